@@ -0,0 +1,115 @@
+import * as path from "path";
+import * as fs from "fs";
+
+/**
+ * IncludeCycleError is thrown when a document ends up including itself,
+ * directly or through other documents.
+ */
+export class IncludeCycleError extends Error {
+    constructor(public chain: Array<string>) {
+        super(`Include cycle detected: ${chain.join(" -> ")}`);
+    }
+}
+
+/**
+ * IncludeResolver loads external documents referenced from the RAML
+ * definition, such as JSON schemas and example bodies. References are
+ * resolved relative to the document which makes them, so nested documents
+ * may in turn reference files next to themselves.
+ */
+export class IncludeResolver {
+
+    private cache : { [file: string]: string } = {};
+
+    /**
+     * Creates a resolver for the RAML definition at the root path.
+     */
+    constructor(private root: string) {}
+
+    /**
+     * Returns the absolute path of a file referenced from the document at
+     * `from`, which defaults to the root RAML file.
+     */
+    resolvePath(ref: string, from: string=this.root): string {
+        return path.resolve(path.dirname(from), ref);
+    }
+
+    /**
+     * Reads a referenced file, returning its contents parsed as JSON if it
+     * is JSON, or as plain text otherwise.
+     */
+    load(ref: string, from: string=this.root): any {
+        return IncludeResolver.parse(this.read(this.resolvePath(ref, from)));
+    }
+
+    /**
+     * Parses document contents which may be JSON or plain text.
+     */
+    static parse(content: string): any {
+        try {
+            return JSON.parse(content);
+        } catch (e) {
+            return content;
+        }
+    }
+
+    /**
+     * Replaces references to other files within a JSON document, such as a
+     * schema's `"$ref": "address.json#/definitions/street"`, with the
+     * referenced document or the part of it the fragment points to. The
+     * referenced documents are resolved in turn, relative to themselves.
+     * References within the same document (`"#/definitions/street"`) are
+     * left as they are. Throws an IncludeCycleError on cycles.
+     */
+    resolveJSON(doc: any, from: string=this.root, visited: Array<string>=[from]): any {
+        if (Array.isArray(doc)) {
+            return doc.map(item => this.resolveJSON(item, from, visited));
+        }
+
+        if (!doc || typeof doc !== "object") {
+            return doc;
+        }
+
+        const ref = doc["$ref"];
+        if (typeof ref === "string" && ref[0] !== "#" && !(/^[a-z]+:\/\//i).test(ref)) {
+            const [file, fragment] = ref.split("#");
+            const target = this.resolvePath(file, from);
+            if (visited.indexOf(target) !== -1) {
+                throw new IncludeCycleError(visited.concat(target));
+            }
+
+            const included = this.resolveJSON(
+                JSON.parse(this.read(target)),
+                target,
+                visited.concat(target)
+            );
+
+            return fragment ? pointer(included, fragment) : included;
+        }
+
+        const out : { [key: string]: any } = {};
+        Object.keys(doc).forEach(key => {
+            out[key] = this.resolveJSON(doc[key], from, visited);
+        });
+
+        return out;
+    }
+
+    private read(file: string): string {
+        if (!(file in this.cache)) {
+            this.cache[file] = fs.readFileSync(file, "utf8");
+        }
+
+        return this.cache[file];
+    }
+}
+
+/**
+ * Evaluates a JSON pointer, like `/definitions/street`, against the doc.
+ */
+function pointer(doc: any, ptr: string): any {
+    return ptr.split("/")
+        .filter(segment => segment !== "")
+        .map(segment => segment.replace(/~1/g, "/").replace(/~0/g, "~"))
+        .reduce((node, segment) => node === undefined ? undefined : node[segment], doc);
+}
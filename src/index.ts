@@ -1,22 +1,25 @@
 import { Target } from "./target";
 import { Todo } from "./todo";
-import { loadApi, api10 } from "raml-1-parser";
+import { IncludeResolver } from "./include";
+import { load } from "./model";
+import { api10 } from "raml-1-parser";
 
 import * as path from "path";
-import * as fs from "fs";
 
 const pkg = require('../package.json');
 const argv = require('yargs')
-    .usage('$0 <raml path> -t [target] -o [output]')
+    .usage('$0 <raml or swagger path> -t [target] -o [output]')
     .version(pkg.version)
     .string('target').alias('t', 'target')
     .boolean('watch').alias('w', 'watch')
     .string('output').alias('o', 'output')
+    .string('package').alias('p', 'package')
     .demand(1, ['target', 'output'])
     .describe({
         target: 'a target language to generate',
         watch: 'whether to watch the RAML files for changes',
-        output: 'target directory to output'
+        output: 'target directory to output',
+        package: 'name of the package to generate, for targets with packages'
     })
     .example('$0 ./docs/index.raml -t go -o ./dist')
     .help('help')
@@ -34,11 +37,12 @@ const todo = new Todo();
 
 todo.start("Installing dependencies");
 target.check()
-.then(() => todo.start("Parsing RAML"))
-.then(() => loadApi(argv._[0]))
-.then((api: any) => {
+.then(() => load(argv._[0], step => todo.start(step)))
+.then((api: api10.Api) => {
     todo.finish();
-    return target.generate(api, argv.output);
+    return target.generate(api, argv.output, new IncludeResolver(path.resolve(argv._[0])), {
+        packageName: argv.package,
+    });
 })
 .then(() => process.exit(0))
 .catch(e => {
@@ -0,0 +1,328 @@
+import { readSwagger, writeSwaggerAsRAML } from "./swagger";
+import { loadApi, api10 } from "raml-1-parser";
+
+import * as fs from "fs";
+
+/**
+ * The resolved model of an API definition, with its traits, resource
+ * types, includes and libraries expanded. It's made of plain objects, so
+ * tools such as documentation generators can use it without the parser.
+ */
+export interface Model {
+    title: string;
+    version: string;
+    baseUri: string;
+    description: string;
+    protocols: Array<string>;
+    mediaTypes: Array<string>;
+    securedBy: Array<string>;
+
+    /**
+     * The types declared by the API and, named with their namespace, the
+     * libraries it uses.
+     */
+    types: Array<Type>;
+    securitySchemes: Array<SecurityScheme>;
+    resources: Array<Resource>;
+
+    /**
+     * Every method of every resource, in the order they're declared.
+     */
+    methods: Array<Method>;
+}
+
+export interface Type {
+    name: string;
+    type: Array<string>;
+    description: string;
+    properties: Array<Parameter>;
+}
+
+export interface SecurityScheme {
+    name: string;
+    type: string;
+    description: string;
+    settings: any;
+}
+
+export interface Resource {
+    /**
+     * The complete URI template of the resource, relative to the baseUri.
+     */
+    path: string;
+    displayName: string;
+    description: string;
+    uriParameters: Array<Parameter>;
+    methods: Array<Method>;
+    resources: Array<Resource>;
+}
+
+export interface Method {
+    method: string;
+    path: string;
+    displayName: string;
+    description: string;
+    protocols: Array<string>;
+
+    /**
+     * The names of the security schemes which apply to the method, from
+     * the method, its resources or the API, in which "null" is the scheme
+     * of unsecured calls.
+     */
+    securedBy: Array<string>;
+    queryParameters: Array<Parameter>;
+    headers: Array<Parameter>;
+    body: Array<Body>;
+    responses: Array<Response>;
+}
+
+export interface Parameter {
+    name: string;
+    type: Array<string>;
+    description: string;
+    required: boolean;
+    default: any;
+}
+
+export interface Body {
+    mediaType: string;
+    type: Array<string>;
+    examples: Array<any>;
+}
+
+export interface Response {
+    code: string;
+    description: string;
+    headers: Array<Parameter>;
+    body: Array<Body>;
+}
+
+/**
+ * Loads the RAML or Swagger 2.0 definition in the file, calling progress
+ * with the name of each step taken, and expands it. Swagger documents are
+ * converted to RAML, which is then parsed in the same way as a RAML
+ * definition.
+ */
+export function load(file: string, progress: (step: string) => void = () => undefined): Promise<api10.Api> {
+    const swagger = readSwagger(file);
+    let loaded: Promise<api10.Api>;
+    if (!swagger) {
+        progress("Parsing RAML");
+        loaded = <Promise<api10.Api>>loadApi(file);
+    } else {
+        progress("Converting Swagger to RAML");
+        const converted = writeSwaggerAsRAML(swagger);
+        progress("Parsing RAML");
+        const remove = () => fs.unlinkSync(converted);
+        loaded = (<Promise<api10.Api>>loadApi(converted)).then(
+            api => { remove(); return api; },
+            err => { remove(); throw err; }
+        );
+    }
+
+    // Apply traits and resource types, so that each resource carries the
+    // methods it inherits and each method the parameters, headers, bodies
+    // and responses it inherits. Parameters such as <<resourcePathName>> and
+    // <<resourcePathName | !singularize>> are substituted, and declarations
+    // made on a resource or method take precedence over inherited ones.
+    return loaded.then(api => {
+        progress("Expanding traits and resource types");
+        return api.expand();
+    });
+}
+
+/**
+ * Loads and expands the definition in the file, as load does, returning
+ * its resolved model.
+ */
+export function parse(file: string): Promise<Model> {
+    return load(file).then(toModel);
+}
+
+/**
+ * Returns the resolved model of the expanded API.
+ */
+export function toModel(api: api10.Api): Model {
+    const methods = new Array<Method>();
+    const toResource = (resource: api10.Resource): Resource => {
+        const out = {
+            path: resource.completeRelativeUri(),
+            displayName: resource.displayName(),
+            description: descriptionOf(resource),
+            uriParameters: resource.uriParameters().map(toParameter),
+            methods: resource.methods().map(method => toMethod(resource, method)),
+            resources: <Array<Resource>>[],
+        };
+        methods.push(...out.methods);
+        out.resources = resource.resources().map(toResource);
+
+        return out;
+    };
+
+    const types = declaredTypes(api).map(({ ns, decl }) => toType(ns, decl));
+
+    return {
+        title: api.title(),
+        version: api.version(),
+        baseUri: api.baseUri() ? api.baseUri().value() : null,
+        description: descriptionOf(api),
+        protocols: api.protocols(),
+        mediaTypes: api.mediaType().map(m => m.value()),
+        securedBy: schemeNames(api.securedBy()),
+        types,
+        securitySchemes: api.securitySchemes().map(scheme => ({
+            name: scheme.name(),
+            type: scheme.type(),
+            description: descriptionOf(scheme),
+            settings: settingsOf(scheme),
+        })),
+        resources: api.resources().map(toResource),
+        methods,
+    };
+}
+
+function toMethod(resource: api10.Resource, method: api10.Method): Method {
+    const api = method.ownerApi();
+
+    return {
+        method: method.method(),
+        path: resource.completeRelativeUri(),
+        displayName: method.displayName(),
+        description: descriptionOf(method),
+        protocols: method.protocols().length ? method.protocols() : api.protocols(),
+        securedBy: schemeNames(securedByOf(resource, method)),
+        queryParameters: method.queryParameters().map(toParameter),
+        headers: method.headers().map(toParameter),
+        body: method.body().map(body => toBody(api, body)),
+        responses: method.responses().map(res => ({
+            code: res.code().value(),
+            description: descriptionOf(res),
+            headers: res.headers().map(toParameter),
+            body: res.body().map(body => toBody(api, body)),
+        })),
+    };
+}
+
+function toType(ns: string, decl: api10.TypeDeclaration): Type {
+    const props = <Function>(<any>decl).properties;
+    return {
+        name: ns + decl.name(),
+        type: typesOf(decl),
+        description: descriptionOf(decl),
+        properties: props ? (<api10.ObjectTypeDeclaration>decl).properties().map(toParameter) : [],
+    };
+}
+
+function toParameter(decl: api10.TypeDeclaration): Parameter {
+    return {
+        name: decl.name(),
+        type: typesOf(decl),
+        description: descriptionOf(decl),
+        required: decl.required(),
+        default: facet(decl, "default"),
+    };
+}
+
+function toBody(api: api10.Api, decl: api10.TypeDeclaration): Body {
+    const examples = decl.examples().length ? decl.examples() : decl.example() ? [decl.example()] : [];
+    const defaults = api.mediaType();
+    return {
+        mediaType: decl.name().indexOf("/") !== -1 ? decl.name()
+            : defaults.length > 0 ? defaults[0].value() : "application/json",
+        type: typesOf(decl),
+        examples: examples.map(example => example.value()),
+    };
+}
+
+/**
+ * Returns the types which the declaration inherits from. RAML 0.8
+ * parameters have a single type, rather than a list.
+ */
+function typesOf(decl: api10.TypeDeclaration): Array<string> {
+    const types = <string | Array<string>>(<any>decl).type();
+    return Array.isArray(types) ? types : [types];
+}
+
+/**
+ * Returns the value of the facet, such as its `pattern` or `minimum`, or
+ * null if the declaration doesn't have it, as RAML 0.8 parameters may not.
+ */
+export function facet(decl: api10.TypeDeclaration, name: string): any {
+    const fn = (<any>decl)[name];
+    const value = typeof fn === "function" ? fn.call(decl) : null;
+    return value === undefined ? null : value;
+}
+
+function settingsOf(scheme: api10.AbstractSecurityScheme): any {
+    const settings = <any>scheme.settings();
+    return (settings && settings.toJSON()) || null;
+}
+
+function schemeNames(refs: Array<api10.SecuritySchemeRef>): Array<string> {
+    return refs.map(ref => ref && ref.securitySchemeName() ? ref.securitySchemeName() : "null");
+}
+
+/**
+ * Returns the value of a node's description, or null if it has none.
+ */
+export function descriptionOf(node: { description(): api10.MarkdownString }): string {
+    const description = node.description();
+    return description && description.value() ? description.value().trim() || null : null;
+}
+
+/**
+ * Returns the `securedBy` which applies to the method: its own, or else
+ * that of the closest of its resources which declares one, or else the
+ * API's.
+ */
+export function securedByOf(resource: api10.Resource, method: api10.Method): Array<api10.SecuritySchemeRef> {
+    let refs = method.securedBy();
+    for (let r = resource; refs.length === 0 && r; r = r.parentResource()) {
+        refs = r.securedBy();
+    }
+
+    return refs.length ? refs : method.ownerApi().securedBy();
+}
+
+/**
+ * Returns whether the method is unsecured, because the `securedBy` which
+ * applies to it lists only the `null` scheme.
+ */
+export function isUnsecured(resource: api10.Resource, method: api10.Method): boolean {
+    const refs = securedByOf(resource, method);
+    return refs.length > 0 && refs.every(ref => !ref || !ref.securitySchemeName()
+        || ref.securitySchemeName() === "null");
+}
+
+/**
+ * A type declared by the API or one of the libraries it uses. The name is
+ * qualified by the library namespace, like `lib.Foo`.
+ */
+export interface DeclaredType {
+    name: string;
+    ns: string;
+    decl: api10.TypeDeclaration;
+}
+
+/**
+ * Returns all types declared by the API and, recursively, by the libraries
+ * it uses.
+ */
+export function declaredTypes(api: api10.Api): Array<DeclaredType> {
+    const out = api.types().map(decl => ({ name: decl.name(), ns: "", decl }));
+
+    (function addLibraries(uses: Array<api10.UsesDeclaration>, prefix: string) {
+        uses.forEach(use => {
+            const lib = use.ast();
+            if (!lib) {
+                return;
+            }
+
+            const ns = `${prefix}${use.key()}.`;
+            lib.types().forEach(decl => out.push({ name: ns + decl.name(), ns, decl }));
+            addLibraries(lib.uses(), ns);
+        });
+    })(api.uses(), "");
+
+    return out;
+}
@@ -0,0 +1,493 @@
+import * as fs from "fs";
+import * as os from "os";
+import * as path from "path";
+
+/**
+ * The HTTP methods a Swagger path item may describe operations for.
+ */
+const operationMethods = ["get", "put", "post", "delete", "options", "head", "patch"];
+
+/**
+ * Reads an API definition which may be a Swagger 2.0 document, in JSON or
+ * YAML, returning the document if it is one or null if it isn't. RAML
+ * definitions are recognized by their extension or `#%RAML` header and
+ * aren't parsed.
+ */
+export function readSwagger(file: string): any {
+    if (path.extname(file).toLowerCase() === ".raml") {
+        return null;
+    }
+
+    const content = fs.readFileSync(file, "utf8");
+    if ((/^\s*#%RAML/).test(content)) {
+        return null;
+    }
+
+    let doc: any;
+    try {
+        doc = JSON.parse(content);
+    } catch (e) {
+        // YAML is a superset of JSON, but JSON.parse is much faster, so
+        // js-yaml is only loaded for documents which aren't JSON.
+        doc = require("js-yaml").safeLoad(content);
+    }
+
+    return doc && typeof doc === "object" && String(doc.swagger) === "2.0" ? doc : null;
+}
+
+/**
+ * Converts a Swagger 2.0 document to an equivalent RAML 1.0 definition,
+ * writing it to a temporary file and returning its path, so that it may be
+ * loaded by the RAML parser and generated from like any other. The caller
+ * should remove the file once it's loaded.
+ */
+export function writeSwaggerAsRAML(doc: any): string {
+    const file = path.join(os.tmpdir(), `raml-client-generator-${process.pid}-${Date.now()}.raml`);
+    fs.writeFileSync(file, swaggerToRAML(doc));
+    return file;
+}
+
+/**
+ * Converts a Swagger 2.0 document to RAML 1.0. Paths become resources and
+ * operations their methods; `definitions` become declared types and
+ * `securityDefinitions` security schemes. The RAML is written as JSON,
+ * which is valid YAML, after the `#%RAML 1.0` header.
+ */
+export function swaggerToRAML(doc: any): string {
+    return "#%RAML 1.0\n" + JSON.stringify(new SwaggerConverter(doc).convert(), null, 2) + "\n";
+}
+
+/**
+ * Returns an identifier-like name, in upper camel case, for the words in
+ * the string.
+ */
+function pascalCase(str: string): string {
+    return str.split(/[^a-z0-9]+/i)
+        .filter(word => !!word)
+        .map(word => word[0].toUpperCase() + word.slice(1))
+        .join("");
+}
+
+/**
+ * Returns the name of a definition referenced by a `#/definitions/Name`
+ * pointer, or null if the reference points elsewhere.
+ */
+function definitionName(ref: string): string {
+    const match = (/^#\/definitions\/(.+)$/).exec(ref);
+    return match ? match[1].replace(/~1/g, "/").replace(/~0/g, "~") : null;
+}
+
+class SwaggerConverter {
+
+    private types : { [name: string]: any } = {};
+
+    constructor(private doc: any) {}
+
+    convert(): any {
+        const doc = this.doc;
+        const info = doc.info || {};
+        const schemes: Array<string> = doc.schemes && doc.schemes.length ? doc.schemes : ["https"];
+        const raml : { [key: string]: any } = { title: info.title || "API" };
+
+        if (info.version) {
+            raml["version"] = String(info.version);
+        }
+        if (info.description) {
+            raml["description"] = info.description;
+        }
+
+        // Without a host, the API is served from wherever its documentation
+        // is, so the host is left for clients to fill in.
+        const scheme = schemes.indexOf("https") !== -1 ? "https" : schemes[0];
+        raml["baseUri"] = `${scheme}://${doc.host || "{host}"}${(doc.basePath || "").replace(/\/$/, "")}`;
+        if (!doc.host) {
+            raml["baseUriParameters"] = { host: { type: "string", description: "The host serving the API." } };
+        }
+        raml["protocols"] = schemes.map(s => s.toUpperCase());
+
+        Object.keys(doc.definitions || {}).forEach(name => {
+            this.types[name] = this.schemaType(doc.definitions[name], name);
+        });
+
+        const securitySchemes = this.securitySchemes();
+        if (Object.keys(securitySchemes).length) {
+            raml["securitySchemes"] = securitySchemes;
+        }
+        if (doc.security) {
+            raml["securedBy"] = this.securedBy(doc.security);
+        }
+
+        const resources : { [key: string]: any } = {};
+        Object.keys(doc.paths || {}).forEach(uri => {
+            resources[uri.startsWith("/") ? uri : "/" + uri] = this.resource(uri, doc.paths[uri]);
+        });
+
+        // Declared types include those hoisted out of bodies while
+        // converting resources, so they're only added now.
+        if (Object.keys(this.types).length) {
+            raml["types"] = this.types;
+        }
+
+        Object.keys(resources).forEach(uri => raml[uri] = resources[uri]);
+        return raml;
+    }
+
+    /**
+     * Follows a `$ref` to a shared parameter or response, returning the
+     * object it points to, or the object itself if it isn't a reference.
+     */
+    private deref(obj: any): any {
+        if (!obj || typeof obj["$ref"] !== "string") {
+            return obj;
+        }
+
+        return obj["$ref"].split("/")
+            .filter((segment: string) => segment !== "#")
+            .map((segment: string) => segment.replace(/~1/g, "/").replace(/~0/g, "~"))
+            .reduce((node: any, segment: string) => node === undefined ? undefined : node[segment], this.doc);
+    }
+
+    private securitySchemes(): { [name: string]: any } {
+        const out : { [name: string]: any } = {};
+        const defs = this.doc.securityDefinitions || {};
+        Object.keys(defs).forEach(name => {
+            const def = defs[name];
+            switch (def.type) {
+            case "basic":
+                out[name] = { type: "Basic Authentication" };
+            break;
+            case "apiKey":
+                out[name] = {
+                    type: "Pass Through",
+                    describedBy: {
+                        [def.in === "query" ? "queryParameters" : "headers"]: {
+                            [def.name]: { type: "string" },
+                        },
+                    },
+                };
+            break;
+            case "oauth2":
+                const grants : { [flow: string]: string } = {
+                    implicit: "implicit",
+                    password: "password",
+                    application: "client_credentials",
+                    accessCode: "authorization_code",
+                };
+                const settings : { [key: string]: any } = {
+                    authorizationGrants: [grants[def.flow] || def.flow],
+                    scopes: Object.keys(def.scopes || {}),
+                };
+                if (def.authorizationUrl) {
+                    settings["authorizationUri"] = def.authorizationUrl;
+                }
+                if (def.tokenUrl) {
+                    settings["accessTokenUri"] = def.tokenUrl;
+                }
+                out[name] = { type: "OAuth 2.0", settings };
+            break;
+            default:
+                console.error(`Unknown Swagger security scheme type "${def.type}" of "${name}"`);
+                return;
+            }
+
+            if (def.description) {
+                out[name].description = def.description;
+            }
+        });
+
+        return out;
+    }
+
+    /**
+     * Converts a list of security requirements. An empty list, which
+     * lifts the API's requirements from an operation, becomes `[null]`.
+     */
+    private securedBy(requirements: Array<any>): Array<string> {
+        if (!requirements.length) {
+            return [null];
+        }
+
+        const names : Array<string> = [];
+        requirements.forEach(req => Object.keys(req).forEach(name => {
+            if (names.indexOf(name) === -1) {
+                names.push(name);
+            }
+        }));
+
+        return names;
+    }
+
+    private resource(uri: string, item: any): any {
+        const resource : { [key: string]: any } = {};
+        const shared : Array<any> = (item.parameters || []).map((p: any) => this.deref(p));
+        const uriParameters : { [name: string]: any } = {};
+
+        operationMethods.filter(verb => item[verb]).forEach(verb => {
+            const op = item[verb];
+
+            // Operation parameters override path item parameters with the
+            // same name and location.
+            const own : Array<any> = (op.parameters || []).map((p: any) => this.deref(p));
+            const params = shared
+                .filter(p => !own.some(o => o.name === p.name && o.in === p.in))
+                .concat(own);
+
+            params.filter(p => p.in === "path").forEach(p => {
+                uriParameters[p.name] = this.parameter(p);
+            });
+
+            resource[verb] = this.method(uri, verb, op, params);
+        });
+
+        shared.filter(p => p.in === "path").forEach(p => {
+            if (!(p.name in uriParameters)) {
+                uriParameters[p.name] = this.parameter(p);
+            }
+        });
+
+        if (Object.keys(uriParameters).length) {
+            resource["uriParameters"] = uriParameters;
+        }
+
+        return resource;
+    }
+
+    private method(uri: string, verb: string, op: any, params: Array<any>): any {
+        const method : { [key: string]: any } = {};
+        const name = pascalCase(op.operationId || `${verb} ${uri.replace(/\{[^}]*\}/g, "")}`);
+
+        if (op.operationId) {
+            method["displayName"] = op.operationId;
+        }
+        if (op.summary || op.description) {
+            method["description"] = [op.summary, op.description].filter(part => !!part).join("\n\n");
+        }
+        if (op.schemes && op.schemes.length) {
+            method["protocols"] = op.schemes.map((s: string) => s.toUpperCase());
+        }
+        if (op.security) {
+            method["securedBy"] = this.securedBy(op.security);
+        }
+
+        [["query", "queryParameters"], ["header", "headers"]].forEach(([location, key]) => {
+            const matching = params.filter(p => p.in === location);
+            if (matching.length) {
+                method[key] = {};
+                matching.forEach(p => method[key][p.name] = this.parameter(p));
+            }
+        });
+
+        const consumes: Array<string> = op.consumes || this.doc.consumes || ["application/json"];
+        const payload = params.find(p => p.in === "body");
+        const form = params.filter(p => p.in === "formData");
+        if (payload) {
+            const type = this.bodyType(payload.schema || {}, `${name}Payload`);
+            method["body"] = {};
+            consumes.forEach(mediaType => method["body"][mediaType] = typeof type === "string" ? { type } : type);
+        } else if (form.length) {
+            const multipart = form.some(p => p.type === "file") || consumes.indexOf("multipart/form-data") !== -1;
+            const properties : { [name: string]: any } = {};
+            form.forEach(p => properties[p.name] = this.parameter(p));
+            method["body"] = {
+                [multipart ? "multipart/form-data" : "application/x-www-form-urlencoded"]: {
+                    type: "object",
+                    properties,
+                },
+            };
+        }
+
+        const produces: Array<string> = op.produces || this.doc.produces || ["application/json"];
+        const responses : { [code: string]: any } = {};
+        Object.keys(op.responses || {}).filter(code => (/^\d{3}$/).test(code)).forEach(code => {
+            responses[code] = this.response(this.deref(op.responses[code]), produces, `${name}Result`);
+        });
+        if (Object.keys(responses).length) {
+            method["responses"] = responses;
+        }
+
+        return method;
+    }
+
+    private response(res: any, produces: Array<string>, name: string): any {
+        const out : { [key: string]: any } = {};
+        if (res.description) {
+            out["description"] = res.description;
+        }
+
+        const headers = res.headers || {};
+        if (Object.keys(headers).length) {
+            out["headers"] = {};
+            Object.keys(headers).forEach(header => {
+                out["headers"][header] = Object.assign(this.parameter(headers[header]), { required: false });
+            });
+        }
+
+        if (res.schema) {
+            const type = this.bodyType(res.schema, name);
+            const examples = res.examples || {};
+            out["body"] = {};
+            produces.forEach(mediaType => {
+                out["body"][mediaType] = typeof type === "string" ? { type } : Object.assign({}, type);
+                if (mediaType in examples) {
+                    out["body"][mediaType].example = examples[mediaType];
+                }
+            });
+        }
+
+        return out;
+    }
+
+    /**
+     * Returns the type of a body with the schema. Inline object schemas
+     * are hoisted into declared types with the name, so they generate
+     * named structs rather than maps.
+     */
+    private bodyType(schema: any, name: string): any {
+        const type = this.schemaType(schema, name);
+        if (typeof type === "object" && type.type === "object" && type.properties) {
+            this.types[name] = type;
+            return name;
+        }
+
+        return type;
+    }
+
+    /**
+     * Converts a non-body parameter, or a response header, which is
+     * described by the same keywords as a schema along with whether it's
+     * required.
+     */
+    private parameter(param: any): any {
+        const type = this.schemaType(param, null);
+        const out = typeof type === "string" ? { type } : type;
+        out.required = !!param.required;
+        if (param.description) {
+            out.description = param.description;
+        }
+
+        return out;
+    }
+
+    /**
+     * Converts a JSON schema, in the Swagger dialect, to a RAML type.
+     * References to definitions become the names of the declared types.
+     * Inline objects in arrays are hoisted into declared types named after
+     * the owner, if it has a name.
+     */
+    private schemaType(schema: any, owner: string): any {
+        if (typeof schema["$ref"] === "string") {
+            const ref = definitionName(schema["$ref"]);
+            if (ref === null) {
+                console.error(`Unsupported Swagger reference "${schema["$ref"]}"`);
+                return "any";
+            }
+
+            return ref;
+        }
+
+        const out : { [key: string]: any } = {};
+
+        if (schema.allOf) {
+            const parents : Array<string> = [];
+            const properties : { [name: string]: any } = {};
+            schema.allOf.forEach((part: any) => {
+                if (typeof part["$ref"] === "string") {
+                    parents.push(this.schemaType(part, owner));
+                    return;
+                }
+
+                const converted = this.schemaType(part, owner);
+                Object.assign(properties, converted.properties || {});
+            });
+
+            out["type"] = parents.length ? (parents.length === 1 ? parents[0] : parents) : "object";
+            if (Object.keys(properties).length) {
+                out["properties"] = properties;
+            }
+
+            return out;
+        }
+
+        switch (schema.type) {
+        case "object":
+        case undefined:
+            if (!schema.properties && schema.type === undefined) {
+                return "any";
+            }
+
+            out["type"] = "object";
+            const required: Array<string> = schema.required || [];
+            const properties : { [name: string]: any } = {};
+            Object.keys(schema.properties || {}).forEach(prop => {
+                let type = this.schemaType(schema.properties[prop], owner && owner + pascalCase(prop));
+                if (typeof type === "string") {
+                    type = { type };
+                }
+                type.required = required.indexOf(prop) !== -1;
+                properties[prop] = type;
+            });
+            out["properties"] = properties;
+        break;
+        case "array":
+            out["type"] = "array";
+            let items = this.schemaType(schema.items || {}, owner && owner + "Item");
+            if (owner && typeof items === "object" && items.type === "object" && items.properties) {
+                this.types[owner + "Item"] = items;
+                items = owner + "Item";
+            }
+            out["items"] = items;
+            ["minItems", "maxItems", "uniqueItems"].forEach(key => {
+                if (key in schema) {
+                    out[key] = schema[key];
+                }
+            });
+        break;
+        case "string":
+            switch (schema.format) {
+            case "date-time": out["type"] = "datetime"; break;
+            case "date":      out["type"] = "date-only"; break;
+            default:          out["type"] = "string";
+            }
+            ["pattern", "minLength", "maxLength"].forEach(key => {
+                if (key in schema) {
+                    out[key] = schema[key];
+                }
+            });
+        break;
+        case "integer":
+        case "number":
+            out["type"] = schema.type;
+            if (schema.format === "int32" || schema.format === "int64") {
+                out["format"] = schema.format;
+            }
+            ["minimum", "maximum", "multipleOf"].forEach(key => {
+                if (key in schema) {
+                    out[key] = schema[key];
+                }
+            });
+        break;
+        case "boolean":
+        case "file":
+            out["type"] = schema.type;
+        break;
+        default:
+            console.error(`Unknown Swagger schema type "${schema.type}"`);
+            return "any";
+        }
+
+        if (schema.enum) {
+            out["enum"] = schema.enum;
+        }
+        if ("default" in schema) {
+            out["default"] = schema.default;
+        }
+        if (schema.description) {
+            out["description"] = schema.description;
+        }
+        if ("example" in schema) {
+            out["example"] = schema.example;
+        }
+
+        return out;
+    }
+}
@@ -1,4 +1,5 @@
 import { api10, api08 } from "raml-1-parser";
+import { IncludeResolver } from "./include";
 
 
 export interface GenerateArgs {
@@ -6,6 +7,17 @@ export interface GenerateArgs {
     Output: string
 }
 
+/**
+ * Options for generation given on the command line.
+ */
+export interface GenerateOptions {
+    /**
+     * The name of the package to generate code in, for targets which have
+     * packages. Each target has its own default.
+     */
+    packageName?: string
+}
+
 export interface Target {
 
     /**
@@ -16,7 +28,8 @@ export interface Target {
 
     /**
      * Runs generation for the provided API and outputs associated
-     * files into the target "output" directory.
+     * files into the target "output" directory. The resolver loads
+     * external documents, such as JSON schemas, which the API references.
      */
-    generate(api: api10.Api, output: string): Promise<void>
+    generate(api: api10.Api, output: string, includes: IncludeResolver, options: GenerateOptions): Promise<void>
 }
\ No newline at end of file
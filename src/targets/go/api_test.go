@@ -0,0 +1,14 @@
+package client
+
+// The generator writes these into api.go, from the API's definition. The
+// tests of the runtime files stand in for them.
+
+const (
+	BaseURL            = "https://api.example.com/v1"
+	DefaultUserAgent   = "Example/v1 (raml-client-generator)"
+	defaultOAuthScheme = "OAuth"
+)
+
+// allowedProtocols is empty, as for APIs which don't restrict their
+// protocols, so that tests may use plain HTTP servers.
+var allowedProtocols []string
@@ -1,70 +1,1387 @@
 package client
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"net/http/cookiejar"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
+// DefaultTimeout is the timeout of the http.Client created by NewClient.
+const DefaultTimeout = 30 * time.Second
+
+// Client makes calls against the API. Clients should be created with
+// NewClient. The zero value is usable too, sending requests with
+// http.DefaultClient, but has no base URL until SetBaseURL is called.
 type Client struct {
-	HTTP    *http.Client
+	// HTTP is the client used to send requests. It may be replaced by
+	// advanced users, but shouldn't be modified once requests are made.
+	HTTP        *http.Client
+	baseURL     string
+	bufferLimit int64
+	arrayStyle  ArrayStyle
+	useNumber   bool
+	codec       Codec
+
+	validateResponses bool
+
+	mu      sync.RWMutex
 	filters []Filter
+	jar     http.CookieJar
+}
+
+// An Option configures a Client created by NewClient.
+type Option func(*Client)
+
+// DefaultOptions are applied by NewClient to every Client it creates,
+// before the options it's passed, such as to install the same filters or
+// transport on each. Filters added later, by options or AddFilter, come
+// after those it installs. It should be set while the program starts, as
+// it's read without synchronization. Filters it installs are shared by the
+// Clients, so stateful ones, like a RateLimitFilter, apply to all of them
+// together.
+var DefaultOptions []Option
+
+// NewClient creates a Client which sends requests to the given base URL,
+// or to BaseURL if it's empty. Unless overridden by an option, the client
+// uses a new http.Client with DefaultTimeout. DefaultOptions are applied
+// first.
+func NewClient(baseURL string, opts ...Option) *Client {
+	if baseURL == "" {
+		baseURL = BaseURL
+	}
+
+	c := &Client{
+		HTTP:    &http.Client{Timeout: DefaultTimeout},
+		baseURL: baseURL,
+		filters: []Filter{},
+	}
+	for _, opt := range DefaultOptions {
+		opt(c)
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithHTTPClient makes the Client send requests using h.
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) { c.HTTP = h }
+}
+
+// WithTransport makes the Client send requests with rt, in place of its
+// http.Client's transport. An http.Client passed to WithHTTPClient isn't
+// modified; the Client uses a copy of it.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		h := *c.HTTP
+		h.Transport = rt
+		c.HTTP = &h
+	}
+}
+
+// WithTLSConfig makes the Client use the TLS configuration, such as to
+// present a client certificate or trust a private CA. Like WithProxy, it
+// configures a copy of the http.Client's transport, which must be an
+// *http.Transport; others, as set by WithTransport, are left untouched.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) {
+		c.editTransport(func(t *http.Transport) { t.TLSClientConfig = config })
+	}
+}
+
+// WithProxy makes the Client send requests through the proxy at u, rather
+// than any set by the environment.
+func WithProxy(u *url.URL) Option {
+	return func(c *Client) {
+		c.editTransport(func(t *http.Transport) { t.Proxy = http.ProxyURL(u) })
+	}
+}
+
+// WithoutRedirects makes the Client return redirect responses to the
+// caller instead of following them, such as to capture the `Location` a
+// 302 points to.
+func WithoutRedirects() Option {
+	return WithCheckRedirect(func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	})
+}
+
+// WithMaxRedirects makes the Client follow at most n redirects in a row,
+// failing the call if the server sends more. By default, like net/http,
+// up to 10 are followed.
+func WithMaxRedirects(n int) Option {
+	return WithCheckRedirect(func(req *http.Request, via []*http.Request) error {
+		if len(via) > n {
+			return fmt.Errorf("client: stopped after %d redirects", n)
+		}
+		return nil
+	})
+}
+
+// WithCheckRedirect makes the Client decide whether to follow redirects
+// with check, which is called as http.Client.CheckRedirect is. It may
+// return http.ErrUseLastResponse to return the redirect response itself.
+//
+// Filters aren't run again on redirected requests. They carry the headers
+// the filters set on the first one, except that net/http drops the
+// `Authorization` and `Cookie` headers when redirecting to another domain.
+// Cookies enabled by EnableCookies are the exception: those set by the
+// redirect response are stored, and the jar's cookies for the redirected
+// URL replace any carried over.
+func WithCheckRedirect(check func(req *http.Request, via []*http.Request) error) Option {
+	return func(c *Client) {
+		h := *c.HTTP
+		h.CheckRedirect = check
+		c.HTTP = &h
+	}
+}
+
+// editTransport edits a copy of the http.Client's transport, which is
+// http.DefaultTransport if unset, and sets the copy on a copy of the
+// http.Client.
+func (c *Client) editTransport(edit func(*http.Transport)) {
+	var t *http.Transport
+	switch rt := c.HTTP.Transport.(type) {
+	case nil:
+		t = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		t = rt.Clone()
+	default:
+		return
+	}
+	edit(t)
+
+	h := *c.HTTP
+	h.Transport = t
+	c.HTTP = &h
+}
+
+// WithUseNumber makes the Client decode JSON numbers into json.Number,
+// rather than float64, wherever a response holds dynamically typed values,
+// such as interface{} fields or map[string]interface{} bodies. Integers
+// beyond 2^53 then keep their precision. Fields the API types are decoded
+// into their Go types regardless.
+func WithUseNumber() Option {
+	return func(c *Client) { c.useNumber = true }
+}
+
+type useNumberKey struct{}
+
+// WithFilters adds the filters to the Client, in order, as if by AddFilter.
+func WithFilters(filters ...Filter) Option {
+	return func(c *Client) {
+		for _, f := range filters {
+			c.AddFilter(f)
+		}
+	}
+}
+
+// ArrayStyle is how array query parameters are encoded.
+type ArrayStyle int
+
+const (
+	// ArrayRepeat repeats the parameter for each value, as in
+	// `?tag=a&tag=b`. It's the default.
+	ArrayRepeat ArrayStyle = iota
+	// ArrayComma sends the values joined by commas, as in `?tag=a,b`.
+	ArrayComma
+)
+
+// WithArrayStyle makes the Client encode array query parameters following
+// the style.
+func WithArrayStyle(style ArrayStyle) Option {
+	return func(c *Client) { c.arrayStyle = style }
+}
+
+// addArrayParam adds the values of an array query parameter to v following
+// the client's ArrayStyle. Nothing is added for empty arrays.
+func (c *Client) addArrayParam(v url.Values, name string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+
+	if c.arrayStyle == ArrayComma {
+		v.Add(name, strings.Join(values, ","))
+		return
+	}
+
+	for _, value := range values {
+		v.Add(name, value)
+	}
 }
 
 // Adds a filter which hooks into part of the HTTP lifecycle.
+// It's safe to call concurrently with requests being made, though requests
+// already in flight won't see the new filter.
 func (c *Client) AddFilter(f Filter) {
+	c.mu.Lock()
 	c.filters = append(c.filters, f)
+	c.mu.Unlock()
+}
+
+// AttachFilter adds a filter like AddFilter, returning a function which
+// detaches it again by calling RemoveFilter.
+func (c *Client) AttachFilter(f Filter) (detach func()) {
+	c.AddFilter(f)
+	return func() { c.RemoveFilter(f) }
+}
+
+// RemoveFilter removes the first filter identical to f, usually meaning the
+// same pointer, preserving the order of the others. It returns whether a
+// filter was removed. Like AddFilter, it's safe for concurrent use.
+func (c *Client) RemoveFilter(f Filter) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, existing := range c.filters {
+		if existing == f {
+			filters := make([]Filter, 0, len(c.filters)-1)
+			c.filters = append(append(filters, c.filters[:i]...), c.filters[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// snapshotFilters returns a copy of the filters list, so that it can be
+// iterated without holding the lock.
+func (c *Client) snapshotFilters() []Filter {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]Filter(nil), c.filters...)
 }
 
 // EnableCookies sets the client up to send and track cookies from the server.
 // It's required for password auth to work.
 func (c *Client) EnableCookies() {
-	jar, _ := cookiejar.New(nil)
+	c.EnableCookiesWithOptions(nil)
+}
+
+// EnableCookiesWithOptions is like EnableCookies, creating the jar with the
+// given options. Callers will usually want to set the PublicSuffixList, for
+// instance to golang.org/x/net/publicsuffix.List, to prevent cookies being
+// shared across unrelated domains.
+func (c *Client) EnableCookiesWithOptions(opts *cookiejar.Options) {
+	jar, _ := cookiejar.New(opts)
+	c.mu.Lock()
+	c.jar = jar
+	c.mu.Unlock()
 	c.AddFilter(&cookieJar{jar: jar})
 }
 
+// loginWithPassword posts the credential fields to the login URL, as JSON
+// or a form, and expects the server to respond by setting a session cookie.
+// Cookies are enabled on the client first if they aren't already. An error
+// is returned if the server doesn't respond with a 2xx status.
+func (c *Client) loginWithPassword(ctx context.Context, loginURL string, asForm bool, fields map[string]string) error {
+	if c.CookieJar() == nil {
+		c.EnableCookies()
+	}
+
+	var (
+		body        []byte
+		contentType string
+	)
+	if asForm {
+		values := url.Values{}
+		for k, v := range fields {
+			values.Set(k, v)
+		}
+		body, contentType = []byte(values.Encode()), "application/x-www-form-urlencoded"
+	} else {
+		var err error
+		if body, err = c.jsonCodec().Marshal(fields); err != nil {
+			return err
+		}
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequest("POST", loginURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("client: login failed: %s", res.Status)
+	}
+
+	return nil
+}
+
+// CookieJar returns the jar set up by EnableCookies, or nil if cookies
+// aren't enabled. It may be used to inspect or pre-seed cookies.
+func (c *Client) CookieJar() http.CookieJar {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.jar
+}
+
 // UseOAuth adds a filter which includes an OAuth `Authorization` header
-// in requests.
+// in requests. The header uses the legacy `OAuth <token>` form, unless the
+// API declares an OAuth 2.0 security scheme, in which case the token is
+// sent as a Bearer token.
 func (c *Client) UseOAuth(token string) {
-	c.AddFilter(&oauthFilter{token: token})
+	c.AddFilter(&oauthFilter{scheme: defaultOAuthScheme, token: token})
+}
+
+// UseOAuthWithRefresh is like UseOAuth, starting with the initial token
+// which expires at the given time. The token is replaced by calling refresh
+// shortly before it expires, or after the server rejects it with a 401
+// response, in which case the rejected request is retried once. A zero
+// expiry means the token is only refreshed after being rejected.
+func (c *Client) UseOAuthWithRefresh(initial string, expiry time.Time, refresh TokenRefresher) {
+	c.AddFilter(&refreshingOAuthFilter{
+		scheme:  defaultOAuthScheme,
+		token:   initial,
+		expiry:  expiry,
+		refresh: refresh,
+	})
+}
+
+// UseBearerToken adds a filter which includes an OAuth 2.0
+// `Authorization: Bearer <token>` header in requests.
+func (c *Client) UseBearerToken(token string) {
+	c.AddFilter(&oauthFilter{scheme: "Bearer", token: token})
+}
+
+// BufferResponses enables buffering of response bodies up to limit bytes
+// in size, so that every After filter, and then the caller, can read the
+// full body. Bodies known or found to be larger than the limit are passed
+// through unbuffered. A limit of zero disables buffering, which is the
+// default.
+func (c *Client) BufferResponses(limit int64) {
+	c.bufferLimit = limit
+}
+
+// UseDigestAuth adds a filter which authenticates requests with HTTP
+// Digest authentication, as described by DigestFilter.
+func (c *Client) UseDigestAuth(username, password string) {
+	c.AddFilter(NewDigestFilter(username, password))
+}
+
+// UseMetrics adds a filter which reports each request to the sink, as
+// described by MetricsFilter.
+func (c *Client) UseMetrics(sink MetricsSink) {
+	c.AddFilter(NewMetricsFilter(sink))
+}
+
+// UseOAuth1 adds a filter which signs requests with OAuth 1.0a, as
+// described by OAuth1Filter.
+func (c *Client) UseOAuth1(consumerKey, consumerSecret, token, tokenSecret string) {
+	c.AddFilter(NewOAuth1Filter(consumerKey, consumerSecret, token, tokenSecret))
+}
+
+// UseBasicAuth adds a filter which sends the credentials in a preemptive
+// HTTP Basic `Authorization` header with every request. The password may
+// contain colons; the credentials are encoded as UTF-8.
+func (c *Client) UseBasicAuth(username, password string) {
+	c.AddFilter(&basicAuthFilter{username: username, password: password})
+}
+
+// UseAPIKey adds a filter which sends the API key value with each request,
+// either in a header or query string parameter with the given name.
+func (c *Client) UseAPIKey(name, value string, in APIKeyLocation) {
+	c.AddFilter(NewAPIKeyFilter(name, value, in))
+}
+
+// SetUserAgent adds a filter which sends the `User-Agent` header on requests
+// which don't already have one set. The generated DefaultUserAgent may be
+// used to identify the API client.
+func (c *Client) SetUserAgent(ua string) {
+	c.AddFilter(&userAgentFilter{ua: ua})
+}
+
+// UseRateLimit adds a filter which limits the client to rps requests per
+// second, allowing bursts of up to burst requests. See RateLimitFilter.
+// It panics unless rps is greater than zero.
+func (c *Client) UseRateLimit(rps float64, burst int) {
+	c.AddFilter(NewRateLimitFilter(rps, burst))
+}
+
+// UseLogger adds a filter which calls fn once for every request sent, as
+// described by LoggingFilter.
+func (c *Client) UseLogger(fn LogFunc) {
+	c.AddFilter(NewLoggingFilter(fn))
+}
+
+// EnableCompression asks the server for gzip or deflate compressed
+// responses, and transparently decompresses them. See DecompressFilter.
+func (c *Client) EnableCompression() {
+	c.AddFilter(new(DecompressFilter))
+}
+
+// UseCache adds a filter which caches GET responses carrying an `ETag`,
+// holding up to maxBytes of bodies, as described by CacheFilter.
+func (c *Client) UseCache(maxBytes int64) {
+	c.AddFilter(NewCacheFilter(maxBytes))
+}
+
+// UseRetry adds a filter which retries failed idempotent requests, as
+// described by RetryFilter.
+func (c *Client) UseRetry(opts RetryOptions) {
+	c.AddFilter(NewRetryFilter(opts))
+}
+
+// UseIdempotencyKeys adds a filter which gives calls of non-idempotent
+// methods an `Idempotency-Key` header, as described by IdempotencyFilter.
+func (c *Client) UseIdempotencyKeys() {
+	c.AddFilter(IdempotencyFilter{})
+}
+
+// SetBaseURL replaces the URL which request paths are resolved against,
+// such as to send requests to a staging server or a local mock. It takes
+// effect for calls made after it returns. The URL must be absolute, and use
+// a protocol the API allows.
+func (c *Client) SetBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return err
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("client: base URL %q is not absolute", baseURL)
+	}
+	if err := checkProtocol(context.Background(), u); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.baseURL = baseURL
+	c.mu.Unlock()
+	return nil
+}
+
+// BaseURL returns the URL which request paths are resolved against.
+func (c *Client) BaseURL() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.baseURL
 }
 
-func (c *Client) do(req *http.Request) (*http.Response, error) {
-	for _, f := range c.filters {
-		f.Before(req)
+// do sends the request bound to the context, running it through the
+// client's filters. If the request fails in transit the filters are
+// notified through AfterError, and a TransportError is returned. When that
+// failure is caused by the context being cancelled or timing out, ctx.Err()
+// is returned instead.
+//
+// Each attempt is made with a fresh copy of the request, so filters may
+// modify it freely in Before. If a RetryFilter or DigestFilter is
+// installed, the request body is buffered so that it can be replayed,
+// unless the call is made WithStreaming.
+func (c *Client) do(ctx context.Context, req *http.Request, opts ...CallOption) (*http.Response, error) {
+	call := newCallOptions(opts)
+	call.apply(req)
+	if call.stream {
+		ctx = context.WithValue(ctx, streamKey{}, true)
+	}
+	if c.useNumber {
+		ctx = context.WithValue(ctx, useNumberKey{}, true)
+	}
+	if c.codec != nil {
+		ctx = context.WithValue(ctx, codecKey{}, c.codec)
+	}
+	if call.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, call.timeout)
+		res, err := c.doCall(ctx, req)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		// The context must live until the caller is done reading the body.
+		res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+		return res, nil
 	}
 
-	res, err := c.HTTP.Do(request)
+	return c.doCall(ctx, req)
+}
+
+// doCall implements do once the call options have been applied.
+func (c *Client) doCall(ctx context.Context, req *http.Request) (*http.Response, error) {
+	req = req.WithContext(ctx)
+	if !req.URL.IsAbs() {
+		baseURL := c.BaseURL()
+		if baseURL == "" {
+			return nil, errors.New("client: no base URL to send the request to")
+		}
+
+		u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + req.URL.String())
+		if err != nil {
+			return nil, err
+		}
+		req.URL = u
+		req.Host = u.Host
+	}
+	if err := checkProtocol(ctx, req.URL); err != nil {
+		return nil, err
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+
+	// Requests without a body mustn't describe one, such as by a
+	// Content-Type set with WithHeader, and GET and HEAD requests, whose
+	// bodies servers and proxies may ignore or reject, mustn't have one.
+	if req.Body == nil || req.Body == http.NoBody {
+		req.Header.Del("Content-Type")
+	} else if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return nil, fmt.Errorf("client: %s requests can't have a body", req.Method)
+	}
+
+	filters := c.snapshotFilters()
+	var retriers []Retrier
+	buffer := false
+	for _, f := range filters {
+		if r, ok := f.(Retrier); ok {
+			retriers = append(retriers, r)
+		}
+		if _, ok := f.(bodyBufferer); ok {
+			buffer = true
+		}
+		if p, ok := f.(callPreparer); ok {
+			p.prepareCall(req)
+		}
+	}
+
+	// Bodies which can't be replayed are only read ahead for the retriers
+	// which resend them, and never for streaming calls. Otherwise they're
+	// sent once, without retries.
+	if buffer && !IsStreaming(req) {
+		if err := bufferBody(req); err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		ex := &exchange{}
+		sent := req.Clone(context.WithValue(ctx, exchangeKey{}, ex))
+		ex.req = sent
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			sent.Body = body
+		}
+
+		res, err := c.send(sent, filters)
+		if err != nil && ex.start.IsZero() {
+			// The request was vetoed by a filter and never sent.
+			return nil, err
+		}
+
+		wait, retry := shouldRetry(retriers, sent, res, err, attempt)
+		if !retry || (req.Body != nil && req.GetBody == nil) {
+			return res, err
+		}
+
+		if res != nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// send runs a single attempt of the request through the filters.
+func (c *Client) send(req *http.Request, filters []Filter) (*http.Response, error) {
+	for _, f := range filters {
+		if err := f.Before(req); err != nil {
+			return nil, err
+		}
+	}
+
+	ex := exchangeFrom(req.Context())
+	ex.start = time.Now()
+	res, err := c.redirectingClient(filters).Do(req)
+	ex.elapsed = time.Since(ex.start)
 	if err != nil {
-		return res, err
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			err = ctxErr
+		} else {
+			err = &TransportError{Method: req.Method, URL: req.URL.String(), Err: err}
+		}
+
+		for i := len(filters) - 1; i >= 0; i-- {
+			filters[i].AfterError(req, err)
+		}
+
+		return nil, err
 	}
 
-	for _, f := range c.filters {
-		f.After(res)
+	buf := c.bufferResponse(res)
+	for i := len(filters) - 1; i >= 0; i-- {
+		buf.rewind()
+		filters[i].After(res)
 	}
+	buf.rewind()
 
 	return res, nil
 }
 
+// bufferResponse reads the response body into memory if buffering is
+// enabled and the body fits within the limit. It returns nil if the body
+// wasn't buffered.
+func (c *Client) bufferResponse(res *http.Response) *responseBuffer {
+	if c.bufferLimit <= 0 || res.Body == nil || res.ContentLength > c.bufferLimit || IsStreaming(res.Request) {
+		return nil
+	}
+
+	body := res.Body
+	data, err := io.ReadAll(io.LimitReader(body, c.bufferLimit+1))
+	if err != nil || int64(len(data)) > c.bufferLimit {
+		res.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), body), body}
+		return nil
+	}
+	body.Close()
+
+	return &responseBuffer{res: res, data: data}
+}
+
+// responseBuffer holds a response body which was read into memory. err is
+// set if reading a body installed by a filter failed part way through.
+type responseBuffer struct {
+	res  *http.Response
+	data []byte
+	err  error
+	body io.ReadCloser
+}
+
+// rewind sets the response body to read the buffered data from the start.
+// If a filter has replaced the body since the last rewind, for instance to
+// decode it, the new body is buffered instead. It's a no-op on nil.
+func (b *responseBuffer) rewind() {
+	if b == nil {
+		return
+	}
+
+	if b.body != nil && b.res.Body != b.body {
+		b.data, b.err = io.ReadAll(b.res.Body)
+		b.res.Body.Close()
+	}
+
+	var r io.Reader = bytes.NewReader(b.data)
+	if b.err != nil {
+		r = io.MultiReader(r, errReader{b.err})
+	}
+
+	b.body = io.NopCloser(r)
+	b.res.Body = b.body
+}
+
+// errReader is an io.Reader which always fails with the given error.
+type errReader struct{ err error }
+
+func (e errReader) Read(p []byte) (int, error) { return 0, e.err }
+
+// decodeResponse decodes the response body into v, picking the decoder by
+// the response's Content-Type: JSON, XML, or a url-encoded form. Bodies of
+// other or missing types are decoded as XML if they look like it, and as
+// JSON otherwise. An empty body, such as that of a 204 No Content response,
+// leaves v unchanged. Failures to decode are returned as a DecodeError.
+//
+// The body is read in full and replaced with a buffered copy, so the
+// caller may still read it.
+func decodeResponse(res *http.Response, v interface{}) error {
+	data, err := readBody(res)
+	if err != nil {
+		return err
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil
+	}
+
+	mediaType := responseMediaType(res)
+	switch {
+	case strings.HasSuffix(mediaType, "/json") || strings.HasSuffix(mediaType, "+json"):
+		err = decodeJSON(res, data, v)
+	case strings.HasSuffix(mediaType, "/xml") || strings.HasSuffix(mediaType, "+xml"):
+		err = xml.Unmarshal(data, v)
+	case mediaType == "application/x-www-form-urlencoded":
+		err = decodeForm(codecOf(res), data, v)
+	case trimmed[0] == '<':
+		err = xml.Unmarshal(data, v)
+	default:
+		err = decodeJSON(res, data, v)
+	}
+	if err != nil {
+		return newDecodeError(res, data, err)
+	}
+
+	return nil
+}
+
+// responseMediaType returns the media type of the response body, without
+// parameters such as the charset.
+func responseMediaType(res *http.Response) string {
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	return mediaType
+}
+
+// decodeForm decodes a url-encoded form into v. A *url.Values receives the
+// form as it is; other types are decoded with the codec from a JSON object
+// holding each field's value, or values if it's repeated, as strings.
+func decodeForm(codec Codec, data []byte, v interface{}) error {
+	form, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	if values, ok := v.(*url.Values); ok {
+		*values = form
+		return nil
+	}
+
+	fields := make(map[string]interface{}, len(form))
+	for k, vs := range form {
+		if len(vs) == 1 {
+			fields[k] = vs[0]
+		} else {
+			fields[k] = vs
+		}
+	}
+
+	encoded, err := codec.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	return codec.Unmarshal(encoded, v)
+}
+
+// decodeJSON decodes the JSON body of the response into v with the
+// Client's Codec, keeping numbers as json.Number if the Client was created
+// WithUseNumber.
+func decodeJSON(res *http.Response, data []byte, v interface{}) error {
+	codec := codecOf(res)
+	if res.Request == nil || res.Request.Context().Value(useNumberKey{}) != true {
+		return codec.Unmarshal(data, v)
+	}
+
+	dec := codec.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	var extra interface{}
+	if err := dec.Decode(&extra); err != io.EOF {
+		return errors.New("invalid data after top-level JSON value")
+	}
+
+	return nil
+}
+
+// readBody reads the response body in full, replacing it with a buffered
+// copy.
+func readBody(res *http.Response) ([]byte, error) {
+	data, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(data))
+	return data, err
+}
+
+// encodeXML returns an XML document holding v in a root element of the
+// given name, starting with the XML declaration.
+func encodeXML(v interface{}, root string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).EncodeElement(v, xml.StartElement{Name: xml.Name{Local: root}}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// formatParam returns the string form of a parameter value, as it is sent in
+// query strings. Times are formatted following RFC 3339, and floats without
+// exponents.
+func formatParam(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	return fmt.Sprint(v)
+}
+
+// pathParam formats a URI parameter as a path segment, escaping it so that
+// values containing slashes, spaces or other reserved characters stay
+// within their segment.
+func pathParam(v interface{}) string {
+	return url.PathEscape(formatParam(v))
+}
+
+// parseHeader parses the named response header into the field v points to,
+// declared by a generated Headers struct. Fields may be strings, booleans,
+// numbers, times or types with an UnmarshalText method, pointers to those,
+// which are set only if the header is present, or slices of them, holding
+// each comma-separated value. Times are parsed as HTTP dates or RFC 3339.
+// Fields of absent headers are left untouched.
+func parseHeader(res *http.Response, name string, v interface{}) error {
+	values := res.Header.Values(name)
+	if len(values) == 0 {
+		return nil
+	}
+
+	field := reflect.ValueOf(v).Elem()
+	target := field
+	if field.Kind() == reflect.Ptr {
+		target = reflect.New(field.Type().Elem()).Elem()
+	}
+
+	// Slices such as net.IP which unmarshal themselves hold a single value.
+	var err error
+	if _, ok := target.Addr().Interface().(encoding.TextUnmarshaler); !ok && target.Kind() == reflect.Slice {
+		for _, value := range values {
+			for _, item := range strings.Split(value, ",") {
+				elem := reflect.New(target.Type().Elem()).Elem()
+				if err = parseHeaderValue(elem, item); err != nil {
+					break
+				}
+				target.Set(reflect.Append(target, elem))
+			}
+		}
+	} else {
+		err = parseHeaderValue(target, values[0])
+	}
+	if err != nil {
+		return fmt.Errorf("client: can't parse %s response header %q: %w", name, strings.Join(values, ", "), err)
+	}
+
+	if field.Kind() == reflect.Ptr {
+		field.Set(target.Addr())
+	}
+	return nil
+}
+
+// parseHeaderValue parses a single header value into v, as by parseHeader.
+func parseHeaderValue(v reflect.Value, s string) error {
+	s = strings.TrimSpace(s)
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		t, err := http.ParseTime(s)
+		if err != nil {
+			t, err = time.Parse(time.RFC3339, s)
+		}
+		v.Set(reflect.ValueOf(t))
+		return err
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// formatParams returns the string forms of the values in a slice, as by
+// formatParam.
+func formatParams(slice interface{}) []string {
+	v := reflect.ValueOf(slice)
+	out := make([]string, v.Len())
+	for i := range out {
+		out[i] = formatParam(v.Index(i).Interface())
+	}
+
+	return out
+}
+
+// httpClient returns the http.Client to send requests with.
+func (c *Client) httpClient() *http.Client {
+	if c.HTTP == nil {
+		return http.DefaultClient
+	}
+
+	return c.HTTP
+}
+
+// redirectingClient returns the http.Client to send a request with. If any
+// of the filters follow redirects, it's a copy of the client whose
+// CheckRedirect hands them each redirected request to be sent.
+func (c *Client) redirectingClient(filters []Filter) *http.Client {
+	h := c.httpClient()
+	var followers []redirectFollower
+	for _, f := range filters {
+		if r, ok := f.(redirectFollower); ok {
+			followers = append(followers, r)
+		}
+	}
+	if len(followers) == 0 {
+		return h
+	}
+
+	check := h.CheckRedirect
+	follow := *h
+	follow.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if check != nil {
+			if err := check(req, via); err != nil {
+				return err
+			}
+		} else if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+
+		for _, f := range followers {
+			f.followRedirect(req)
+		}
+		return nil
+	}
+
+	return &follow
+}
+
+// exchange holds bookkeeping for a single attempt of a request. Filters can
+// retrieve it from the context of the request, or of the response's request.
+type exchange struct {
+	// req is the request as it was passed to the filters.
+	req *http.Request
+	// start and elapsed time the HTTP round trip, excluding any filters.
+	start   time.Time
+	elapsed time.Duration
+	// etag is the ETag a CacheFilter revalidated the request with.
+	etag string
+}
+
+type exchangeKey struct{}
+
+// exchangeFrom returns the exchange stored in the context. It returns an
+// empty exchange if there is none, so callers need not check for nil.
+func exchangeFrom(ctx context.Context) *exchange {
+	if ex, ok := ctx.Value(exchangeKey{}).(*exchange); ok {
+		return ex
+	}
+
+	return &exchange{}
+}
+
+type routeKey struct{}
+
+// withRoute returns a context carrying the RAML route template of the
+// method being called, such as "/users/{userId}". Generated methods stamp
+// their route on the context so that filters can identify the endpoint.
+func withRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// routeFrom returns the route template stored in the context, or an empty
+// string if there is none.
+func routeFrom(ctx context.Context) string {
+	route, _ := ctx.Value(routeKey{}).(string)
+	return route
+}
+
+type protocolsKey struct{}
+
+// withProtocols returns a context restricting the method being called to
+// the given protocols, in place of the API's allowedProtocols.
+func withProtocols(ctx context.Context, protocols ...string) context.Context {
+	return context.WithValue(ctx, protocolsKey{}, protocols)
+}
+
+// checkProtocol returns an error unless the URL uses one of the protocols
+// the API allows for the method being called. Any is allowed if the API
+// doesn't say.
+func checkProtocol(ctx context.Context, u *url.URL) error {
+	allowed, ok := ctx.Value(protocolsKey{}).([]string)
+	if !ok {
+		allowed = allowedProtocols
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	for _, protocol := range allowed {
+		if strings.EqualFold(u.Scheme, protocol) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("client: the API doesn't allow %s requests, only %s", u.Scheme, strings.Join(allowed, " or "))
+}
+
+type unsecuredKey struct{}
+
+// withoutAuth returns a context marking the method being called as one the
+// API leaves unsecured, by declaring `securedBy: [null]`.
+func withoutAuth(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unsecuredKey{}, true)
+}
+
+// RequiresAuth returns whether the request calls a method which the API
+// secures. Filters which authenticate requests leave those for unsecured
+// methods untouched, and custom ones should do the same.
+func RequiresAuth(req *http.Request) bool {
+	unsecured, _ := req.Context().Value(unsecuredKey{}).(bool)
+	return !unsecured
+}
+
+// shouldRetry asks each retrier whether the attempt should be made again,
+// returning the longest delay any of them requested.
+func shouldRetry(retriers []Retrier, req *http.Request, res *http.Response, err error, attempt int) (time.Duration, bool) {
+	var (
+		wait  time.Duration
+		retry bool
+	)
+
+	for _, r := range retriers {
+		if d, ok := r.Retry(req, res, err, attempt); ok {
+			retry = true
+			if d > wait {
+				wait = d
+			}
+		}
+	}
+
+	return wait, retry
+}
+
+// bufferBody reads the request body into memory, if it has one which
+// cannot already be replayed, and sets GetBody to rewind it.
+func bufferBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.Body, _ = req.GetBody()
+	req.ContentLength = int64(len(data))
+
+	return nil
+}
+
 // A Filter can be attached to the Client to modify outgoing requests.
 // They can be used to implement authentication, user-agent handling, etc.
+//
+// Before is called, in the order filters were added, prior to sending each
+// request. It may modify the request, or return an error to veto it: the
+// remaining filters are skipped, no HTTP call is made and the error is
+// returned to the caller as-is. Filters which never fail should return nil.
+// Note that this differs from earlier versions of the client, where Before
+// had no return value.
+//
+// After is called once a response is received, regardless of its status.
+// AfterError is called instead when no response could be obtained, such as
+// on network failures, timeouts or cancellation of the request's context.
+// Exactly one of the two is called for each request which is sent. They run
+// in the reverse order of Before, like unwinding middleware, so the filter
+// added first sees the response last.
 type Filter interface {
-	Before(request *http.Request)
-	After(response *http.Response)
+	Before(req *http.Request) error
+	After(res *http.Response)
+	AfterError(req *http.Request, err error)
+}
+
+// A Retrier is a Filter which may ask for a request to be sent again once
+// it has seen the outcome of an attempt. Retry is called after the After or
+// AfterError hooks have run; res is nil whenever err is not. Attempts are
+// numbered from 1. It returns how long to wait before the next attempt and
+// whether one should be made at all.
+type Retrier interface {
+	Filter
+	Retry(req *http.Request, res *http.Response, err error, attempt int) (time.Duration, bool)
+}
+
+// A callPreparer is a Filter which edits requests once per call, before the
+// attempts to send it, so its changes carry over to retries.
+type callPreparer interface {
+	Filter
+	prepareCall(req *http.Request)
+}
+
+// A bodyBufferer is a Retrier whose retries must resend the request body,
+// which the Client buffers for it if it can't otherwise be replayed.
+type bodyBufferer interface {
+	Retrier
+	buffersBodies()
+}
+
+// A redirectFollower is a Filter which sees each redirected request before
+// it's sent, with the redirect response as its Response.
+type redirectFollower interface {
+	Filter
+	followRedirect(req *http.Request)
 }
 
 // cookieJar stores HTTP cookies, adding them to requests and updating
-// the jar based on responses.
+// the jar based on responses, including redirects.
 type cookieJar struct{ jar http.CookieJar }
 
-var _ Filter = new(cookieJar)
+var _ redirectFollower = new(cookieJar)
+
+func (c *cookieJar) Before(req *http.Request) error {
+	for _, cookie := range c.jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
+	}
+
+	return nil
+}
+
+func (c *cookieJar) After(res *http.Response) {
+	if cookies := res.Cookies(); len(cookies) > 0 {
+		c.jar.SetCookies(res.Request.URL, cookies)
+	}
+}
+
+func (c *cookieJar) AfterError(req *http.Request, err error) {}
+
+func (c *cookieJar) followRedirect(req *http.Request) {
+	if res := req.Response; res != nil {
+		c.After(res)
+	}
 
-func (c *cookieJar) Before(req *http.Request) { req.Jar = c.jar }
-func (c *cookieJar) After(res *http.Response) {}
+	req.Header.Del("Cookie")
+	c.Before(req)
+}
 
-// oauthFilter adds an `Authorization` header to outgoing requests.
-type oauthFilter struct{ token string }
+// oauthFilter adds an `Authorization` header to outgoing requests, with
+// the token prefixed by the scheme name.
+type oauthFilter struct{ scheme, token string }
 
 var _ Filter = new(oauthFilter)
 
-func (o *oauthFilter) Before(req *http.Request) { req.Header.Set("Authorization", "OAuth "+o.token) }
-func (o *oauthFilter) After(res *http.Response) {}
+func (o *oauthFilter) Before(req *http.Request) error {
+	if !RequiresAuth(req) {
+		return nil
+	}
+
+	req.Header.Set("Authorization", o.scheme+" "+o.token)
+	return nil
+}
+
+func (o *oauthFilter) After(res *http.Response)                {}
+func (o *oauthFilter) AfterError(req *http.Request, err error) {}
+
+// A TokenRefresher obtains a new access token along with the time it
+// expires at. It may return a zero expiry if that is unknown.
+type TokenRefresher func() (token string, expiry time.Time, err error)
+
+// tokenRefreshMargin is how long before its expiry a token is refreshed.
+const tokenRefreshMargin = 30 * time.Second
+
+// refreshingOAuthFilter is an oauthFilter which replaces its token when it
+// is about to expire or is rejected. The refresh is guarded so that
+// concurrent requests trigger only a single call to the refresher.
+type refreshingOAuthFilter struct {
+	scheme  string
+	refresh TokenRefresher
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+	stale  bool
+}
+
+var _ Retrier = new(refreshingOAuthFilter)
+
+func (o *refreshingOAuthFilter) Before(req *http.Request) error {
+	if !RequiresAuth(req) {
+		return nil
+	}
+
+	token, err := o.current()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", o.scheme+" "+token)
+	return nil
+}
+
+// After marks the token as stale if the server rejected it. Requests which
+// were sent with an older token are ignored, as it's already been replaced.
+func (o *refreshingOAuthFilter) After(res *http.Response) {
+	if res.StatusCode != http.StatusUnauthorized {
+		return
+	}
+
+	o.mu.Lock()
+	if res.Request.Header.Get("Authorization") == o.scheme+" "+o.token {
+		o.stale = true
+	}
+	o.mu.Unlock()
+}
+
+func (o *refreshingOAuthFilter) AfterError(req *http.Request, err error) {}
+
+// Retry resends a request rejected with a 401, once, with the new token.
+// Requests whose bodies can't be replayed, as they aren't buffered for it,
+// aren't resent.
+func (o *refreshingOAuthFilter) Retry(req *http.Request, res *http.Response, err error, attempt int) (time.Duration, bool) {
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	return 0, attempt == 1 && replayable && RequiresAuth(req) && res != nil && res.StatusCode == http.StatusUnauthorized
+}
+
+// current returns the token to use, refreshing it first if needed.
+func (o *refreshingOAuthFilter) current() (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.stale || (!o.expiry.IsZero() && time.Until(o.expiry) < tokenRefreshMargin) {
+		token, expiry, err := o.refresh()
+		if err != nil {
+			return "", err
+		}
+
+		o.token, o.expiry, o.stale = token, expiry, false
+	}
+
+	return o.token, nil
+}
+
+// basicAuthFilter adds a Basic `Authorization` header to outgoing requests.
+type basicAuthFilter struct{ username, password string }
+
+var _ Filter = new(basicAuthFilter)
+
+func (b *basicAuthFilter) Before(req *http.Request) error {
+	if RequiresAuth(req) {
+		req.SetBasicAuth(b.username, b.password)
+	}
+
+	return nil
+}
+
+func (b *basicAuthFilter) After(res *http.Response)                {}
+func (b *basicAuthFilter) AfterError(req *http.Request, err error) {}
+
+// userAgentFilter sets the `User-Agent` header unless the request has one.
+type userAgentFilter struct{ ua string }
+
+var _ Filter = new(userAgentFilter)
+
+func (u *userAgentFilter) Before(req *http.Request) error {
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", u.ua)
+	}
+
+	return nil
+}
+
+func (u *userAgentFilter) After(res *http.Response)                {}
+func (u *userAgentFilter) AfterError(req *http.Request, err error) {}
+
+// APIKeyLocation is where an APIKeyFilter places the key in requests.
+type APIKeyLocation int
+
+const (
+	// APIKeyInHeader sends the key as a request header.
+	APIKeyInHeader APIKeyLocation = iota
+	// APIKeyInQuery sends the key as a query string parameter.
+	APIKeyInQuery
+)
+
+// APIKeyFilter authenticates requests with a static API key, sent in a
+// header or query string parameter. Other query parameters on the request
+// are left untouched.
+type APIKeyFilter struct {
+	name, value string
+	in          APIKeyLocation
+}
+
+var _ Filter = new(APIKeyFilter)
+
+// NewAPIKeyFilter creates an APIKeyFilter sending the value under the name.
+func NewAPIKeyFilter(name, value string, in APIKeyLocation) *APIKeyFilter {
+	return &APIKeyFilter{name: name, value: value, in: in}
+}
+
+func (a *APIKeyFilter) Before(req *http.Request) error {
+	if !RequiresAuth(req) {
+		return nil
+	}
+
+	switch a.in {
+	case APIKeyInQuery:
+		setQueryParam(req.URL, a.name, a.value)
+	default:
+		req.Header.Set(a.name, a.value)
+	}
+
+	return nil
+}
+
+func (a *APIKeyFilter) After(res *http.Response)                {}
+func (a *APIKeyFilter) AfterError(req *http.Request, err error) {}
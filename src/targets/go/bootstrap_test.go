@@ -0,0 +1,1290 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testFilter is a Filter which runs the given functions, any of which may
+// be nil.
+type testFilter struct {
+	before     func(req *http.Request) error
+	after      func(res *http.Response)
+	afterError func(req *http.Request, err error)
+}
+
+func (f *testFilter) Before(req *http.Request) error {
+	if f.before == nil {
+		return nil
+	}
+
+	return f.before(req)
+}
+
+func (f *testFilter) After(res *http.Response) {
+	if f.after != nil {
+		f.after(res)
+	}
+}
+
+func (f *testFilter) AfterError(req *http.Request, err error) {
+	if f.afterError != nil {
+		f.afterError(req, err)
+	}
+}
+
+// newTestServer starts a server answering requests with handler, which is
+// closed when the test finishes.
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// get sends a GET request for the path through the client.
+func get(t *testing.T, c *Client, path string, opts ...CallOption) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c.do(context.Background(), req, opts...)
+}
+
+// readAll reads and closes the response body.
+func readAll(t *testing.T, res *http.Response) string {
+	t.Helper()
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func TestDoSendsRequest(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/users" {
+			t.Errorf("got path %q, want /v1/users", r.URL.Path)
+		}
+		io.WriteString(w, "ok")
+	})
+
+	var before, after int
+	c := NewClient(srv.URL + "/v1")
+	c.AddFilter(&testFilter{
+		before: func(req *http.Request) error { before++; return nil },
+		after:  func(res *http.Response) { after++ },
+	})
+
+	res, err := get(t, c, "/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readAll(t, res); body != "ok" {
+		t.Errorf("got body %q, want ok", body)
+	}
+	if before != 1 || after != 1 {
+		t.Errorf("filters ran Before %d and After %d times, want once each", before, after)
+	}
+}
+
+func TestBeforeErrorVetoesRequest(t *testing.T) {
+	var hits int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	})
+
+	veto := errors.New("no token")
+	var later, after int
+	c := NewClient(srv.URL)
+	c.AddFilter(&testFilter{
+		before: func(req *http.Request) error { return veto },
+		after:  func(res *http.Response) { after++ },
+	})
+	c.AddFilter(&testFilter{
+		before: func(req *http.Request) error { later++; return nil },
+	})
+
+	res, err := get(t, c, "/")
+	if err != veto {
+		t.Fatalf("got error %v, want the filter's", err)
+	}
+	if res != nil {
+		t.Error("got a response to a vetoed request")
+	}
+	if n := atomic.LoadInt32(&hits); n != 0 {
+		t.Errorf("server received %d requests, want none", n)
+	}
+	if later != 0 || after != 0 {
+		t.Error("filters ran after the request was vetoed")
+	}
+}
+
+func TestCancelledContext(t *testing.T) {
+	release := make(chan struct{})
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+
+	var failed error
+	c := NewClient(srv.URL)
+	c.AddFilter(&testFilter{afterError: func(req *http.Request, err error) { failed = err }})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest("GET", "/slow", nil)
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.do(ctx, req)
+		done <- err
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("got error %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the call didn't return after its context was cancelled")
+	}
+	if failed != context.Canceled {
+		t.Errorf("AfterError got %v, want context.Canceled", failed)
+	}
+}
+
+// failingTransport fails every request with err.
+type failingTransport struct{ err error }
+
+func (f failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, f.err
+}
+
+func TestAfterErrorSeesTransportErrors(t *testing.T) {
+	refused := errors.New("connection refused")
+	c := NewClient("https://api.example.com", WithTransport(failingTransport{refused}))
+	c.UseOAuth("token")
+	c.EnableCookies()
+
+	var after int
+	var failed []error
+	c.AddFilter(&testFilter{
+		after:      func(res *http.Response) { after++ },
+		afterError: func(req *http.Request, err error) { failed = append(failed, err) },
+	})
+
+	_, err := get(t, c, "/users")
+	if !IsTransportError(err) || !errors.Is(err, refused) {
+		t.Fatalf("got error %v, want a TransportError wrapping the transport's", err)
+	}
+	if after != 0 {
+		t.Error("After ran for a request which failed")
+	}
+	if len(failed) != 1 || failed[0] != err {
+		t.Errorf("AfterError got %v, want the returned error once", failed)
+	}
+}
+
+// roundTripFunc is an http.RoundTripper calling the function.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// sentRequest makes a GET request with a Client configured by setup and the
+// call options, returning the request as the transport received it.
+func sentRequest(t *testing.T, setup func(c *Client), opts ...CallOption) *http.Request {
+	t.Helper()
+	var sent *http.Request
+	c := NewClient("https://api.example.com", WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: http.Header{}, Request: req}, nil
+	})))
+	setup(c)
+
+	res, err := get(t, c, "/", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	return sent
+}
+
+func TestUseBasicAuth(t *testing.T) {
+	for _, tc := range []struct{ username, password, want string }{
+		{"Aladdin", "open sesame", "Basic QWxhZGRpbjpvcGVuIHNlc2FtZQ=="},
+		{"jürgen", "pa:ss:wörd", "Basic asO8cmdlbjpwYTpzczp3w7ZyZA=="},
+	} {
+		req := sentRequest(t, func(c *Client) { c.UseBasicAuth(tc.username, tc.password) })
+		if got := req.Header.Get("Authorization"); got != tc.want {
+			t.Errorf("%s:%s: got Authorization %q, want %q", tc.username, tc.password, got, tc.want)
+		}
+	}
+}
+
+func TestSetUserAgent(t *testing.T) {
+	req := sentRequest(t, func(c *Client) { c.SetUserAgent(DefaultUserAgent) })
+	if got := req.Header.Get("User-Agent"); got != DefaultUserAgent {
+		t.Errorf("got User-Agent %q, want %q", got, DefaultUserAgent)
+	}
+
+	req = sentRequest(t, func(c *Client) { c.SetUserAgent(DefaultUserAgent) }, WithHeader("User-Agent", "Custom/2.0"))
+	if got := req.Header.Get("User-Agent"); got != "Custom/2.0" {
+		t.Errorf("got User-Agent %q, want the request's own", got)
+	}
+}
+
+func TestBufferResponsesForFilters(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "full body")
+	})
+
+	c := NewClient(srv.URL)
+	c.BufferResponses(1024)
+	var read []string
+	reader := &testFilter{after: func(res *http.Response) {
+		data, _ := io.ReadAll(res.Body)
+		read = append(read, string(data))
+	}}
+	c.AddFilter(reader)
+	c.AddFilter(&testFilter{after: reader.after})
+
+	res, err := get(t, c, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readAll(t, res); body != "full body" {
+		t.Errorf("caller read %q, want the full body", body)
+	}
+	if len(read) != 2 || read[0] != "full body" || read[1] != "full body" {
+		t.Errorf("filters read %q, want the full body each", read)
+	}
+}
+
+func TestBufferResponsesLimit(t *testing.T) {
+	large := strings.Repeat("x", 100)
+	for _, chunked := range []bool{false, true} {
+		srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if chunked {
+				w.(http.Flusher).Flush()
+			}
+			io.WriteString(w, large)
+		})
+
+		// Unbuffered bodies aren't rewound, so what the filter reads is
+		// gone by the time the caller reads the body.
+		c := NewClient(srv.URL)
+		c.BufferResponses(10)
+		c.AddFilter(&testFilter{after: func(res *http.Response) {
+			io.ReadFull(res.Body, make([]byte, 5))
+		}})
+
+		res, err := get(t, c, "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body := readAll(t, res); len(body) != len(large)-5 {
+			t.Errorf("chunked %v: caller read %d bytes, want the %d the filter left", chunked, len(body), len(large)-5)
+		}
+	}
+}
+
+func TestOAuthSchemes(t *testing.T) {
+	req := sentRequest(t, func(c *Client) { c.UseOAuth("abc123") })
+	if got := req.Header.Get("Authorization"); got != "OAuth abc123" {
+		t.Errorf("UseOAuth sent Authorization %q, want %q", got, "OAuth abc123")
+	}
+
+	req = sentRequest(t, func(c *Client) { c.UseBearerToken("abc123") })
+	if got := req.Header.Get("Authorization"); got != "Bearer abc123" {
+		t.Errorf("UseBearerToken sent Authorization %q, want %q", got, "Bearer abc123")
+	}
+}
+
+func TestOAuthRefreshBeforeExpiry(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "OAuth fresh" {
+			t.Errorf("got Authorization %q, want the refreshed token", got)
+		}
+	})
+
+	var refreshes int32
+	c := NewClient(srv.URL)
+	c.UseOAuthWithRefresh("expiring", time.Now().Add(time.Second), func() (string, time.Time, error) {
+		atomic.AddInt32(&refreshes, 1)
+		return "fresh", time.Now().Add(time.Hour), nil
+	})
+
+	callConcurrently(t, c, 20)
+	if n := atomic.LoadInt32(&refreshes); n != 1 {
+		t.Errorf("refreshed %d times, want once", n)
+	}
+}
+
+func TestOAuthRefreshAfterRejection(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "OAuth fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	var refreshes int32
+	c := NewClient(srv.URL)
+	c.UseOAuthWithRefresh("revoked", time.Time{}, func() (string, time.Time, error) {
+		atomic.AddInt32(&refreshes, 1)
+		return "fresh", time.Time{}, nil
+	})
+
+	callConcurrently(t, c, 20)
+	if n := atomic.LoadInt32(&refreshes); n != 1 {
+		t.Errorf("refreshed %d times, want once", n)
+	}
+}
+
+func TestOAuthRefreshResendsReplayableBodies(t *testing.T) {
+	var attempts int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		if body, _ := io.ReadAll(r.Body); string(body) != "payload" {
+			t.Errorf("got body %q, want payload", body)
+		}
+		if r.Header.Get("Authorization") != "OAuth fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	for _, tc := range []struct {
+		body     io.Reader
+		status   int
+		attempts int32
+	}{
+		{strings.NewReader("payload"), http.StatusOK, 2},
+		// A reader without a Len hides the body from http.NewRequest, so it
+		// can't be replayed.
+		{io.MultiReader(strings.NewReader("payload")), http.StatusUnauthorized, 1},
+	} {
+		atomic.StoreInt32(&attempts, 0)
+		c := NewClient(srv.URL)
+		c.UseOAuthWithRefresh("revoked", time.Time{}, func() (string, time.Time, error) {
+			return "fresh", time.Time{}, nil
+		})
+
+		req, _ := http.NewRequest("PUT", "/", tc.body)
+		res, err := c.do(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != tc.status {
+			t.Errorf("got status %d, want %d", res.StatusCode, tc.status)
+		}
+		if n := atomic.LoadInt32(&attempts); n != tc.attempts {
+			t.Errorf("made %d attempts, want %d", n, tc.attempts)
+		}
+	}
+}
+
+func TestOAuthRefreshFailure(t *testing.T) {
+	failed := errors.New("refresh failed")
+	c := NewClient("https://api.example.com")
+	c.UseOAuthWithRefresh("expired", time.Now(), func() (string, time.Time, error) {
+		return "", time.Time{}, failed
+	})
+
+	if _, err := get(t, c, "/"); err != failed {
+		t.Errorf("got error %v, want the refresher's", err)
+	}
+}
+
+func TestOAuthRefreshStreamsUploads(t *testing.T) {
+	received := make(chan struct{})
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		first := make([]byte, len("first"))
+		if _, err := io.ReadFull(r.Body, first); err != nil {
+			t.Error(err)
+		}
+		close(received)
+		io.Copy(io.Discard, r.Body)
+	})
+
+	c := NewClient(srv.URL)
+	c.UseOAuthWithRefresh("token", time.Time{}, func() (string, time.Time, error) {
+		return "token", time.Time{}, nil
+	})
+
+	// The rest of the upload is only written once the server has the start
+	// of it, which it never would if the Client read all of it first.
+	pr, pw := io.Pipe()
+	go func() {
+		io.WriteString(pw, "first")
+		select {
+		case <-received:
+			io.WriteString(pw, "second")
+			pw.Close()
+		case <-time.After(5 * time.Second):
+			pw.CloseWithError(errors.New("the upload was read ahead"))
+		}
+	}()
+
+	req, _ := http.NewRequest("PUT", "/", pr)
+	res, err := c.do(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+}
+
+// callConcurrently makes n GET requests at once, failing the test unless
+// they all succeed.
+func callConcurrently(t *testing.T, c *Client, n int) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := get(t, c, "/")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+			if res.StatusCode != http.StatusOK {
+				t.Errorf("got status %d, want 200", res.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	c := NewClient("")
+	if c.HTTP == nil || c.HTTP.Timeout != DefaultTimeout {
+		t.Errorf("got http.Client %+v, want one with DefaultTimeout", c.HTTP)
+	}
+	if c.BaseURL() != BaseURL {
+		t.Errorf("got base URL %q, want BaseURL", c.BaseURL())
+	}
+
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {})
+	for _, c := range []*Client{NewClient(srv.URL), {baseURL: srv.URL}} {
+		res, err := get(t, c, "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+}
+
+func TestNewClientOptions(t *testing.T) {
+	h := &http.Client{}
+	f := &testFilter{}
+	c := NewClient("https://staging.example.com", WithHTTPClient(h), WithFilters(f))
+	if c.HTTP != h {
+		t.Error("WithHTTPClient didn't set the http.Client")
+	}
+	if c.BaseURL() != "https://staging.example.com" {
+		t.Errorf("got base URL %q, want the one given", c.BaseURL())
+	}
+	if filters := c.snapshotFilters(); len(filters) != 1 || filters[0] != f {
+		t.Errorf("got filters %v, want the one given", filters)
+	}
+}
+
+func TestDefaultOptions(t *testing.T) {
+	first, second, own := &testFilter{}, &testFilter{}, &testFilter{}
+	h := &http.Client{}
+	DefaultOptions = []Option{WithHTTPClient(h), WithFilters(first, second)}
+	defer func() { DefaultOptions = nil }()
+
+	c := NewClient("https://api.example.com", WithFilters(own))
+	c.AddFilter(&testFilter{})
+	filters := c.snapshotFilters()
+	if len(filters) != 4 || filters[0] != first || filters[1] != second || filters[2] != own {
+		t.Errorf("got filters %v, want the defaults first, in order", filters)
+	}
+	if c.HTTP != h {
+		t.Error("the default http.Client wasn't used")
+	}
+
+	// Options passed to NewClient override the defaults.
+	other := &http.Client{}
+	if c := NewClient("https://api.example.com", WithHTTPClient(other)); c.HTTP != other {
+		t.Error("the http.Client passed to NewClient wasn't used")
+	}
+
+	DefaultOptions = nil
+	if filters := NewClient("https://api.example.com").snapshotFilters(); len(filters) != 0 {
+		t.Errorf("got filters %v once the defaults were cleared", filters)
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	var sent []*http.Request
+	recorder := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = append(sent, req)
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Request: req}, nil
+	})
+
+	h := &http.Client{Timeout: time.Minute}
+	c := NewClient("https://api.example.com/v1", WithHTTPClient(h), WithTransport(recorder))
+	if h.Transport != nil {
+		t.Error("WithTransport modified the http.Client passed to WithHTTPClient")
+	}
+	if c.HTTP.Timeout != time.Minute {
+		t.Errorf("got timeout %v, want the http.Client's", c.HTTP.Timeout)
+	}
+
+	res, err := get(t, c, "/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if len(sent) != 1 || sent[0].URL.String() != "https://api.example.com/v1/users" {
+		t.Errorf("recorded %v, want the request for /users", sent)
+	}
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	// The handshake the untrusting client fails is logged otherwise.
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	if _, err := get(t, NewClient(srv.URL), "/"); err == nil {
+		t.Fatal("trusted the test server's certificate without being told to")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	c := NewClient(srv.URL, WithTLSConfig(&tls.Config{RootCAs: pool}))
+	res, err := get(t, c, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if config := http.DefaultTransport.(*http.Transport).TLSClientConfig; config != nil && config.RootCAs == pool {
+		t.Error("WithTLSConfig modified http.DefaultTransport")
+	}
+
+	// Transports set by WithTransport are left as they are.
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) { return nil, errors.New("unused") })
+	c = NewClient(srv.URL, WithTransport(rt), WithTLSConfig(&tls.Config{}))
+	if _, ok := c.HTTP.Transport.(roundTripFunc); !ok {
+		t.Errorf("got transport %T, want the one given to WithTransport", c.HTTP.Transport)
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	var hosts []string
+	proxy := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.URL.Host)
+	})
+	u, _ := url.Parse(proxy.URL)
+
+	c := NewClient("http://api.example.com", WithProxy(u))
+	res, err := get(t, c, "/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if len(hosts) != 1 || hosts[0] != "api.example.com" {
+		t.Errorf("the proxy got requests for %q, want api.example.com", hosts)
+	}
+}
+
+// redirectServer redirects /hop/n to /hop/n-1, and /hop/0 to /final. It
+// sets a cookie named after the hop on each redirect.
+func redirectServer(t *testing.T) *httptest.Server {
+	return newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		if _, err := fmt.Sscanf(r.URL.Path, "/hop/%d", &n); err == nil {
+			http.SetCookie(w, &http.Cookie{Name: fmt.Sprintf("hop%d", n), Value: "1", Path: "/"})
+			next := "/final"
+			if n > 0 {
+				next = fmt.Sprintf("/hop/%d", n-1)
+			}
+			http.Redirect(w, r, next, http.StatusFound)
+			return
+		}
+		io.WriteString(w, "final")
+	})
+}
+
+func TestRedirectsAreFollowed(t *testing.T) {
+	srv := redirectServer(t)
+	c := NewClient(srv.URL)
+	c.EnableCookiesWithOptions(&cookiejar.Options{PublicSuffixList: testSuffixList{}})
+
+	res, err := get(t, c, "/hop/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readAll(t, res); body != "final" || res.Request.URL.Path != "/final" {
+		t.Errorf("landed on %s, reading %q, want /final", res.Request.URL.Path, body)
+	}
+
+	// The cookie filter follows the redirects, so the last request carried
+	// the cookies each redirect set.
+	for _, name := range []string{"hop0", "hop1", "hop2"} {
+		if _, err := res.Request.Cookie(name); err != nil {
+			t.Errorf("the redirected request didn't carry the %s cookie", name)
+		}
+	}
+}
+
+func TestWithoutRedirects(t *testing.T) {
+	srv := redirectServer(t)
+	res, err := get(t, NewClient(srv.URL, WithoutRedirects()), "/hop/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusFound || res.Header.Get("Location") != "/final" {
+		t.Errorf("got %d to %q, want the 302 to /final", res.StatusCode, res.Header.Get("Location"))
+	}
+}
+
+func TestWithMaxRedirects(t *testing.T) {
+	srv := redirectServer(t)
+	c := NewClient(srv.URL, WithMaxRedirects(2))
+
+	res, err := get(t, c, "/hop/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readAll(t, res); body != "final" {
+		t.Errorf("got body %q after two redirects, want final", body)
+	}
+	if _, err := get(t, c, "/hop/2"); err == nil || !strings.Contains(err.Error(), "stopped after 2 redirects") {
+		t.Errorf("got error %v after three redirects, want the limit's", err)
+	}
+}
+
+func TestWithCheckRedirect(t *testing.T) {
+	srv := redirectServer(t)
+	var seen []string
+	c := NewClient(srv.URL, WithCheckRedirect(func(req *http.Request, via []*http.Request) error {
+		seen = append(seen, req.URL.Path)
+		if req.URL.Path == "/hop/0" {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}))
+
+	res, err := get(t, c, "/hop/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.Header.Get("Location") != "/hop/0" {
+		t.Errorf("got a redirect to %q, want the one to /hop/0", res.Header.Get("Location"))
+	}
+	if len(seen) != 2 || seen[0] != "/hop/1" || seen[1] != "/hop/0" {
+		t.Errorf("checked redirects to %q, want /hop/1 and /hop/0", seen)
+	}
+}
+
+func TestSetBaseURL(t *testing.T) {
+	var paths []string
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+	})
+
+	c := NewClient("https://api.example.com")
+	for _, baseURL := range []string{srv.URL + "/v2", srv.URL + "/v3/"} {
+		if err := c.SetBaseURL(baseURL); err != nil {
+			t.Fatal(err)
+		}
+		if c.BaseURL() != baseURL {
+			t.Errorf("got base URL %q, want %q", c.BaseURL(), baseURL)
+		}
+		res, err := get(t, c, "/users?page=2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	if want := []string{"/v2/users?page=2", "/v3/users?page=2"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("requested %q, want %q", paths, want)
+	}
+
+	for _, baseURL := range []string{"", "/v2", "api.example.com", "http://[::1"} {
+		if err := c.SetBaseURL(baseURL); err == nil {
+			t.Errorf("set the base URL to %q", baseURL)
+		}
+	}
+	if c.BaseURL() != srv.URL+"/v3/" {
+		t.Errorf("invalid base URLs replaced %q with %q", srv.URL+"/v3/", c.BaseURL())
+	}
+}
+
+func TestCheckProtocol(t *testing.T) {
+	defer func(protocols []string) { allowedProtocols = protocols }(allowedProtocols)
+
+	httpURL, _ := url.Parse("http://api.example.com/")
+	httpsURL, _ := url.Parse("https://api.example.com/")
+	ctx := context.Background()
+
+	allowedProtocols = nil
+	if err := checkProtocol(ctx, httpURL); err != nil {
+		t.Errorf("APIs without protocols refused HTTP: %v", err)
+	}
+
+	allowedProtocols = []string{"HTTPS"}
+	if err := checkProtocol(ctx, httpsURL); err != nil {
+		t.Errorf("HTTPS-only APIs refused HTTPS: %v", err)
+	}
+	err := checkProtocol(ctx, httpURL)
+	if want := "client: the API doesn't allow http requests, only HTTPS"; err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+
+	// Methods may allow protocols the API doesn't, or the other way round.
+	if err := checkProtocol(withProtocols(ctx, "http", "https"), httpURL); err != nil {
+		t.Errorf("methods allowing HTTP refused it: %v", err)
+	}
+	allowedProtocols = nil
+	if err := checkProtocol(withProtocols(ctx, "https"), httpURL); err == nil {
+		t.Error("methods allowing only HTTPS accepted HTTP")
+	}
+}
+
+func TestProtocolsAreEnforced(t *testing.T) {
+	defer func(protocols []string) { allowedProtocols = protocols }(allowedProtocols)
+	allowedProtocols = []string{"https"}
+
+	var calls int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	})
+
+	if err := NewClient("https://api.example.com").SetBaseURL(srv.URL); err == nil {
+		t.Errorf("set an HTTP base URL for an HTTPS-only API")
+	}
+	if _, err := get(t, NewClient(srv.URL), "/"); err == nil {
+		t.Error("sent a request over HTTP to an HTTPS-only API")
+	}
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Errorf("the server got %d requests, want none", n)
+	}
+
+	tls := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tls.Close()
+	c := NewClient("https://api.example.com", WithHTTPClient(tls.Client()))
+	if err := c.SetBaseURL(tls.URL); err != nil {
+		t.Fatal(err)
+	}
+	res, err := get(t, c, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+}
+
+func TestPathParam(t *testing.T) {
+	for _, tc := range []struct {
+		v    interface{}
+		want string
+	}{
+		{"acme", "acme"},
+		{"acme/inc", "acme%2Finc"},
+		{"my repo", "my%20repo"},
+		{"a?b#c", "a%3Fb%23c"},
+		{int64(42), "42"},
+	} {
+		if got := pathParam(tc.v); got != tc.want {
+			t.Errorf("pathParam(%#v) = %q, want %q", tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	res := &http.Response{Header: http.Header{
+		"X-Total-Count": {"42"},
+		"X-Tags":        {"a, b", "c"},
+		"X-Ratio":       {"0.5"},
+		"X-Enabled":     {"true"},
+		"Last-Modified": {date.Format(http.TimeFormat)},
+		"X-Expires":     {date.Format(time.RFC3339)},
+		"X-Address":     {"10.0.0.1"},
+		"X-Bad":         {"lots"},
+	}}
+
+	var headers struct {
+		Count    int64
+		Missing  *int64
+		Present  *int64
+		Tags     []string
+		Ratio    float64
+		Enabled  bool
+		Modified time.Time
+		Expires  time.Time
+		Address  net.IP
+	}
+	headers.Count = -1
+	for _, tc := range []struct {
+		name string
+		v    interface{}
+	}{
+		{"X-Total-Count", &headers.Count},
+		{"X-Missing", &headers.Missing},
+		{"X-Total-Count", &headers.Present},
+		{"X-Tags", &headers.Tags},
+		{"X-Ratio", &headers.Ratio},
+		{"X-Enabled", &headers.Enabled},
+		{"Last-Modified", &headers.Modified},
+		{"X-Expires", &headers.Expires},
+		{"X-Address", &headers.Address},
+	} {
+		if err := parseHeader(res, tc.name, tc.v); err != nil {
+			t.Errorf("parsing %s: %v", tc.name, err)
+		}
+	}
+
+	if headers.Count != 42 || headers.Missing != nil || headers.Present == nil || *headers.Present != 42 {
+		t.Errorf("got counts %d, %v and %v, want 42, nil and 42", headers.Count, headers.Missing, headers.Present)
+	}
+	if !reflect.DeepEqual(headers.Tags, []string{"a", "b", "c"}) {
+		t.Errorf("got tags %q, want a, b and c", headers.Tags)
+	}
+	if headers.Ratio != 0.5 || !headers.Enabled {
+		t.Errorf("got ratio %v and enabled %v", headers.Ratio, headers.Enabled)
+	}
+	if !headers.Modified.Equal(date) || !headers.Expires.Equal(date) {
+		t.Errorf("got times %v and %v, want %v", headers.Modified, headers.Expires, date)
+	}
+	if !headers.Address.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("got address %v, want 10.0.0.1", headers.Address)
+	}
+
+	var bad int64
+	err := parseHeader(res, "X-Bad", &bad)
+	if err == nil || err.Error() != `client: can't parse X-Bad response header "lots": strconv.ParseInt: parsing "lots": invalid syntax` {
+		t.Errorf("got error %v parsing a bad integer", err)
+	}
+}
+
+func TestRequestBodies(t *testing.T) {
+	var requests int
+	var got *http.Request
+	var body string
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		got = r
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	})
+	c := NewClient(srv.URL)
+
+	// A Content-Type without a body is dropped.
+	for _, method := range []string{"GET", "DELETE"} {
+		req, _ := http.NewRequest(method, "/notes", nil)
+		res, err := c.do(context.Background(), req, WithHeader("Content-Type", "application/json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if ct := got.Header.Get("Content-Type"); ct != "" || body != "" || got.ContentLength != 0 {
+			t.Errorf("%s sent Content-Type %q and body %q, want neither", method, ct, body)
+		}
+	}
+
+	req, _ := http.NewRequest("POST", "/notes", strings.NewReader(`{"text":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.do(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if ct := got.Header.Get("Content-Type"); ct != "application/json" || body != `{"text":"hi"}` {
+		t.Errorf("POST sent Content-Type %q and body %q", ct, body)
+	}
+
+	for _, method := range []string{"GET", "HEAD"} {
+		req, _ := http.NewRequest(method, "/notes", strings.NewReader("stray"))
+		want := fmt.Sprintf("client: %s requests can't have a body", method)
+		if _, err := c.do(context.Background(), req); err == nil || err.Error() != want {
+			t.Errorf("got error %v, want %q", err, want)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("made %d requests, want none with a GET or HEAD body", requests)
+	}
+}
+
+func TestArrayStyles(t *testing.T) {
+	for _, tc := range []struct {
+		style  ArrayStyle
+		values []string
+		want   string
+	}{
+		{ArrayRepeat, []string{"a", "b c"}, "tag=a&tag=b+c"},
+		{ArrayComma, []string{"a", "b c"}, "tag=a%2Cb+c"},
+		{ArrayRepeat, nil, ""},
+		{ArrayComma, []string{}, ""},
+	} {
+		c := NewClient("https://api.example.com", WithArrayStyle(tc.style))
+		v := url.Values{}
+		c.addArrayParam(v, "tag", tc.values)
+		if got := v.Encode(); got != tc.want {
+			t.Errorf("style %d with %q: got %q, want %q", tc.style, tc.values, got, tc.want)
+		}
+	}
+}
+
+func TestConcurrentFilters(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {})
+	c := NewClient(srv.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.AddFilter(&testFilter{})
+		}()
+		go func() {
+			defer wg.Done()
+			res, err := get(t, c, "/")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if n := len(c.snapshotFilters()); n != 10 {
+		t.Errorf("got %d filters, want 10", n)
+	}
+}
+
+func TestRemoveFilter(t *testing.T) {
+	c := NewClient("https://api.example.com")
+	a, b, d := &testFilter{}, &testFilter{}, &testFilter{}
+	c.AddFilter(a)
+	c.AddFilter(b)
+	c.AddFilter(d)
+
+	if !c.RemoveFilter(b) {
+		t.Error("RemoveFilter didn't find the filter")
+	}
+	if filters := c.snapshotFilters(); len(filters) != 2 || filters[0] != a || filters[1] != d {
+		t.Errorf("got filters %v, want the first and last in order", filters)
+	}
+	if c.RemoveFilter(b) {
+		t.Error("RemoveFilter removed a filter twice")
+	}
+
+	detach := c.AttachFilter(b)
+	detach()
+	if n := len(c.snapshotFilters()); n != 2 {
+		t.Errorf("got %d filters after detaching one, want 2", n)
+	}
+}
+
+func TestRemoveFilterConcurrently(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {})
+	c := NewClient(srv.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		f := &testFilter{}
+		c.AddFilter(f)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.RemoveFilter(f)
+		}()
+		go func() {
+			defer wg.Done()
+			res, err := get(t, c, "/")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if n := len(c.snapshotFilters()); n != 0 {
+		t.Errorf("got %d filters, want none", n)
+	}
+}
+
+func TestFilterOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) *testFilter {
+		return &testFilter{
+			before:     func(req *http.Request) error { calls = append(calls, "before "+name); return nil },
+			after:      func(res *http.Response) { calls = append(calls, "after "+name) },
+			afterError: func(req *http.Request, err error) { calls = append(calls, "afterError "+name) },
+		}
+	}
+
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {})
+	c := NewClient(srv.URL, WithFilters(record("a"), record("b"), record("c")))
+	res, err := get(t, c, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	c = NewClient("https://api.example.com", WithTransport(failingTransport{errors.New("refused")}),
+		WithFilters(record("a"), record("b")))
+	get(t, c, "/")
+
+	want := []string{
+		"before a", "before b", "before c", "after c", "after b", "after a",
+		"before a", "before b", "afterError b", "afterError a",
+	}
+	if strings.Join(calls, ", ") != strings.Join(want, ", ") {
+		t.Errorf("filters were called as\n\t%s\nwant\n\t%s", strings.Join(calls, ", "), strings.Join(want, ", "))
+	}
+}
+
+func TestCookies(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/set":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t"})
+		case "/check":
+			if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "s3cr3t" {
+				t.Errorf("got session cookie %v, want the one set", cookie)
+			}
+			if cookie, err := r.Cookie("seeded"); err != nil || cookie.Value != "yes" {
+				t.Errorf("got seeded cookie %v, want the one in the jar", cookie)
+			}
+		}
+	})
+
+	c := NewClient(srv.URL)
+	if c.CookieJar() != nil {
+		t.Error("a new Client has a cookie jar")
+	}
+	c.EnableCookiesWithOptions(&cookiejar.Options{PublicSuffixList: testSuffixList{}})
+	u, _ := url.Parse(srv.URL)
+	c.CookieJar().SetCookies(u, []*http.Cookie{{Name: "seeded", Value: "yes"}})
+
+	for _, path := range []string{"/set", "/check"} {
+		res, err := get(t, c, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+}
+
+// testSuffixList is a cookiejar.PublicSuffixList treating the last label
+// of the domain as the public suffix.
+type testSuffixList struct{}
+
+func (testSuffixList) PublicSuffix(domain string) string {
+	return domain[strings.LastIndex(domain, ".")+1:]
+}
+
+func (testSuffixList) String() string { return "test" }
+
+func TestAPIKeyFilter(t *testing.T) {
+	req := sentRequest(t, func(c *Client) { c.UseAPIKey("X-API-Key", "k3y", APIKeyInHeader) })
+	if got := req.Header.Get("X-API-Key"); got != "k3y" {
+		t.Errorf("got X-API-Key %q, want k3y", got)
+	}
+
+	f := NewAPIKeyFilter("api_key", "k 3y", APIKeyInQuery)
+	req, _ = http.NewRequest("GET", "https://api.example.com/items?tag=a,b&api_key=old&page=2", nil)
+	if err := f.Before(req); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := req.URL.RawQuery, "tag=a,b&page=2&api_key=k+3y"; got != want {
+		t.Errorf("got query %q, want %q", got, want)
+	}
+
+	req, _ = http.NewRequest("GET", "https://api.example.com/public", nil)
+	req = req.WithContext(withoutAuth(req.Context()))
+	f.Before(req)
+	if req.URL.RawQuery != "" {
+		t.Errorf("added the key to an unsecured request, as %q", req.URL.RawQuery)
+	}
+}
+
+func TestLoginWithPassword(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/login" {
+			if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "alice" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+			return
+		}
+
+		var username, password string
+		if r.Header.Get("Content-Type") == "application/json" {
+			var fields map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+				t.Error(err)
+			}
+			username, password = fields["user"], fields["pass"]
+		} else {
+			username, password = r.PostFormValue("user"), r.PostFormValue("pass")
+		}
+		if password != "hunter2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: username})
+	})
+
+	for _, asForm := range []bool{false, true} {
+		c := NewClient(srv.URL)
+		err := c.loginWithPassword(context.Background(), srv.URL+"/login", asForm,
+			map[string]string{"user": "alice", "pass": "wrong"})
+		if err == nil {
+			t.Errorf("form %v: login with a bad password succeeded", asForm)
+		}
+
+		err = c.loginWithPassword(context.Background(), srv.URL+"/login", asForm,
+			map[string]string{"user": "alice", "pass": "hunter2"})
+		if err != nil {
+			t.Fatalf("form %v: %v", asForm, err)
+		}
+		res, err := get(t, c, "/me")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("form %v: got status %d after logging in, want 200", asForm, res.StatusCode)
+		}
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	type note struct {
+		Title string `json:"title" xml:"title"`
+	}
+
+	for _, tc := range []struct {
+		contentType, body string
+	}{
+		{"application/json", `{"title":"Hi"}`},
+		{"application/vnd.api+json; charset=utf-8", `{"title":"Hi"}`},
+		{"application/xml", `<note><title>Hi</title></note>`},
+		{"application/atom+xml", `<note><title>Hi</title></note>`},
+		{"application/x-www-form-urlencoded", `title=Hi`},
+		{"text/plain", ` <note><title>Hi</title></note>`},
+		{"", `{"title":"Hi"}`},
+	} {
+		res := testResponse(http.StatusOK, tc.contentType, tc.body)
+		var n note
+		if err := decodeResponse(res, &n); err != nil {
+			t.Errorf("%q: %v", tc.contentType, err)
+			continue
+		}
+		if n.Title != "Hi" {
+			t.Errorf("%q: got %+v, want the title Hi", tc.contentType, n)
+		}
+		if body := readAll(t, res); body != tc.body {
+			t.Errorf("%q: left body %q, want it readable", tc.contentType, body)
+		}
+	}
+}
+
+func TestWithUseNumber(t *testing.T) {
+	const body = `{"id": 9007199254740993, "ratio": 0.5}`
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body)
+	})
+
+	for _, tc := range []struct {
+		opts []Option
+		want map[string]interface{}
+	}{
+		{nil, map[string]interface{}{"id": float64(9007199254740992), "ratio": 0.5}},
+		{[]Option{WithUseNumber()}, map[string]interface{}{"id": json.Number("9007199254740993"), "ratio": json.Number("0.5")}},
+	} {
+		res, err := get(t, NewClient(srv.URL, tc.opts...), "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]interface{}
+		if err := decodeResponse(res, &got); err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("with %d options, decoded %#v, want %#v", len(tc.opts), got, tc.want)
+		}
+	}
+
+	// Trailing data is rejected as by json.Unmarshal.
+	res := testResponse(200, "application/json", `{"id": 1} {}`)
+	res.Request = (&http.Request{}).WithContext(context.WithValue(context.Background(), useNumberKey{}, true))
+	var got map[string]interface{}
+	if err := decodeResponse(res, &got); err == nil {
+		t.Error("decoded a body with trailing data")
+	}
+}
+
+func TestDecodeResponseEmptyBody(t *testing.T) {
+	for _, body := range []string{"", "\n"} {
+		n := map[string]string{"title": "Hi"}
+		if err := decodeResponse(testResponse(http.StatusNoContent, "application/json", body), &n); err != nil {
+			t.Errorf("decoding %q: %v", body, err)
+		}
+		if n["title"] != "Hi" {
+			t.Errorf("decoding %q changed the value to %v", body, n)
+		}
+	}
+}
+
+func TestDecodeResponseErrors(t *testing.T) {
+	body := `{"title": ` + strings.Repeat("x", 300)
+	err := decodeResponse(testResponse(http.StatusOK, "application/json", body), new(map[string]string))
+
+	var derr *DecodeError
+	if !errors.As(err, &derr) {
+		t.Fatalf("got error %v, want a DecodeError", err)
+	}
+	if derr.StatusCode != http.StatusOK || derr.ContentType != "application/json" {
+		t.Errorf("got status %d and type %q, want the response's", derr.StatusCode, derr.ContentType)
+	}
+	if want := body[:maxSnippet] + "..."; derr.Snippet != want {
+		t.Errorf("got snippet %q, want the first %d bytes", derr.Snippet, maxSnippet)
+	}
+	var serr *json.SyntaxError
+	if !errors.As(err, &serr) {
+		t.Errorf("got error %v, want it to wrap the decoder's", err)
+	}
+	if !strings.Contains(err.Error(), "200 response") {
+		t.Errorf("got message %q, want it to name the status", err)
+	}
+}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// CacheFilter caches the bodies of GET responses which carry an `ETag`,
+// revalidating them with `If-None-Match` on later requests for the same
+// URL. When the server answers 304 Not Modified, the response is turned
+// into a 200 OK carrying the cached body and headers, so callers decode it
+// as if the server had sent it again. Requests which already set
+// `If-None-Match` are left to the caller.
+//
+// Entries are keyed by URL alone, so a client whose requests for the same
+// URL are answered differently, such as for different users, shouldn't
+// share a cache between them. The bodies held are bounded in total size,
+// evicting the least recently used first. It is safe for concurrent use.
+type CacheFilter struct {
+	maxBytes int64
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+var _ Filter = new(CacheFilter)
+
+// cacheEntry is a cached response, which is the value of an element in the
+// filter's LRU list.
+type cacheEntry struct {
+	url    string
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// NewCacheFilter creates a CacheFilter holding up to maxBytes of response
+// bodies. Bodies larger than that aren't cached.
+func NewCacheFilter(maxBytes int64) *CacheFilter {
+	return &CacheFilter{
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+func (f *CacheFilter) Before(req *http.Request) error {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return nil
+	}
+
+	if entry := f.get(req.URL.String()); entry != nil {
+		req.Header.Set("If-None-Match", entry.etag)
+		exchangeFrom(req.Context()).etag = entry.etag
+	}
+
+	return nil
+}
+
+func (f *CacheFilter) After(res *http.Response) {
+	req := res.Request
+	if req == nil || req.Method != http.MethodGet {
+		return
+	}
+	key := req.URL.String()
+
+	switch {
+	case res.StatusCode == http.StatusNotModified:
+		// Only the filter's own revalidations are answered from the cache.
+		etag := exchangeFrom(req.Context()).etag
+		entry := f.get(key)
+		if etag == "" || entry == nil || entry.etag != etag {
+			return
+		}
+
+		for name, values := range entry.header {
+			if _, ok := res.Header[name]; !ok {
+				res.Header[name] = values
+			}
+		}
+		res.Body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(entry.body))
+		res.ContentLength = int64(len(entry.body))
+		res.StatusCode = http.StatusOK
+		res.Status = "200 OK"
+
+	case res.StatusCode == http.StatusOK && res.Header.Get("ETag") != "" && !IsStreaming(req):
+		body := res.Body
+		data, err := io.ReadAll(io.LimitReader(body, f.maxBytes+1))
+		if err != nil || int64(len(data)) > f.maxBytes {
+			var rest io.Reader = body
+			if err != nil {
+				rest = errReader{err}
+			}
+			res.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(data), rest), body}
+			f.remove(key)
+			return
+		}
+		body.Close()
+
+		res.Body = io.NopCloser(bytes.NewReader(data))
+		f.put(&cacheEntry{url: key, etag: res.Header.Get("ETag"), header: res.Header.Clone(), body: data})
+
+	case res.StatusCode == http.StatusOK:
+		f.remove(key)
+	}
+}
+
+func (f *CacheFilter) AfterError(req *http.Request, err error) {}
+
+// get returns the entry for the URL, marking it as recently used, or nil if
+// there is none.
+func (f *CacheFilter) get(key string) *cacheEntry {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	el, ok := f.entries[key]
+	if !ok {
+		return nil
+	}
+	f.lru.MoveToFront(el)
+
+	return el.Value.(*cacheEntry)
+}
+
+// put stores the entry, replacing any for the same URL, then evicts the
+// least recently used entries until the cache fits within its bound.
+func (f *CacheFilter) put(entry *cacheEntry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.removeLocked(entry.url)
+	f.entries[entry.url] = f.lru.PushFront(entry)
+	f.size += int64(len(entry.body))
+
+	for f.size > f.maxBytes {
+		f.removeLocked(f.lru.Back().Value.(*cacheEntry).url)
+	}
+}
+
+// remove drops the entry for the URL, if there is one.
+func (f *CacheFilter) remove(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.removeLocked(key)
+}
+
+func (f *CacheFilter) removeLocked(key string) {
+	if el, ok := f.entries[key]; ok {
+		f.lru.Remove(el)
+		delete(f.entries, key)
+		f.size -= int64(len(el.Value.(*cacheEntry).body))
+	}
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// etagServer serves the bodies, tagged with ETags derived from them,
+// answering 304 when the request's If-None-Match matches. It records the
+// If-None-Match header of each request.
+func etagServer(t *testing.T, bodies map[string]string) (*Client, func() []string) {
+	var mu sync.Mutex
+	var sent []string
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		body := bodies[r.URL.Path]
+		sent = append(sent, r.Header.Get("If-None-Match"))
+		mu.Unlock()
+
+		etag := fmt.Sprintf(`"%x"`, body)
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, body)
+	})
+
+	return NewClient(srv.URL), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), sent...)
+	}
+}
+
+func TestCacheServesNotModified(t *testing.T) {
+	c, sent := etagServer(t, map[string]string{"/books": "cached"})
+	c.UseCache(1024)
+
+	for i := 0; i < 2; i++ {
+		res, err := get(t, c, "/books")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body := readAll(t, res); res.StatusCode != http.StatusOK || body != "cached" {
+			t.Errorf("request %d got %d %q, want 200 cached", i+1, res.StatusCode, body)
+		}
+		if ct := res.Header.Get("Content-Type"); ct != "text/plain" {
+			t.Errorf("request %d got Content-Type %q, want the cached response's", i+1, ct)
+		}
+	}
+	if got := sent(); got[0] != "" || got[1] != fmt.Sprintf(`"%x"`, "cached") {
+		t.Errorf("sent If-None-Match %q, want none and then the ETag", got)
+	}
+}
+
+func TestCacheReplacesChangedBodies(t *testing.T) {
+	bodies := map[string]string{"/books": "old"}
+	c, _ := etagServer(t, bodies)
+	c.UseCache(1024)
+
+	for _, want := range []string{"old", "old", "new", "new"} {
+		if want == "new" {
+			bodies["/books"] = "new"
+		}
+		res, err := get(t, c, "/books")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body := readAll(t, res); body != want {
+			t.Errorf("got body %q, want %q", body, want)
+		}
+	}
+}
+
+func TestCacheIsBounded(t *testing.T) {
+	bodies := map[string]string{"/a": "aaaaaa", "/b": "bbbbbb", "/large": strings.Repeat("x", 20)}
+	c, sent := etagServer(t, bodies)
+	c.UseCache(10)
+
+	// Caching /b evicts /a, the least recently used, and /large is never
+	// cached, though it's still read in full.
+	for _, path := range []string{"/a", "/b", "/a", "/large", "/large"} {
+		res, err := get(t, c, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body := readAll(t, res); body != bodies[path] {
+			t.Errorf("%s: got body %q, want %q", path, body, bodies[path])
+		}
+	}
+	for i, inm := range sent() {
+		if inm != "" {
+			t.Errorf("request %d revalidated with %s, want every one to miss", i+1, inm)
+		}
+	}
+}
+
+func TestCacheLeavesOtherRequestsAlone(t *testing.T) {
+	c, sent := etagServer(t, map[string]string{"/books": "cached"})
+	c.UseCache(1024)
+	etag := fmt.Sprintf(`"%x"`, "cached")
+
+	res, err := get(t, c, "/books")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	// The caller's own revalidation gets the server's 304.
+	res, err = get(t, c, "/books", WithHeader("If-None-Match", etag))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotModified {
+		t.Errorf("got status %d, want the server's 304", res.StatusCode)
+	}
+
+	res, err = c.do(context.Background(), mustRequest(t, "HEAD", "/books"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if got := sent(); got[2] != "" {
+		t.Errorf("a HEAD request was sent If-None-Match %s", got[2])
+	}
+}
+
+func TestCacheConcurrently(t *testing.T) {
+	bodies := map[string]string{}
+	for i := 0; i < 8; i++ {
+		bodies[fmt.Sprintf("/%d", i)] = strings.Repeat(fmt.Sprint(i), 10)
+	}
+	c, _ := etagServer(t, bodies)
+	c.UseCache(40)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			res, err := get(t, c, path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if body := readAll(t, res); body != bodies[path] {
+				t.Errorf("%s: got body %q, want %q", path, body, bodies[path])
+			}
+		}(fmt.Sprintf("/%d", i%8))
+	}
+	wg.Wait()
+}
+
+func mustRequest(t *testing.T, method, path string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return req
+}
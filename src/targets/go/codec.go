@@ -0,0 +1,66 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Codec marshals request bodies to JSON and unmarshals JSON responses,
+// letting a Client use a faster JSON library in place of encoding/json.
+// Implementations must honour the `json` struct tags and the Marshaler and
+// Unmarshaler interfaces of encoding/json, as types the API declares rely
+// on them. It must be safe for concurrent use.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Decoder reads JSON values from a stream, as a *json.Decoder does.
+type Decoder interface {
+	Decode(v interface{}) error
+	UseNumber()
+}
+
+// JSONCodec is the Codec used unless a Client is created WithCodec,
+// backed by encoding/json.
+var JSONCodec Codec = stdCodec{}
+
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (stdCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+func (stdCodec) NewDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+
+// WithCodec makes the Client marshal and unmarshal JSON bodies with codec.
+// Bodies of other types, and the JSON the API's types marshal themselves,
+// such as union types and dates, are unaffected.
+func WithCodec(codec Codec) Option {
+	return func(c *Client) { c.codec = codec }
+}
+
+type codecKey struct{}
+
+// jsonCodec returns the Client's Codec.
+func (c *Client) jsonCodec() Codec {
+	if c.codec == nil {
+		return JSONCodec
+	}
+
+	return c.codec
+}
+
+// codecOf returns the Codec of the Client which sent the response's
+// request.
+func codecOf(res *http.Response) Codec {
+	if res.Request != nil {
+		if codec, ok := res.Request.Context().Value(codecKey{}).(Codec); ok {
+			return codec
+		}
+	}
+
+	return JSONCodec
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// recordingCodec is a Codec counting its calls, delegating to JSONCodec.
+type recordingCodec struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (c *recordingCodec) record(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.calls == nil {
+		c.calls = map[string]int{}
+	}
+	c.calls[name]++
+}
+
+func (c *recordingCodec) count(name string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[name]
+}
+
+func (c *recordingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.record("Marshal")
+	return JSONCodec.Marshal(v)
+}
+
+func (c *recordingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.record("Unmarshal")
+	return JSONCodec.Unmarshal(data, v)
+}
+
+func (c *recordingCodec) NewDecoder(r io.Reader) Decoder {
+	c.record("NewDecoder")
+	return JSONCodec.NewDecoder(r)
+}
+
+func TestWithCodec(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"title": "Hi"}`)
+	})
+
+	codec := &recordingCodec{}
+	for _, tc := range []struct {
+		opts []Option
+		want string
+	}{
+		{[]Option{WithCodec(codec)}, "Unmarshal"},
+		{[]Option{WithCodec(codec), WithUseNumber()}, "NewDecoder"},
+	} {
+		c := NewClient(srv.URL, tc.opts...)
+		if c.jsonCodec() != codec {
+			t.Fatalf("got codec %v, want the one given", c.jsonCodec())
+		}
+
+		res, err := get(t, c, "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got struct{ Title string }
+		if err := decodeResponse(res, &got); err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if got.Title != "Hi" {
+			t.Errorf("decoded %+v, want the title", got)
+		}
+		if n := codec.count(tc.want); n != 1 {
+			t.Errorf("called %s %d times, want once", tc.want, n)
+		}
+	}
+
+	// Responses to requests of other Clients use the default.
+	if got := codecOf(testResponse(200, "application/json", "{}")); got != JSONCodec {
+		t.Errorf("got codec %v, want JSONCodec", got)
+	}
+	if got := NewClient(srv.URL).jsonCodec(); got != JSONCodec {
+		t.Errorf("a Client created without WithCodec uses %v", got)
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DecompressFilter requests compressed responses by setting the
+// `Accept-Encoding` header, unless the request already has one, and
+// decodes gzip and deflate encoded response bodies. Decoded responses
+// have their `Content-Encoding` and `Content-Length` headers removed.
+//
+// Go's transport only decompresses responses by itself when it added the
+// `Accept-Encoding` header, so this filter is also useful when another
+// filter or the caller sets that header.
+type DecompressFilter struct{}
+
+var _ Filter = new(DecompressFilter)
+
+func (d *DecompressFilter) Before(req *http.Request) error {
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+	}
+
+	return nil
+}
+
+func (d *DecompressFilter) After(res *http.Response) {
+	var open func(io.Reader) (io.Reader, error)
+	switch strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		open = func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }
+	case "deflate":
+		open = func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) }
+	default:
+		return
+	}
+
+	res.Body = &decompressReader{body: res.Body, open: open}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+}
+
+func (d *DecompressFilter) AfterError(req *http.Request, err error) {}
+
+// decompressReader decodes the body as it's read. The decoder is created on
+// the first read, so that errors reading the compression header are
+// returned from Read rather than lost.
+type decompressReader struct {
+	body io.ReadCloser
+	open func(io.Reader) (io.Reader, error)
+	r    io.Reader
+	err  error
+}
+
+func (d *decompressReader) Read(p []byte) (int, error) {
+	if d.r == nil && d.err == nil {
+		d.r, d.err = d.open(d.body)
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	return d.r.Read(p)
+}
+
+func (d *decompressReader) Close() error { return d.body.Close() }
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestDecompressFilter(t *testing.T) {
+	const text = "decompressed response body"
+	encoders := map[string]func(io.Writer) io.WriteCloser{
+		"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+	}
+
+	for encoding, encoder := range encoders {
+		var buf bytes.Buffer
+		w := encoder(&buf)
+		io.WriteString(w, text)
+		w.Close()
+
+		srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Accept-Encoding"); got != "gzip, deflate" {
+				t.Errorf("got Accept-Encoding %q, want gzip, deflate", got)
+			}
+			w.Header().Set("Content-Encoding", encoding)
+			w.Write(buf.Bytes())
+		})
+
+		c := NewClient(srv.URL)
+		c.EnableCompression()
+		res, err := get(t, c, "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body := readAll(t, res); body != text {
+			t.Errorf("%s: read %q, want %q", encoding, body, text)
+		}
+		if res.Header.Get("Content-Encoding") != "" || res.ContentLength != -1 || !res.Uncompressed {
+			t.Errorf("%s: response still describes the encoded body", encoding)
+		}
+	}
+}
+
+func TestDecompressFilterKeepsAcceptEncoding(t *testing.T) {
+	req := sentRequest(t, func(c *Client) { c.EnableCompression() }, WithHeader("Accept-Encoding", "gzip"))
+	if got := req.Header.Get("Accept-Encoding"); got != "gzip" {
+		t.Errorf("got Accept-Encoding %q, want the request's own", got)
+	}
+}
+
+func TestDecompressFilterBadBody(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		io.WriteString(w, "not gzip")
+	})
+
+	c := NewClient(srv.URL)
+	c.EnableCompression()
+	res, err := get(t, c, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if _, err := io.ReadAll(res.Body); err == nil {
+		t.Error("read a corrupt gzip body without an error")
+	}
+}
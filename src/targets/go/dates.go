@@ -0,0 +1,137 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Layouts of the RAML date and time types which aren't RFC 3339 datetimes.
+const (
+	dateOnlyLayout     = "2006-01-02"
+	timeOnlyLayout     = "15:04:05"
+	dateTimeOnlyLayout = "2006-01-02T15:04:05"
+)
+
+// DateOnly is a RAML date-only value, like "2015-05-23".
+type DateOnly struct{ time.Time }
+
+// TimeOnly is a RAML time-only value, like "12:30:00".
+type TimeOnly struct{ time.Time }
+
+// DateTimeOnly is a RAML datetime-only value, which has no time zone, like
+// "2015-07-04T21:00:00".
+type DateTimeOnly struct{ time.Time }
+
+// HTTPTime is a RAML datetime value with the rfc2616 format, like
+// "Sun, 28 Feb 2016 16:41:41 GMT".
+type HTTPTime struct{ time.Time }
+
+// The types embed time.Time, so they define their own marshalling methods
+// to replace the RFC 3339 ones it would otherwise promote.
+
+func (d DateOnly) String() string {
+	return d.Format(dateOnlyLayout)
+}
+
+func (d DateOnly) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *DateOnly) UnmarshalText(data []byte) error {
+	return parseLayout(&d.Time, dateOnlyLayout, data)
+}
+
+func (d DateOnly) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *DateOnly) UnmarshalJSON(data []byte) error {
+	return unmarshalLayout(&d.Time, dateOnlyLayout, data)
+}
+
+func (t TimeOnly) String() string {
+	return t.Format(timeOnlyLayout)
+}
+
+func (t TimeOnly) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *TimeOnly) UnmarshalText(data []byte) error {
+	return parseLayout(&t.Time, timeOnlyLayout, data)
+}
+
+func (t TimeOnly) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+func (t *TimeOnly) UnmarshalJSON(data []byte) error {
+	return unmarshalLayout(&t.Time, timeOnlyLayout, data)
+}
+
+func (d DateTimeOnly) String() string {
+	return d.Format(dateTimeOnlyLayout)
+}
+
+func (d DateTimeOnly) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *DateTimeOnly) UnmarshalText(data []byte) error {
+	return parseLayout(&d.Time, dateTimeOnlyLayout, data)
+}
+
+func (d DateTimeOnly) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *DateTimeOnly) UnmarshalJSON(data []byte) error {
+	return unmarshalLayout(&d.Time, dateTimeOnlyLayout, data)
+}
+
+func (h HTTPTime) String() string {
+	return h.UTC().Format(http.TimeFormat)
+}
+
+func (h HTTPTime) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *HTTPTime) UnmarshalText(data []byte) error {
+	return parseLayout(&h.Time, http.TimeFormat, data)
+}
+
+func (h HTTPTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.String())
+}
+
+func (h *HTTPTime) UnmarshalJSON(data []byte) error {
+	return unmarshalLayout(&h.Time, http.TimeFormat, data)
+}
+
+// parseLayout parses the text into t following the layout.
+func parseLayout(t *time.Time, layout string, data []byte) error {
+	parsed, err := time.Parse(layout, string(data))
+	if err != nil {
+		return err
+	}
+
+	*t = parsed
+	return nil
+}
+
+// unmarshalLayout parses the JSON string into t following the layout.
+// JSON nulls leave t untouched.
+func unmarshalLayout(t *time.Time, layout string, data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	return parseLayout(t, layout, []byte(s))
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDatesMarshalJSON(t *testing.T) {
+	at := time.Date(2015, 7, 4, 21, 0, 0, 0, time.FixedZone("EST", -5*60*60))
+
+	for _, tc := range []struct {
+		v    interface{}
+		want string
+	}{
+		{DateOnly{at}, `"2015-07-04"`},
+		{TimeOnly{at}, `"21:00:00"`},
+		{DateTimeOnly{at}, `"2015-07-04T21:00:00"`},
+		{HTTPTime{at}, `"Sun, 05 Jul 2015 02:00:00 GMT"`},
+	} {
+		data, err := json.Marshal(tc.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tc.want {
+			t.Errorf("%T marshals to %s, want %s", tc.v, data, tc.want)
+		}
+	}
+}
+
+func TestDatesRoundTrip(t *testing.T) {
+	type dates struct {
+		Date     DateOnly     `json:"date"`
+		Time     TimeOnly     `json:"time"`
+		DateTime DateTimeOnly `json:"dateTime"`
+		HTTP     HTTPTime     `json:"http"`
+		RFC3339  time.Time    `json:"rfc3339"`
+	}
+
+	in := `{"date":"2015-05-23","time":"12:30:00","dateTime":"2015-07-04T21:00:00",` +
+		`"http":"Sun, 28 Feb 2016 16:41:41 GMT","rfc3339":"2016-02-28T16:41:41.09Z"}`
+	var d dates
+	if err := json.Unmarshal([]byte(in), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Date.Year() != 2015 || d.Date.Month() != time.May || d.Date.Day() != 23 {
+		t.Errorf("got date %v, want 2015-05-23", d.Date.Time)
+	}
+
+	out, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != in {
+		t.Errorf("got %s, want %s", out, in)
+	}
+}
+
+func TestDatesUnmarshalErrors(t *testing.T) {
+	for _, in := range []string{`"2015-05-23T12:30:00"`, `"23/05/2015"`, `20150523`} {
+		var d DateOnly
+		if err := json.Unmarshal([]byte(in), &d); err == nil {
+			t.Errorf("unmarshalling %s as a date-only gave no error", in)
+		}
+	}
+
+	d := DateOnly{time.Date(2015, 5, 23, 0, 0, 0, 0, time.UTC)}
+	if err := json.Unmarshal([]byte("null"), &d); err != nil || d.String() != "2015-05-23" {
+		t.Errorf("unmarshalling null gave (%v, %v), want the date untouched", d, err)
+	}
+}
+
+func TestDatesFormatParam(t *testing.T) {
+	at := time.Date(2016, 2, 28, 16, 41, 41, 0, time.UTC)
+
+	for _, tc := range []struct {
+		v    interface{}
+		want string
+	}{
+		{at, "2016-02-28T16:41:41Z"},
+		{DateOnly{at}, "2016-02-28"},
+		{TimeOnly{at}, "16:41:41"},
+		{DateTimeOnly{at}, "2016-02-28T16:41:41"},
+		{HTTPTime{at}, "Sun, 28 Feb 2016 16:41:41 GMT"},
+	} {
+		if got := formatParam(tc.v); got != tc.want {
+			t.Errorf("formatParam(%T) = %q, want %q", tc.v, got, tc.want)
+		}
+	}
+}
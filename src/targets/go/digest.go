@@ -0,0 +1,213 @@
+package client
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DigestFilter authenticates requests with HTTP Digest authentication
+// (RFC 7616), supporting the MD5 and MD5-sess algorithms with qop=auth.
+//
+// Until the server has issued a challenge, requests are sent without
+// credentials. When one is rejected with a 401 carrying a Digest challenge,
+// the filter records the challenge and the request is retried with an
+// `Authorization` header. Later requests reuse the challenge, so only the
+// first needs the extra round trip. It is safe for concurrent use.
+type DigestFilter struct {
+	username, password string
+
+	mu        sync.Mutex
+	challenge *digestChallenge
+	nc        int
+}
+
+var _ bodyBufferer = new(DigestFilter)
+
+// NewDigestFilter creates a DigestFilter using the credentials.
+func NewDigestFilter(username, password string) *DigestFilter {
+	return &DigestFilter{username: username, password: password}
+}
+
+// digestChallenge holds the parameters of a `WWW-Authenticate` challenge.
+type digestChallenge struct {
+	realm, nonce, opaque, algorithm, qop string
+	stale                                bool
+}
+
+func (d *DigestFilter) Before(req *http.Request) error {
+	d.mu.Lock()
+	ch := d.challenge
+	d.nc++
+	nc := d.nc
+	d.mu.Unlock()
+
+	if ch == nil || !RequiresAuth(req) {
+		return nil
+	}
+
+	cnonce := digestCnonce()
+	ha1 := md5Hex(d.username + ":" + ch.realm + ":" + d.password)
+	if strings.EqualFold(ch.algorithm, "MD5-sess") {
+		ha1 = md5Hex(ha1 + ":" + ch.nonce + ":" + cnonce)
+	}
+	uri := req.URL.RequestURI()
+	ha2 := md5Hex(req.Method + ":" + uri)
+
+	fields := []string{
+		fmt.Sprintf(`username="%s"`, d.username),
+		fmt.Sprintf(`realm="%s"`, ch.realm),
+		fmt.Sprintf(`nonce="%s"`, ch.nonce),
+		fmt.Sprintf(`uri="%s"`, uri),
+	}
+	if ch.algorithm != "" {
+		fields = append(fields, "algorithm="+ch.algorithm)
+	}
+
+	if ch.qop == "auth" {
+		ncs := fmt.Sprintf("%08x", nc)
+		response := md5Hex(strings.Join([]string{ha1, ch.nonce, ncs, cnonce, "auth", ha2}, ":"))
+		fields = append(fields,
+			"qop=auth",
+			"nc="+ncs,
+			fmt.Sprintf(`cnonce="%s"`, cnonce),
+			fmt.Sprintf(`response="%s"`, response),
+		)
+	} else {
+		fields = append(fields, fmt.Sprintf(`response="%s"`, md5Hex(ha1+":"+ch.nonce+":"+ha2)))
+	}
+
+	if ch.opaque != "" {
+		fields = append(fields, fmt.Sprintf(`opaque="%s"`, ch.opaque))
+	}
+
+	req.Header.Set("Authorization", "Digest "+strings.Join(fields, ", "))
+	return nil
+}
+
+// After records the Digest challenge from a 401 response.
+func (d *DigestFilter) After(res *http.Response) {
+	if res.StatusCode != http.StatusUnauthorized {
+		return
+	}
+
+	for _, header := range res.Header.Values("WWW-Authenticate") {
+		if ch := parseDigestChallenge(header); ch != nil {
+			d.mu.Lock()
+			d.challenge, d.nc = ch, 0
+			d.mu.Unlock()
+			return
+		}
+	}
+}
+
+func (d *DigestFilter) AfterError(req *http.Request, err error) {}
+
+func (d *DigestFilter) buffersBodies() {}
+
+// Retry resends a request which was rejected with a Digest challenge. It
+// retries once, or a second time if the server says the nonce was stale.
+func (d *DigestFilter) Retry(req *http.Request, res *http.Response, err error, attempt int) (time.Duration, bool) {
+	if res == nil || res.StatusCode != http.StatusUnauthorized || attempt > 2 || !RequiresAuth(req) {
+		return 0, false
+	}
+
+	d.mu.Lock()
+	ch := d.challenge
+	d.mu.Unlock()
+
+	return 0, ch != nil && (attempt == 1 || ch.stale)
+}
+
+// parseDigestChallenge parses a `WWW-Authenticate` header value, returning
+// nil if it isn't a Digest challenge we support.
+func parseDigestChallenge(header string) *digestChallenge {
+	const prefix = "digest "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return nil
+	}
+
+	params := parseAuthParams(header[len(prefix):])
+	ch := &digestChallenge{
+		realm:     params["realm"],
+		nonce:     params["nonce"],
+		opaque:    params["opaque"],
+		algorithm: params["algorithm"],
+		stale:     strings.EqualFold(params["stale"], "true"),
+	}
+
+	switch strings.ToUpper(ch.algorithm) {
+	case "", "MD5", "MD5-SESS":
+	default:
+		return nil
+	}
+
+	if qop, ok := params["qop"]; ok {
+		for _, option := range strings.Split(qop, ",") {
+			if strings.TrimSpace(option) == "auth" {
+				ch.qop = "auth"
+			}
+		}
+		if ch.qop == "" {
+			return nil
+		}
+	}
+
+	return ch
+}
+
+// parseAuthParams parses a comma-separated list of key=value pairs, where
+// values may be quoted strings containing commas and escaped characters.
+func parseAuthParams(s string) map[string]string {
+	params := map[string]string{}
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return params
+		}
+
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " \t")
+
+		var value strings.Builder
+		if strings.HasPrefix(s, `"`) {
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value.WriteByte(s[i])
+			}
+			if i < len(s) {
+				i++
+			}
+			s = s[i:]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value.WriteString(strings.TrimSpace(s[:end]))
+			s = s[end:]
+		}
+
+		params[key] = value.String()
+	}
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func digestCnonce() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// digestServer starts a server, returning its URL, which answers requests
+// with a Digest challenge unless they carry a valid response to it, as
+// computed following RFC 7616 for the algorithm.
+func digestServer(t *testing.T, algorithm string, challenges *int32) string {
+	const (
+		realm    = "testrealm@host.com"
+		nonce    = "dcd98b7102dd2f0e8b11d0f600bfb0c093"
+		opaque   = "5ccc069c403ebaf9f0171e9517f40e41"
+		username = "Mufasa"
+		password = "Circle Of Life"
+	)
+
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method == "POST" && string(body) != "payload" {
+			t.Errorf("got body %q, want payload", body)
+		}
+
+		auth := r.Header.Get("Authorization")
+		if strings.HasPrefix(auth, "Digest ") {
+			p := parseAuthParams(auth[len("Digest "):])
+			ha1 := md5Hex(username + ":" + realm + ":" + password)
+			if algorithm == "MD5-sess" {
+				ha1 = md5Hex(ha1 + ":" + nonce + ":" + p["cnonce"])
+			}
+			ha2 := md5Hex(r.Method + ":" + r.URL.RequestURI())
+			want := md5Hex(strings.Join([]string{ha1, nonce, p["nc"], p["cnonce"], "auth", ha2}, ":"))
+			if p["username"] == username && p["uri"] == r.URL.RequestURI() && p["opaque"] == opaque &&
+				p["qop"] == "auth" && p["response"] == want {
+				return
+			}
+		}
+
+		atomic.AddInt32(challenges, 1)
+		w.Header().Set("WWW-Authenticate", `Digest realm="`+realm+`", qop="auth,auth-int", algorithm=`+
+			algorithm+`, nonce="`+nonce+`", opaque="`+opaque+`"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	return srv.URL
+}
+
+func TestDigestAuth(t *testing.T) {
+	for _, algorithm := range []string{"MD5", "MD5-sess"} {
+		var challenges int32
+		c := NewClient(digestServer(t, algorithm, &challenges))
+		c.UseDigestAuth("Mufasa", "Circle Of Life")
+
+		for _, method := range []string{"POST", "GET"} {
+			var body io.Reader
+			if method == "POST" {
+				body = io.MultiReader(strings.NewReader("payload"))
+			}
+			req, _ := http.NewRequest(method, "/dir/index.html?q=1", body)
+			res, err := c.do(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != http.StatusOK {
+				t.Errorf("%s %s: got status %d, want 200", algorithm, method, res.StatusCode)
+			}
+		}
+
+		// Only the first request is challenged; the second reuses it.
+		if n := atomic.LoadInt32(&challenges); n != 1 {
+			t.Errorf("%s: the server sent %d challenges, want 1", algorithm, n)
+		}
+	}
+}
+
+func TestDigestBadPassword(t *testing.T) {
+	var challenges int32
+	c := NewClient(digestServer(t, "MD5", &challenges))
+	c.UseDigestAuth("Mufasa", "wrong")
+
+	res, err := get(t, c, "/dir/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got status %d, want 401", res.StatusCode)
+	}
+	if n := atomic.LoadInt32(&challenges); n != 2 {
+		t.Errorf("the server sent %d challenges, want 2 for the request and its one retry", n)
+	}
+}
+
+func TestParseDigestChallenge(t *testing.T) {
+	ch := parseDigestChallenge(`Digest realm="a \"quoted\", realm", nonce="abc", qop="auth-int, auth", stale=TRUE, algorithm=MD5-sess`)
+	if ch == nil {
+		t.Fatal("didn't parse the challenge")
+	}
+	if ch.realm != `a "quoted", realm` || ch.nonce != "abc" || ch.qop != "auth" || !ch.stale || ch.algorithm != "MD5-sess" {
+		t.Errorf("got challenge %+v", ch)
+	}
+
+	for _, header := range []string{
+		`Basic realm="x"`,
+		`Digest realm="x", nonce="y", algorithm=SHA-256`,
+		`Digest realm="x", nonce="y", qop="auth-int"`,
+	} {
+		if ch := parseDigestChallenge(header); ch != nil {
+			t.Errorf("parsed unsupported challenge %q as %+v", header, ch)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ValidationError is returned by calls whose arguments violate constraints
+// declared by the API, such as a pattern or a minimum value, or which leave
+// a required parameter empty. Calls which return it make no request.
+type ValidationError struct {
+	// Param is the name of the offending parameter, as the API declares it.
+	Param string
+	// Reason describes the constraint which was violated.
+	Reason string
+}
+
+// missingReason is the Reason of ValidationErrors for required parameters
+// which are left empty.
+const missingReason = "is required"
+
+// missingParam returns a ValidationError for the required parameter, which
+// was left empty.
+func missingParam(param string) *ValidationError {
+	return &ValidationError{Param: param, Reason: missingReason}
+}
+
+func (e *ValidationError) Error() string {
+	if e.Reason == missingReason {
+		return fmt.Sprintf("client: missing required parameter %q", e.Param)
+	}
+
+	return fmt.Sprintf("client: invalid parameter %q: %s", e.Param, e.Reason)
+}
+
+// APIError is returned by calls whose response has an unsuccessful status.
+// Callers can retrieve it with errors.As to inspect the response.
+type APIError struct {
+	// StatusCode is the status of the response.
+	StatusCode int
+	// Body is the response body, decoded into a pointer to the type which
+	// the API declares for the status. It's nil if the API declares no body
+	// for the status or the body couldn't be decoded, in which case the
+	// body is only available from Raw.
+	Body interface{}
+	// Raw holds the response body as it was received.
+	Raw []byte
+}
+
+func (e *APIError) Error() string {
+	if err, ok := e.Body.(error); ok {
+		return fmt.Sprintf("client: unsuccessful response status %d: %v", e.StatusCode, err)
+	}
+
+	return fmt.Sprintf("client: unsuccessful response status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Unwrap returns the decoded body if it is itself an error, such as the
+// API's shared Error type, so that errors.As finds it.
+func (e *APIError) Unwrap() error {
+	err, _ := e.Body.(error)
+	return err
+}
+
+// newAPIError returns an APIError for the unsuccessful response. Unless
+// body is nil, the response body is decoded into it, and it's kept as the
+// error's Body if that succeeds. The response body is buffered, so it can
+// still be read afterwards.
+func newAPIError(res *http.Response, body interface{}) *APIError {
+	raw, _ := readBody(res)
+	e := &APIError{StatusCode: res.StatusCode, Raw: raw}
+	if body != nil && decodeResponse(res, body) == nil {
+		if s, ok := body.(interface{ setStatus(int) }); ok {
+			s.setStatus(res.StatusCode)
+		}
+		e.Body = body
+	}
+
+	return e
+}
+
+// IsStatusError returns whether err is, or wraps, an APIError for a
+// response with the status code. A code of zero matches any status.
+func IsStatusError(err error, code int) bool {
+	var e *APIError
+	return errors.As(err, &e) && (code == 0 || e.StatusCode == code)
+}
+
+// TransportError is returned by calls whose request couldn't be sent or
+// whose response couldn't be received, such as on DNS or connection
+// failures and timeouts of the http.Client. Unlike an APIError, no response
+// was obtained. Cancellation and deadlines of the call's context are
+// reported as the context's error rather than a TransportError.
+type TransportError struct {
+	// Method and URL identify the request which failed.
+	Method string
+	URL    string
+	// Err is the error returned by the http.Client.
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("client: %s %s failed: %v", e.Method, e.URL, unwrapURLError(e.Err))
+}
+
+// Unwrap returns the error returned by the http.Client.
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout returns whether the request failed by timing out.
+func (e *TransportError) Timeout() bool {
+	var t interface{ Timeout() bool }
+	return errors.As(e.Err, &t) && t.Timeout()
+}
+
+// IsTransportError returns whether err is, or wraps, a TransportError.
+func IsTransportError(err error) bool {
+	var e *TransportError
+	return errors.As(err, &e)
+}
+
+// unwrapURLError returns the error a *url.Error wraps, whose method and
+// URL a TransportError already reports.
+func unwrapURLError(err error) error {
+	if u, ok := err.(*url.Error); ok {
+		return u.Err
+	}
+
+	return err
+}
+
+// DecodeError is returned by calls whose response body can't be decoded
+// into the type the API declares for it.
+type DecodeError struct {
+	// StatusCode is the status of the response.
+	StatusCode int
+	// ContentType is the response's Content-Type header.
+	ContentType string
+	// Snippet holds the start of the response body.
+	Snippet string
+	// Err is the error returned by the decoder.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("client: can't decode %d response of type %q: %v (body: %q)",
+		e.StatusCode, e.ContentType, e.Err, e.Snippet)
+}
+
+// Unwrap returns the error returned by the decoder.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// maxSnippet is the most bytes of the body a DecodeError holds.
+const maxSnippet = 256
+
+func newDecodeError(res *http.Response, body []byte, err error) *DecodeError {
+	snippet := string(body)
+	if len(body) > maxSnippet {
+		snippet = string(body[:maxSnippet]) + "..."
+	}
+
+	return &DecodeError{
+		StatusCode:  res.StatusCode,
+		ContentType: res.Header.Get("Content-Type"),
+		Snippet:     snippet,
+		Err:         err,
+	}
+}
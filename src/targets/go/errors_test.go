@@ -0,0 +1,166 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testResponse returns a response with the status, Content-Type and body.
+func testResponse(status int, contentType, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {contentType}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestValidationErrorMessages(t *testing.T) {
+	if got, want := missingParam("userId").Error(), `client: missing required parameter "userId"`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	err := &ValidationError{Param: "limit", Reason: "must be at most 100"}
+	if got, want := err.Error(), `client: invalid parameter "limit": must be at most 100`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNewAPIError(t *testing.T) {
+	var body struct {
+		Message string `json:"message"`
+	}
+	res := testResponse(http.StatusNotFound, "application/json", `{"message": "no such user"}`)
+	e := newAPIError(res, &body)
+
+	if e.StatusCode != http.StatusNotFound || e.Body != &body || body.Message != "no such user" {
+		t.Errorf("got %+v decoding %+v, want the decoded 404 body", e, body)
+	}
+	if string(e.Raw) != `{"message": "no such user"}` {
+		t.Errorf("got raw body %q", e.Raw)
+	}
+	if got := readAll(t, res); got != string(e.Raw) {
+		t.Errorf("read body %q after the error was made, want it buffered", got)
+	}
+	if got, want := e.Error(), "client: unsuccessful response status 404 Not Found"; got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+}
+
+func TestNewAPIErrorWithoutBody(t *testing.T) {
+	// Bodies which don't decode are only kept raw, as are those of
+	// statuses the API declares no body for.
+	var body struct{ Message string }
+	for _, e := range []*APIError{
+		newAPIError(testResponse(http.StatusInternalServerError, "application/json", "Oops"), &body),
+		newAPIError(testResponse(http.StatusInternalServerError, "application/json", "Oops"), nil),
+	} {
+		if e.Body != nil || string(e.Raw) != "Oops" {
+			t.Errorf("got body %v and raw %q, want only the raw body", e.Body, e.Raw)
+		}
+	}
+}
+
+func TestIsStatusError(t *testing.T) {
+	err := fmt.Errorf("fetching: %w", &APIError{StatusCode: http.StatusConflict})
+	for _, tc := range []struct {
+		err  error
+		code int
+		want bool
+	}{
+		{err, http.StatusConflict, true},
+		{err, 0, true},
+		{err, http.StatusNotFound, false},
+		{errors.New("client: other"), 0, false},
+		{nil, 0, false},
+	} {
+		if got := IsStatusError(tc.err, tc.code); got != tc.want {
+			t.Errorf("IsStatusError(%v, %d) = %v, want %v", tc.err, tc.code, got, tc.want)
+		}
+	}
+}
+
+// testError stands in for the API's shared Error type.
+type testError struct {
+	Message string `json:"message"`
+	status  int
+}
+
+func (e *testError) Error() string        { return e.Message }
+func (e *testError) setStatus(status int) { e.status = status }
+
+func TestAPIErrorUnwrapsErrorBodies(t *testing.T) {
+	res := testResponse(http.StatusBadRequest, "application/json", `{"message": "bad name"}`)
+	err := error(newAPIError(res, new(testError)))
+
+	var body *testError
+	if !errors.As(err, &body) || body.Message != "bad name" || body.status != http.StatusBadRequest {
+		t.Fatalf("got %+v from %v, want the decoded body with its status", body, err)
+	}
+	if got, want := err.Error(), "client: unsuccessful response status 400: bad name"; got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+	if !IsStatusError(err, http.StatusBadRequest) {
+		t.Error("the error isn't a 400 APIError")
+	}
+
+	var notErr struct{ Message string }
+	if err := newAPIError(res, &notErr).Unwrap(); err != nil {
+		t.Errorf("unwrapped %v from a body which isn't an error", err)
+	}
+}
+
+func TestTransportErrors(t *testing.T) {
+	// Nothing listens on the address of a closed server, so dialing fails.
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	_, err := get(t, NewClient(srv.URL), "/users")
+	var terr *TransportError
+	if !errors.As(err, &terr) || !IsTransportError(err) || IsStatusError(err, 0) {
+		t.Fatalf("got error %v, want a TransportError", err)
+	}
+	if terr.Method != "GET" || terr.URL != srv.URL+"/users" || terr.Timeout() {
+		t.Errorf("got %+v, want a GET of /users which didn't time out", terr)
+	}
+	if want := "client: GET " + srv.URL + "/users failed: "; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("got message %q, want it to start %q", err, want)
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Op != "dial" {
+		t.Errorf("got error %v, want it to wrap the dial's", err)
+	}
+}
+
+func TestTransportErrorTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+
+	c := NewClient(srv.URL)
+	c.HTTP.Timeout = 20 * time.Millisecond
+	_, err := get(t, c, "/")
+	var terr *TransportError
+	if !errors.As(err, &terr) || !terr.Timeout() {
+		t.Errorf("got error %v, want a TransportError which timed out", err)
+	}
+}
+
+func TestStatusErrorsAreNotTransportErrors(t *testing.T) {
+	res := testResponse(http.StatusInternalServerError, "text/plain", "Oops")
+	err := error(newAPIError(res, nil))
+	if !IsStatusError(err, http.StatusInternalServerError) || IsTransportError(err) {
+		t.Errorf("got error %v, want only a status error", err)
+	}
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// idempotencyHeader is the header carrying idempotency keys.
+const idempotencyHeader = "Idempotency-Key"
+
+// IdempotencyFilter gives calls of non-idempotent methods, such as POST,
+// an `Idempotency-Key` header holding a random UUID, unless they already
+// have one, so that servers supporting the header can recognize retries
+// of a call. The key is set once per call, before it's first sent, so the
+// attempts made by a RetryFilter carry the same key. RetryFilter retries
+// requests with a key as though they were idempotent.
+type IdempotencyFilter struct{}
+
+var _ callPreparer = IdempotencyFilter{}
+
+func (IdempotencyFilter) prepareCall(req *http.Request) {
+	if !isIdempotentMethod(req.Method) && req.Header.Get(idempotencyHeader) == "" {
+		req.Header.Set(idempotencyHeader, newIdempotencyKey())
+	}
+}
+
+func (IdempotencyFilter) Before(req *http.Request) error          { return nil }
+func (IdempotencyFilter) After(res *http.Response)                {}
+func (IdempotencyFilter) AfterError(req *http.Request, err error) {}
+
+// WithIdempotencyKey sends the call with the key in its `Idempotency-Key`
+// header, or a random UUID if the key is empty. Like keys set by an
+// IdempotencyFilter, it's kept across retries of the call.
+func WithIdempotencyKey(key string) CallOption {
+	return func(c *callOptions) {
+		c.edits = append(c.edits, func(req *http.Request) {
+			if key == "" {
+				req.Header.Set(idempotencyHeader, newIdempotencyKey())
+			} else {
+				req.Header.Set(idempotencyHeader, key)
+			}
+		})
+	}
+}
+
+// newIdempotencyKey returns a random (version 4) UUID.
+func newIdempotencyKey() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+// keyServer starts a server recording the idempotency keys it's sent,
+// failing the first attempt of each call with a 503.
+func keyServer(t *testing.T) (*Client, func() []string) {
+	var mu sync.Mutex
+	var keys []string
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Get(idempotencyHeader))
+		if len(keys)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	c := NewClient(srv.URL)
+	c.UseRetry(RetryOptions{BaseDelay: time.Millisecond})
+	return c, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), keys...)
+	}
+}
+
+func post(t *testing.T, c *Client, opts ...CallOption) {
+	t.Helper()
+	req, _ := http.NewRequest("POST", "/payments", strings.NewReader(`{"amount":1}`))
+	res, err := c.do(context.Background(), req, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want the retry to succeed", res.StatusCode)
+	}
+}
+
+func TestIdempotencyKeyKeptAcrossRetries(t *testing.T) {
+	c, keys := keyServer(t)
+	c.AddFilter(IdempotencyFilter{})
+
+	post(t, c)
+	post(t, c)
+	got := keys()
+	if len(got) != 4 {
+		t.Fatalf("made %d attempts, want 4", len(got))
+	}
+	if !uuidPattern.MatchString(got[0]) {
+		t.Errorf("got key %q, want a UUID", got[0])
+	}
+	if got[0] != got[1] || got[2] != got[3] {
+		t.Errorf("got keys %q, want retries to repeat their call's", got)
+	}
+	if got[0] == got[2] {
+		t.Errorf("two calls were both sent key %q", got[0])
+	}
+}
+
+func TestWithIdempotencyKey(t *testing.T) {
+	c, keys := keyServer(t)
+
+	post(t, c, WithIdempotencyKey("payment-1"))
+	post(t, c, WithIdempotencyKey(""))
+	got := keys()
+	if len(got) != 4 {
+		t.Fatalf("made %d attempts, want 4, as keys make POSTs safe to retry", len(got))
+	}
+	if got[0] != "payment-1" || got[1] != "payment-1" {
+		t.Errorf("got keys %q, want payment-1", got[:2])
+	}
+	if !uuidPattern.MatchString(got[2]) || got[2] != got[3] {
+		t.Errorf("got keys %q, want the same UUID", got[2:])
+	}
+}
+
+func TestIdempotencyFilterKeepsKeys(t *testing.T) {
+	var f IdempotencyFilter
+	for _, tc := range []struct {
+		method, key string
+		want        *regexp.Regexp
+	}{
+		{"GET", "", regexp.MustCompile(`^$`)},
+		{"PUT", "", regexp.MustCompile(`^$`)},
+		{"POST", "", uuidPattern},
+		{"PATCH", "", uuidPattern},
+		{"POST", "mine", regexp.MustCompile(`^mine$`)},
+	} {
+		req, _ := http.NewRequest(tc.method, "https://api.example.com/", nil)
+		if tc.key != "" {
+			req.Header.Set(idempotencyHeader, tc.key)
+		}
+		f.prepareCall(req)
+		if got := req.Header.Get(idempotencyHeader); !tc.want.MatchString(got) {
+			t.Errorf("%s with key %q: got key %q, want one matching %s", tc.method, tc.key, got, tc.want)
+		}
+	}
+}
@@ -1,13 +1,178 @@
-import { Module, File, Func, Arg, Call, Struct } from "./lang";
+import { Module, File, Func, Arg, Call, Struct, Enum } from "./lang";
 import { WriteCollector } from "./util";
-import { Target } from "../../target";
+import { Target, GenerateOptions } from "../../target";
 import { Todo } from "../../todo";
+import { IncludeResolver } from "../../include";
+import { declaredTypes, descriptionOf, facet, isUnsecured } from "../../model";
 import { api10 } from "raml-1-parser";
 
 import * as child from "child_process";
 import * as path from "path";
 import * as fs from "fs";
 
+/**
+ * The directory holding the hand-written Go runtime files.
+ */
+const runtimeDir = path.join(__dirname, "../../../src/targets/go");
+
+/**
+ * Hand-written Go files which are copied into every generated module.
+ */
+const runtimeFiles = [
+    "bootstrap.go",
+    "cache.go",
+    "codec.go",
+    "compress.go",
+    "dates.go",
+    "digest.go",
+    "errors.go",
+    "idempotency.go",
+    "logging.go",
+    "metrics.go",
+    "multipart.go",
+    "oauth1.go",
+    "options.go",
+    "patch.go",
+    "ratelimit.go",
+    "retry.go",
+    "schema.go",
+];
+
+/**
+ * Returns the names of the methods which the runtime files declare on the
+ * Client, which generated methods mustn't reuse.
+ */
+function runtimeClientMethods(): Array<string> {
+    const names: Array<string> = [];
+    runtimeFiles.forEach(file => {
+        const source = fs.readFileSync(path.join(runtimeDir, file), "utf8");
+        const re = /^func \(\w+ \*Client\) (\w+)/gm;
+        for (let match = re.exec(source); match; match = re.exec(source)) {
+            names.push(match[1]);
+        }
+    });
+
+    return names;
+}
+
+/**
+ * The annotations which steer generation, used from the annotations.raml
+ * library as `go`, like `(go.methodName)`.
+ */
+const annotationNames = ["methodName", "package", "ping", "pointer", "skip", "timeout"];
+
+/**
+ * Returns the value of the node's `go` annotation with the name, which is
+ * null for annotations without a value, or undefined if it's absent.
+ */
+function goAnnotation(node: { annotations(): Array<api10.AnnotationRef> }, name: string): any {
+    const ref = node.annotations().find(a => a.name() === `go.${name}`);
+    if (!ref) {
+        return undefined;
+    }
+
+    const value = ref.structuredValue() && ref.structuredValue().value();
+    return value === undefined ? null : value;
+}
+
+/**
+ * Returns a Go expression for the method's `(go.timeout)`, a duration in
+ * the format of time.ParseDuration such as `30s` or `1m30s`, or null if
+ * it has none. Invalid durations are warned about and ignored.
+ */
+function goTimeout(method: api10.Method): string {
+    const value = goAnnotation(method, "timeout");
+    if (value === undefined) {
+        return null;
+    }
+
+    const units: { [unit: string]: number } = { ns: 1, us: 1e3, "µs": 1e3, ms: 1e6, s: 1e9, m: 60e9, h: 3600e9 };
+    const part = /(\d+(?:\.\d*)?|\.\d+)(ns|us|µs|ms|s|m|h)/g;
+    const str = String(value);
+    if (!(/^((\d+(\.\d*)?|\.\d+)(ns|us|µs|ms|s|m|h))+$/).test(str)) {
+        console.warn(`Ignoring invalid (go.timeout: ${str}) on ${method.method().toUpperCase()}, which must be like 30s`);
+        return null;
+    }
+
+    let ns = 0;
+    for (let match = part.exec(str); match; match = part.exec(str)) {
+        ns += Math.round(Number(match[1]) * units[match[2]]);
+    }
+
+    const names: Array<[string, number]> = [
+        ["Hour", 3600e9], ["Minute", 60e9], ["Second", 1e9], ["Millisecond", 1e6], ["Microsecond", 1e3],
+    ];
+    const unit = names.find(([, size]) => ns % size === 0);
+    if (!unit) {
+        return String(ns);
+    }
+
+    return ns === unit[1] ? `time.${unit[0]}` : `${ns / unit[1]} * time.${unit[0]}`;
+}
+
+/**
+ * Warns about `go` annotations on the API's resources, methods and types
+ * which the generator doesn't know, and ignores.
+ */
+function warnUnknownAnnotations(api: api10.Api) {
+    const check = (node: { annotations(): Array<api10.AnnotationRef> }, where: string) => {
+        node.annotations()
+            .map(a => a.name())
+            .filter(name => (/^go\./).test(name) && annotationNames.indexOf(name.slice(3)) === -1)
+            .forEach(name => console.warn(`Ignoring unknown annotation (${name}) on ${where}`));
+    };
+    const checkResource = (resource: api10.Resource) => {
+        check(resource, resource.completeRelativeUri());
+        resource.methods().forEach(m => check(m, `${m.method().toUpperCase()} ${resource.completeRelativeUri()}`));
+        resource.resources().forEach(checkResource);
+    };
+
+    check(api, "the API");
+    api.resources().forEach(checkResource);
+    declaredTypes(api).forEach(({ name, decl }) => {
+        check(decl, name);
+        if (isObjectType(decl)) {
+            (<api10.ObjectTypeDeclaration>decl).properties().forEach(prop => check(prop, `${name}.${prop.name()}`));
+        }
+    });
+}
+
+/**
+ * Go's keywords, which can't be used as package names, and which unexported
+ * names derived from properties are kept from colliding with.
+ */
+const keywords = [
+    "break", "case", "chan", "const", "continue", "default", "defer", "else",
+    "fallthrough", "for", "func", "go", "goto", "if", "import", "interface",
+    "map", "package", "range", "return", "select", "struct", "switch", "type",
+    "var",
+];
+
+/**
+ * Packages which may be referenced from generated type expressions, by the
+ * name they're referenced with.
+ */
+const knownPackages: { [name: string]: string } = {
+    context: "context",
+    http: "net/http",
+    io: "io",
+    time: "time",
+    url: "net/url",
+};
+
+/**
+ * Adds imports to the file for the packages referenced in a type.
+ */
+function importPackagesOf(file: File, type: string) {
+    const re = /\b([a-z]+)\.[A-Z]/g;
+    let match: RegExpExecArray;
+    while ((match = re.exec(type)) !== null) {
+        if (knownPackages[match[1]]) {
+            file.import(knownPackages[match[1]]);
+        }
+    }
+}
+
 /**
  * Uppercases the first character in the string.
  */
@@ -19,7 +184,7 @@ function upperFirst(str: string): string {
  * A map of terms which should have their cased versions treated specially.
  */
 const capitalizations = [
-    { needle: /Id/g, replace: "ID" },
+    { needle: /Id(?=s?(?![a-z]))/g, replace: "ID" },
     { needle: /Oauth/g, replace: "OAuth" },
 ];
 
@@ -35,11 +200,33 @@ function fixCaps(str: string): string {
 }
 
 /**
- * Returns the Go type for a RAML type string.
+ * Returns whether a body's type is an inline or included JSON schema.
+ */
+function isJSONSchema(type: string): boolean {
+    return !!type && type.trim()[0] === "{";
+}
+
+/**
+ * Returns the Go identifier for a declared RAML type. Types from libraries,
+ * such as `lib.Foo`, are prefixed with their library's alias, as `LibFoo`.
+ */
+function goTypeName(name: string): string {
+    return name.split(".").map(upperFirst).join("");
+}
+
+/**
+ * Returns the Go type for a RAML type string. When translating types
+ * declared inside a library, ns is the library's namespace, such as "lib.",
+ * which references to other declared types are resolved within.
  */
-function translateTypeString(str: string): string {
+function translateTypeString(str: string, ns: string=""): string {
+    str = str.trim();
+    if ((/^\([^()]*\)$/).test(str)) {
+        return translateTypeString(str.slice(1, -1), ns);
+    }
+
     if (str.endsWith("[]")) {
-        return `[]${translateTypeString(str.slice(0, -2))}`;
+        return `[]${translateTypeString(str.slice(0, -2), ns)}`;
     }
 
     if (str.indexOf("|") !== -1) {
@@ -48,52 +235,154 @@ function translateTypeString(str: string): string {
 
     switch (str) {
     case "string":              return "string";
-    case "integer":
-    case "number":              return "int";
+    case "integer":             return "int64";
+    case "number":              return "float64";
     case "uint":                return "uint";
     case "boolean":             return "bool";
     case "object":              return "map[string]interface{}";
+    case "any":                 return "interface{}";
     case "file":                return "io.Reader";
     case "IsoDate":             return "time.Time";
     case "UnixTimestampMillis": return "time.Time";
+    case "datetime":            return "time.Time";
+    case "date-only":           return "DateOnly";
+    case "time-only":           return "TimeOnly";
+    case "datetime-only":       return "DateTimeOnly";
     }
 
-    return str;
+    return goTypeName(ns + str);
 }
 
 /**
- * Returns the Go type for a RAML type declaration.
+ * The Go types of the formats RAML integers and numbers may declare. Those
+ * without a format are int64 and float64, so no value is truncated.
+ */
+const numberFormats : { [format: string]: string } = {
+    int8: "int8",
+    int16: "int16",
+    int32: "int32",
+    int64: "int64",
+    int: "int",
+    long: "int64",
+    float: "float32",
+    double: "float64",
+};
+
+/**
+ * Returns the Go type for a RAML type declaration, which may be declared
+ * within the library namespace ns.
  */
-function translateType(type: api10.TypeDeclaration): string {
+function translateType(type: api10.TypeDeclaration, ns: string=""): string {
     let out = "";
     if (!type.required()) {
         out += "*";
     }
 
-    let primary = type.type()[0];
+    let primary = primaryType(type);
+    let decl = type;
     if (primary === "array") {
         out += "[]";
-        primary = (<api10.ArrayTypeDeclaration>type).items().type()[0];
+        decl = (<api10.ArrayTypeDeclaration>type).items();
+        primary = decl.type()[0];
     }
 
-    out += translateTypeString(primary);
+    const format = facet(decl, "format");
+    if (primary === "datetime" && format === "rfc2616") {
+        out += "HTTPTime";
+    } else if ((primary === "integer" || primary === "number") && numberFormats[format]) {
+        out += numberFormats[format];
+    } else {
+        out += translateTypeString(primary, ns);
+    }
 
     return fixCaps(out);
 }
 
 /**
- * Returns the internal, Go name for a JavaScript property.
+ * Initialisms which Go names spell in a consistent case, such as `UserID`
+ * and `userID` rather than `UserId`, following golint. Plurals, like IDs,
+ * keep a lowercase s.
+ */
+const initialisms = [
+    "acl", "api", "ascii", "cpu", "css", "dns", "eof", "guid", "html", "http",
+    "https", "id", "ip", "json", "lhs", "qps", "ram", "rhs", "rpc", "sla",
+    "smtp", "sql", "ssh", "tcp", "tls", "ttl", "udp", "ui", "uid", "uri",
+    "url", "utf8", "uuid", "vm", "xml", "xmpp", "xsrf", "xss",
+];
+
+/**
+ * Returns the Go spelling of a word within a name: initialisms are
+ * uppercased, the OAuth special case is respected, and other words are
+ * capitalized. Words written in all caps, as in `USER_NAME`, are treated
+ * as ordinary words.
+ */
+function goWord(word: string): string {
+    const lower = word.toLowerCase();
+    if (initialisms.indexOf(lower) !== -1) {
+        return lower.toUpperCase();
+    }
+    if (lower.endsWith("s") && initialisms.indexOf(lower.slice(0, -1)) !== -1) {
+        return lower.slice(0, -1).toUpperCase() + "s";
+    }
+    if (lower === "oauth") {
+        return "OAuth";
+    }
+    if (word.length > 1 && word === word.toUpperCase()) {
+        return word[0] + lower.slice(1);
+    }
+
+    return upperFirst(word);
+}
+
+/**
+ * Returns the Go name for a RAML or JSON property, which may be in camel,
+ * snake or kebab case, like `createdAt`, `created_at` or `user-id`. The
+ * words are joined in camel case, with initialisms such as ID and URL
+ * uppercased, becoming `CreatedAt` and `UserID`. Names which would start
+ * with a digit are prefixed with N. The property's own name is kept in the
+ * struct tags and parameter names, so no information is lost.
  */
 function translatePropName(name: string, isExported: boolean=true): string {
-    name = name.split(/[^a-z0-9]+/ig)
-        .map((str, i) => (i > 0 || isExported) ? upperFirst(str) : str)
-        .join('');
+    const words = name.match(/[A-Z]{2,}s(?![a-z])|[A-Z]+(?![a-z])[0-9]*|[A-Z]?[a-z]+[0-9]*|[0-9]+[a-z]*/g) || [];
+    if (words.length === 0) {
+        return isExported ? "Field" : "field";
+    }
+
+    let out = words.map(goWord).join("");
+    if (!isExported) {
+        out = words[0].toLowerCase() + out.slice(goWord(words[0]).length);
+    }
+    if ((/^[0-9]/).test(out)) {
+        out = (isExported ? "N" : "n") + out;
+    }
 
-    if (name === "type") {
-        name = "kind";
+    if (out === "type") {
+        out = "kind";
+    } else if (keywords.indexOf(out) !== -1) {
+        out += "Value";
     }
 
-    return fixCaps(name);
+    return out;
+}
+
+/**
+ * The names of locals, receivers and arguments which generated calls
+ * declare themselves, and which arguments named after parameters would
+ * shadow or clash with.
+ */
+const reservedLocals = [
+    "body", "c", "ctx", "err", "form", "headers", "opts", "parts", "payload",
+    "q", "query", "req", "res", "result", "v",
+];
+
+/**
+ * Returns the Go name for the argument of a call taking the parameter, as
+ * translatePropName does for unexported names, suffixing names which clash
+ * with the call's locals with Value.
+ */
+function translateArgName(name: string): string {
+    const out = translatePropName(name, false);
+    return reservedLocals.indexOf(out) !== -1 ? `${out}Value` : out;
 }
 
 /**
@@ -104,292 +393,2745 @@ function getSuccessfulResponse(method: api10.Method): api10.Response {
 }
 
 /**
- * Generates a method name to query the method on the specified resource.
+ * Returns whether the API declares a security scheme of the given type,
+ * such as "OAuth 2.0" or "Basic Authentication".
  */
-function inferMethodName(resource: api10.Resource, method: api10.Method): string {
-    if (method.displayName() !== null) {
-        return method.displayName();
+function hasSecurityScheme(api: api10.Api, type: string): boolean {
+    return api.securitySchemes().some(scheme => scheme.type() === type);
+}
+
+/**
+ * Returns the settings of a security scheme as a plain object.
+ */
+function getSchemeSettings(scheme: api10.AbstractSecurityScheme): { [key: string]: any } {
+    const settings = <any>scheme.settings();
+    return (settings && settings.toJSON()) || {};
+}
+
+/**
+ * Inspects a custom security scheme for password login settings. These are
+ * the `loginUri` to post credentials to, and optionally the names of the
+ * `usernameField` and `passwordField` and the `mediaType` to post them as.
+ * Returns null if the scheme doesn't describe a password login.
+ */
+function getPasswordLogin(scheme: api10.AbstractSecurityScheme) {
+    const settings = getSchemeSettings(scheme);
+    if (!scheme.type().startsWith("x-") || typeof settings["loginUri"] !== "string") {
+        return null;
     }
 
-    let parts = resource.absoluteUri()
-        .split("/")
-        .filter(seg => !(/^\{.+\}$/).test(seg))
-        .map(part => upperFirst(part))
-        .slice(5);
+    return {
+        uri: <string>settings["loginUri"],
+        usernameField: <string>settings["usernameField"] || "username",
+        passwordField: <string>settings["passwordField"] || "password",
+        form: settings["mediaType"] === "application/x-www-form-urlencoded",
+    };
+}
 
-    const sr = getSuccessfulResponse(method);
-    const primary = sr && sr.body().length && sr.body()[0].type()[0];
-    if (primary && primary[0].toUpperCase() === primary[0]) {
-        parts[parts.length - 1] = primary;
+/**
+ * Inspects a custom or pass-through security scheme, returning the header or
+ * query parameter it describes if it's an API key scheme. A scheme counts
+ * as an API key when it's described by exactly one such parameter.
+ */
+function getAPIKeyParam(scheme: api10.AbstractSecurityScheme): { name: string, in: string } {
+    const type = scheme.type();
+    if (type !== "Pass Through" && !type.startsWith("x-")) {
+        return null;
     }
 
-    const tail = fixCaps(parts.join("").replace(/[^a-z0-9]/ig, ""));
+    const described = scheme.describedBy();
+    const headers = described ? described.headers() : [];
+    const query = described ? described.queryParameters() : [];
+    if (headers.length + query.length !== 1) {
+        return null;
+    }
 
-    switch (method.method()) {
-    case "get":    return `Get${tail}`;
-    case "post":   return `Create${tail}`;
-    case "put":
-    case "patch":  return `Update${tail}`;
-    case "delete": return `Delete${tail}`;
+    if (headers.length) {
+        return { name: headers[0].name(), in: "APIKeyInHeader" };
     }
 
-    return "UNKNOWN";
+    return { name: query[0].name(), in: "APIKeyInQuery" };
 }
 
 /**
- * Writes a struct containing the specified list of types to standard
- * output.
+ * Returns the declared protocols as lowercase URL schemes, sorted.
  */
-function generateStruct(file: File, api: api10.Api, name: string, type: api10.ObjectTypeDeclaration) {
+function protocolsOf(protocols: Array<string>): Array<string> {
+    return (protocols || []).map(p => p.toLowerCase()).sort();
+}
 
-    const struct = file.struct(name);
+/**
+ * Returns whether the resource addresses a single member of a collection,
+ * meaning its path ends with a URI parameter, like `/users/{userId}`.
+ */
+function isMemberResource(resource: api10.Resource): boolean {
+    return (/\{[^}]+\}$/).test(resource.relativeUri().value());
+}
 
-    (function gen(type: api10.TypeDeclaration) {
-        if (!("properties" in type)) {
-            return;
-        }
+/**
+ * Returns the name segments of the resource's path, leaving out parameters.
+ */
+function resourcePath(resource: api10.Resource): Array<string> {
+    return resource.completeRelativeUri()
+        .split("/")
+        .filter(seg => seg !== "" && !(/^\{.+\}$/).test(seg));
+}
 
-        const objType = <api10.ObjectTypeDeclaration>type;
-        const declaration : { [prop:string]: string } = {};
-        objType.properties().forEach(prop => {
-            struct.field(
-                translatePropName(prop.name()),
-                translateType(prop),
-                `json:"${prop.name()}"`
-            );
-        });
+/**
+ * GOOS and GOARCH values, which Go files mustn't be named after with an
+ * underscore, like `users_linux.go`, lest they only build on that platform.
+ */
+const buildSuffixes = [
+    "aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "js", "linux",
+    "nacl", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows", "zos",
+    "386", "amd64", "amd64p32", "arm", "arm64", "arm64be", "armbe", "loong64", "mips", "mips64",
+    "mips64le", "mips64p32", "mips64p32le", "mipsle", "ppc", "ppc64", "ppc64le", "riscv",
+    "riscv64", "s390", "s390x", "sparc", "sparc64", "wasm", "test",
+];
 
-        objType.type().forEach(subtype => {
-            api.types().some(type => {
-                if (type.name() === subtype) {
-                    gen(type);
-                    return true;
-                }
-            });
-        });
-    })(type);
-}
+/**
+ * Returns the name, without the .go extension, of the file holding the
+ * calls on a top-level resource named with the path segment, like
+ * `user_groups` for `/user-groups`. Names which the runtime or other
+ * generated files use, or which Go would build only on some platforms or
+ * in tests, get an `_endpoints` suffix. Leading underscores, which would
+ * make Go ignore the file, are dropped.
+ */
+function goFileName(seg: string): string {
+    const name = seg.replace(/([a-z0-9])([A-Z])/g, "$1_$2").toLowerCase()
+        .replace(/[^a-z0-9]+/g, "_").replace(/^_+|_+$/g, "");
+    if (!name) {
+        return "endpoints";
+    }
 
-enum ReqStructKind {
-    Payload = 0,
-    Params
+    const taken = runtimeFiles.concat("api.go", "endpoints.go", "models.go", "stub.go")
+        .map(file => file.replace(/\.go$/, ""));
+    const suffix = name.slice(name.lastIndexOf("_") + 1);
+    const clashes = taken.indexOf(name) !== -1
+        || (name.indexOf("_") !== -1 && buildSuffixes.indexOf(suffix) !== -1);
+    return clashes ? `${name}_endpoints` : name;
 }
 
 /**
- * Request is a class that generates a function to make a request against
- * a particular endpoint.
+ * Returns the verb a method on the resource is named with, following the
+ * collection/member pattern: GET methods on collections which return arrays
+ * are `List`, other GET methods are `Get`, and so on.
  */
-class Request {
+function methodVerb(resource: api10.Resource, method: api10.Method): string {
+    const sr = getSuccessfulResponse(method);
+    const primary = sr && sr.body().length && sr.body()[0].type()[0];
+    const listing = !isMemberResource(resource) && (/(\[\]|^array)$/).test(primary || "");
 
-    private func : Func;
-    private options : WriteCollector;
-    private before : WriteCollector;
-    private after : WriteCollector;
+    switch (method.method()) {
+    case "get":    return listing ? "List" : "Get";
+    case "post":   return "Create";
+    case "put":
+    case "patch":  return "Update";
+    case "delete": return "Delete";
+    }
 
-    constructor(private file: File, private resource: api10.Resource) {}
+    return upperFirst(method.method());
+}
 
-    /**
-     * Returns an array of arguments used to format the query string call.
-     */
-    private getPathFmtArgs(): Array<Arg> {
-        return this.resource.absoluteUriParameters().slice(1).map(param => {
-            return new Arg(
-                translatePropName(param.name(), false),
-                translateType(param)
-            );
-        });
+/**
+ * Generates a method name to query the method on the specified resource.
+ * Methods are named by their `(go.methodName)` annotation or after their
+ * display name if they have one, and
+ * otherwise with their verb followed by the resource path, except that GET
+ * methods on members are named after the type they return, if it's a named
+ * type.
+ */
+function inferMethodName(resource: api10.Resource, method: api10.Method): string {
+    if (typeof goAnnotation(method, "methodName") === "string") {
+        return goAnnotation(method, "methodName");
     }
 
-    /**
-     * Returns a string for the method call to fmt.Sprintf to generate
-     * the path to query.
-     */
-    private getPathFmtCall(): string {
-        let uri = this.resource.absoluteUri().replace("{version}", "1");
-        const args = this.getPathFmtArgs();
-        if (args.length === 0) {
-            return `"${uri}" + q`;
-        }
+    if (method.displayName() !== null) {
+        return fixCaps(method.displayName().split(/[^a-z0-9]+/ig).map(upperFirst).join("")
+            .replace(/^(\d)/, "Call$1"));
+    }
 
-        this.file.import("fmt");
-        this.resource.absoluteUriParameters().slice(1).forEach(param => {
-            uri = uri.replace(`{${param.name()}}`, param.type()[0] === "number" ? "%d" : "%s");
-        });
+    let parts = resourcePath(resource).map(part => upperFirst(part));
 
-        args.unshift(new Arg(`"${uri}"`, "string"));
-        return new Call("fmt.Sprintf", ...args).toString().trim() + " + q";
+    const sr = getSuccessfulResponse(method);
+    const primary = sr && sr.body().length && sr.body()[0].type()[0];
+    // Inline JSON schemas, which start with a brace, aren't named types.
+    if (isMemberResource(resource) && (/^[A-Z]/).test(primary || "")) {
+        parts[parts.length - 1] = primary;
     }
 
-    /**
-     * Adds query parameter initializations to the current request, if needed.
-     */
-    private generateQueryParams(method: api10.Method) {
-        const queryParams = method.is().reduce(
-            (params, trait) => params.concat(trait.trait().queryParameters()),
-            method.queryParameters()
-        );
+    return methodVerb(resource, method) + fixCaps(parts.join("").replace(/[^a-z0-9]/ig, ""));
+}
 
-        if (queryParams.length === 0) {
-            this.before.write(`q := ""\n`)
-            return;
+/**
+ * Returns whether the method's name is set by the API, rather than inferred.
+ */
+function hasCustomName(method: api10.Method): boolean {
+    return method.displayName() !== null || typeof goAnnotation(method, "methodName") === "string";
+}
+
+/**
+ * Returns unique names for the methods on their resources, in the order
+ * given, none of which are among the reserved names. Methods are named by
+ * inferMethodName. Those which collide are named with their full path
+ * instead, then also with the parameters along it, like
+ * `GetOrgsByOrgIDRepos`, and if they still collide they're numbered in
+ * order, so the same definition always produces the same names.
+ */
+function nameMethods(calls: Array<{ resource: api10.Resource, method: api10.Method }>,
+                     reserved: Array<string>): Array<string> {
+    const candidates = calls.map(({ resource, method }) => {
+        const names = [inferMethodName(resource, method)];
+        if (hasCustomName(method)) {
+            return names;
         }
 
-        this.file.import("net/url");
+        [false, true].forEach(params => {
+            const path = resource.completeRelativeUri().split("/")
+                .filter(seg => seg !== "" && (params || !(/^\{.+\}$/).test(seg)))
+                .map(seg => (/^\{.+\}$/).test(seg)
+                    ? `By${translatePropName(seg.slice(1, -1))}`
+                    : upperFirst(seg.replace(/[^a-z0-9]/ig, "")));
+            names.push(methodVerb(resource, method) + fixCaps(path.join("")));
+        });
 
-        const type = `${this.func.getName()}Params`;
-        const struct = this.file.struct(type);
-        this.func.arg("query", type);
-        this.before.write(`v := url.Values{}\n`)
+        return names;
+    });
 
-        queryParams.forEach(prop => {
-            const propName = translatePropName(prop.name())
-            struct.field(propName, translateTypeString(prop.type()[0]));
-            this.before.write(`v.Set("${prop.name()}", query.${propName})\n`)
+    const level = calls.map(() => 0);
+    const names = () => candidates.map((names, i) => names[level[i]]);
+    const clashes = (all: Array<string>, i: number) => reserved.indexOf(all[i]) !== -1
+        || all.some((name, j) => j !== i && name === all[i]);
+
+    for (let changed = true; changed; ) {
+        const current = names();
+        changed = false;
+        current.forEach((name, i) => {
+            if (clashes(current, i) && level[i] < candidates[i].length - 1) {
+                level[i]++;
+                changed = true;
+            }
         });
-        this.before.write(`q := "?" + v.Encode()\n`)
     }
 
-    private generateBodyParams(method: api10.Method) {
-        const resolved = {};
-        const body = <api10.ObjectTypeDeclaration>method.is().reduce(
-            (params, trait) => params.concat(trait.trait().body()),
-            method.body()
-        ).shift();
-
-        if (!body) {
-            return;
+    const taken = reserved.slice();
+    return names().map(name => {
+        let unique = name;
+        for (let n = 2; taken.indexOf(unique) !== -1; n++) {
+            unique = `${name}${n}`;
         }
+        taken.push(unique);
 
-        switch (body.displayName()) {
-        case "application/json":
-            let type = translateTypeString(body.type()[0]);
-            if (!this.file.module.getIdentifier(type)) {
-                type = `${this.func.getName()}Payload`;
-                generateStruct(this.file, method.ownerApi(), type, body);
-            }
+        return unique;
+    });
+}
 
-            this.func.arg("payload", type);
-            this.file.import("bytes").import("encoding/json");
-            this.before.write(`
-                body, err := json.Marshal(payload)
-                if err != nil {
-                    return err
-                }
-            `);
-            this.options.write(`
-                Body: bytes.NewReader(body),
-                ContentLength: len(body),
-                Header: http.Header{"Content-Type": {"application/json"}},
-            `);
-        break;
-        case "multipart/form-data":
-            // todo
-        break;
-        default:
-            console.error("Unknown body type:", body.toJSON());
-        }
-    }
+/**
+ * Finds the declared type with the given name, which is resolved within the
+ * library namespace ns.
+ */
+function findType(api: api10.Api, name: string, ns: string=""): api10.TypeDeclaration {
+    const found = declaredTypes(api).find(type => type.name === ns + name);
+    return found && found.decl;
+}
 
-    /**
-     * Generates the post-request serialization and return values for
-     * the function under construction.
-     */
-    private generateFuncReturns(method: api10.Method) {
-        const goodRes = getSuccessfulResponse(method);
-        let resType : string;
+/**
+ * Returns whether the type declaration describes an object.
+ */
+function isObjectType(type: api10.TypeDeclaration): boolean {
+    return "properties" in type;
+}
 
-        this.func.returns("*http.Response");
-        if (goodRes && goodRes.body().length > 0) {
-            resType = translateType(goodRes.body()[0]);
-            this.func.returns(resType);
-        }
-        this.func.returns("error");
+/**
+ * Writes a struct for the object type to the file. Properties become
+ * fields, and object types it inherits from are embedded. Properties
+ * declared with an inline object type get a struct of their own, named
+ * after the parent and property.
+ */
+function generateStruct(file: File, api: api10.Api, name: string, type: api10.ObjectTypeDeclaration, ns: string="") {
 
-        if (!goodRes) {
-            this.after.write("return res, err\n");
-            return;
+    const struct = file.struct(name);
+
+    type.type().forEach(supertype => {
+        const parent = findType(api, supertype, ns);
+        if (parent && isObjectType(parent)) {
+            struct.composes(fixCaps(goTypeName(ns + supertype)));
         }
+    });
 
-        this.after.write(`
-            if err != nil || res.StatusCode >= 300 {
-                return res, ${resType ? "nil, " : ""}err
-            }
+    type.properties().forEach(prop => {
+        const field = translatePropName(prop.name());
+        let fieldType = translateType(prop, ns);
+        // Unset optional arrays, objects and values of any type are nil
+        // slices, maps and interfaces, rather than nil pointers to them.
+        fieldType = fieldType.replace(/^\*(?=\[\]|map\[|interface\{)/, "");
+        importPackagesOf(file, fieldType);
+        const enumType = generateEnum(file, prop, field, name);
+        if (enumType) {
+            fieldType = (prop.required() ? "" : "*") + enumType;
+        } else if (prop.type()[0] === "object" && isObjectType(prop)
+            && (<api10.ObjectTypeDeclaration>prop).properties().length > 0) {
+            generateStruct(file, api, name + field, <api10.ObjectTypeDeclaration>prop, ns);
+            fieldType = (prop.required() ? "" : "*") + name + field;
+        }
 
-            var typ ${resType}
-            if err := json.NewDecoder(res.Body).Decode(&typ); err != nil {
-                return err
-            }
+        if (goAnnotation(prop, "pointer") !== undefined && !(/^(\*|\[\]|map\[|interface\{)/).test(fieldType)) {
+            fieldType = `*${fieldType}`;
+        }
 
-            return res, typ, nil
-        `);
-    }
+        // Optional fields are pointers, or slices or maps, so they're only
+        // left out when unset rather than whenever they're zero.
+        const omit = prop.required() ? "" : ",omitempty";
+        let tags = `json:"${prop.name()}${omit}"`;
+        if (usesXML(api)) {
+            tags += ` ${xmlTag(prop, omit)}`;
+        }
 
-    /**
-     * Adds a method to query the endpoint on the resource
-     * to the associated file.
-     */
-    method(method: api10.Method) {
-        this.file.import("net/http");
+        struct.field(field, fieldType, tags,
+            docComment(field, descriptionOf(prop), `${field} holds the "${prop.name()}" property.`));
+    });
+}
 
-        this.func = this.file.func(inferMethodName(this.resource, method));
-        this.func.methodOf("c *Client").addArgs(...this.getPathFmtArgs());
+/**
+ * Returns the properties of the object type, following those of the object
+ * types it inherits from, each with the namespace of the type declaring it.
+ */
+function objectProperties(api: api10.Api, type: api10.ObjectTypeDeclaration, ns: string="")
+    : Array<{ prop: api10.TypeDeclaration, ns: string }> {
+
+    const out: Array<{ prop: api10.TypeDeclaration, ns: string }> = [];
+    type.type().forEach(supertype => {
+        const parent = findType(api, supertype, ns);
+        if (parent && isObjectType(parent)) {
+            const full = ns + supertype;
+            out.push(...objectProperties(api, <api10.ObjectTypeDeclaration>parent, full.slice(0, full.lastIndexOf(".") + 1)));
+        }
+    });
+    type.properties().forEach(prop => out.push({ prop, ns }));
+
+    return out.filter(({ prop }, i) => !out.slice(i + 1).some(later => later.prop.name() === prop.name()));
+}
+
+/**
+ * Writes a struct for the body of a partial update, such as a PATCH or a
+ * JSON merge patch, to the file. Unlike generateStruct, every property,
+ * including inherited ones, becomes an optional field, which is left out
+ * when nil so that the API leaves it unchanged. Fields named in the
+ * struct's NullFields are sent as null instead, clearing them.
+ */
+function generatePatchStruct(file: File, api: api10.Api, name: string, type: api10.ObjectTypeDeclaration) {
+    const struct = file.struct(name);
+    objectProperties(api, type).forEach(({ prop, ns }) => {
+        const field = translatePropName(prop.name());
+        let fieldType = translateType(prop, ns);
+        const enumType = generateEnum(file, prop, field, name);
+        if (enumType) {
+            fieldType = enumType;
+        } else if (prop.type()[0] === "object" && isObjectType(prop)
+            && (<api10.ObjectTypeDeclaration>prop).properties().length > 0) {
+            generateStruct(file, api, name + field, <api10.ObjectTypeDeclaration>prop, ns);
+            fieldType = name + field;
+        }
+        fieldType = fieldType.replace(/^\*?/, "*");
+        importPackagesOf(file, fieldType);
+
+        struct.field(field, fieldType, `json:"${prop.name()},omitempty"`,
+            docComment(field, descriptionOf(prop), `${field} holds the "${prop.name()}" property.`));
+    });
+    struct.field("NullFields", "[]string", `json:"-"`,
+        "// NullFields are the names of fields, like \"Name\", sent as null to\n"
+        + "// clear them, whatever their values.\n");
+
+    file.write(`// MarshalJSON implements json.Marshaler, sending the fields named in\n`);
+    file.write(`// NullFields as null.\n`);
+    const marshal = file.func("MarshalJSON").methodOf(`p ${name}`).returns("[]byte").returns("error");
+    marshal.write("return p.marshalPatch(JSONCodec)\n");
+
+    // Calls marshal the body with the Client's Codec, rather than through
+    // MarshalJSON, which can't see it.
+    file.write(`// marshalPatch marshals p with the codec, as MarshalJSON does.\n`);
+    const withCodec = new Func("marshalPatch").methodOf(`p ${name}`).returns("[]byte").returns("error");
+    withCodec.arg("codec", "Codec");
+    withCodec.write(`
+        type plain ${name}
+        return marshalPatch(codec, plain(p), p.NullFields)
+    `);
+    file.write(withCodec);
+}
+
+/**
+ * Returns the media type of a body. Bodies which don't declare one have the
+ * API's default `mediaType`, or application/json if it has none.
+ */
+function mediaTypeOf(api: api10.Api, body: api10.TypeDeclaration): string {
+    const declared = body.name();
+    if (declared && declared.indexOf("/") !== -1) {
+        return declared;
+    }
+
+    const defaults = api.mediaType();
+    return defaults.length > 0 ? defaults[0].value() : "application/json";
+}
+
+/**
+ * Returns the Accept header value asking for the bodies' media types, in
+ * the order they're declared. If there are several, the first is the most
+ * preferred, and the rest get decreasing quality values.
+ */
+function acceptHeader(api: api10.Api, bodies: Array<api10.TypeDeclaration>): string {
+    const types = bodies
+        .map(body => mediaTypeOf(api, body))
+        .filter((type, i, all) => all.indexOf(type) === i);
+
+    return types
+        .map((type, i) => i === 0 ? type : `${type};q=${Math.max(1, 10 - i) / 10}`)
+        .join(", ");
+}
+
+/**
+ * Returns whether a body holds binary data, which is streamed rather than
+ * encoded or decoded, because it's a file or has a binary media type.
+ */
+function isBinaryBody(api: api10.Api, body: api10.TypeDeclaration): boolean {
+    return body.type()[0] === "file"
+        || (/^(application\/(octet-stream|pdf|zip|gzip)|image\/|audio\/|video\/)/).test(mediaTypeOf(api, body));
+}
+
+/**
+ * Returns whether the media type is an XML one, like `application/xml` or
+ * `application/atom+xml`.
+ */
+function isXMLMediaType(mediaType: string): boolean {
+    return (/[\/+]xml$/).test(mediaType);
+}
+
+/**
+ * Returns whether any method of the API sends or receives XML bodies, in
+ * which case structs get XML tags as well as JSON ones.
+ */
+function usesXML(api: api10.Api): boolean {
+    const inResource = (resource: api10.Resource): boolean =>
+        resource.methods().some(method => method.body()
+            .concat(...method.responses().map(res => res.body()))
+            .some(body => isXMLMediaType(mediaTypeOf(api, body))))
+        || resource.resources().some(inResource);
+
+    return api.resources().some(inResource);
+}
+
+/**
+ * Returns the XML struct tag for a property, following its `xml` facet:
+ * properties may be renamed, sent as attributes, or, for arrays, wrapped
+ * in an element of their own. Options, like ",omitempty", are appended.
+ */
+function xmlTag(prop: api10.TypeDeclaration, options: string=""): string {
+    const xml = facet(prop, "xml");
+    const name = (xml && xml.name()) || prop.name();
+    if (xml && xml.attribute()) {
+        return `xml:"${name},attr${options}"`;
+    }
+
+    if (xml && xml.wrapped() && prop.type()[0] === "array") {
+        const items = (<api10.ArrayTypeDeclaration>prop).items();
+        const itemXML = facet(items, "xml");
+        return `xml:"${name}>${(itemXML && itemXML.name()) || items.type()[0]}${options}"`;
+    }
+
+    return `xml:"${name}${options}"`;
+}
+
+/**
+ * Returns the name of the root element of XML documents holding the type,
+ * which is given by its `xml` facet, or otherwise is the type's name.
+ */
+function xmlRootName(type: api10.TypeDeclaration, fallback: string): string {
+    const xml = facet(type, "xml");
+    if (xml && xml.name()) {
+        return xml.name();
+    }
+
+    const primary = type.type()[0];
+    return (/^[A-Z][\w.]*$/).test(primary || "") ? primary.split(".").pop() : fallback;
+}
+
+/**
+ * Returns the first type a declaration inherits from, such as "string" or
+ * "object". RAML 0.8 parameters have a single type, rather than a list.
+ */
+function primaryType(type: api10.TypeDeclaration): string {
+    const types = <string | Array<string>>(<any>type).type();
+    return Array.isArray(types) ? types[0] : types;
+}
+
+/**
+ * Returns a Go string literal for the string, raw if possible.
+ */
+function goString(str: string): string {
+    return str.indexOf("`") === -1 ? "`" + str + "`" : JSON.stringify(str);
+}
+
+/**
+ * Returns the JSON examples declared on the body, named after their name
+ * in the RAML definition. Examples which aren't valid JSON are left out.
+ */
+function jsonExamples(body: api10.TypeDeclaration): Array<{ name: string, value: string }> {
+    const specs = body.examples().length ? body.examples() : body.example() ? [body.example()] : [];
+    return specs
+        .map((spec, i) => {
+            const value = spec.value();
+            return {
+                name: spec.name() || (specs.length > 1 ? `example${i + 1}` : "example"),
+                value: typeof value === "string" ? value : JSON.stringify(value),
+            };
+        })
+        .filter(example => {
+            try {
+                return JSON.parse(example.value) !== undefined;
+            } catch (e) {
+                return false;
+            }
+        });
+}
+
+/**
+ * Removes inline markdown which doesn't render in godoc, such as emphasis
+ * and code spans, and writes out links with their URLs.
+ */
+function inlineMarkdown(text: string): string {
+    return text
+        .replace(/!\[([^\]]*)\]\([^)]*\)/g, "$1")
+        .replace(/\[([^\]]+)\]\(([^)\s]+)[^)]*\)/g, "$1 ($2)")
+        .replace(/<\/?[a-z][a-z0-9]*(\s[^>]*)?\/?>/ig, "")
+        .replace(/(\*\*|__)(.+?)\1/g, "$2")
+        .replace(/(^|\W)[*_](\S(?:.*?\S)?)[*_](?=\W|$)/g, "$1$2")
+        .replace(/`([^`]+)`/g, "$1")
+        .trim();
+}
+
+/**
+ * Breaks the text into lines of at most width characters, unless a single
+ * word is longer.
+ */
+function wrapText(text: string, width: number): Array<string> {
+    const lines: Array<string> = [];
+    text.split(/\s+/).filter(word => word !== "").forEach(word => {
+        const last = lines.length - 1;
+        if (last >= 0 && lines[last].length + 1 + word.length <= width) {
+            lines[last] += ` ${word}`;
+        } else {
+            lines.push(word);
+        }
+    });
+
+    return lines;
+}
+
+/**
+ * Converts a markdown description to the lines of a Go doc comment.
+ * Paragraphs are wrapped, headings become paragraphs of their own, list
+ * items are indented as godoc lists, and fenced code blocks are indented
+ * as preformatted text.
+ */
+function markdownToDoc(md: string, width: number=76): Array<string> {
+    const lines: Array<string> = [];
+    let paragraph: Array<string> = [];
+    let code = false;
+    const flush = () => {
+        lines.push(...wrapText(paragraph.join(" "), width));
+        paragraph = [];
+    };
+    const separate = () => {
+        flush();
+        if (lines.length && lines[lines.length - 1] !== "") {
+            lines.push("");
+        }
+    };
+
+    md.replace(/\r\n?/g, "\n").split("\n").forEach(line => {
+        if ((/^\s*(```|~~~)/).test(line)) {
+            separate();
+            code = !code;
+            return;
+        }
+        if (code) {
+            lines.push(`\t${line}`);
+            return;
+        }
+
+        const heading = line.match(/^\s*#+\s*(.*?)[\s#]*$/);
+        const item = line.match(/^\s*([-*+]|\d+\.)\s+(.*)$/);
+        if (heading) {
+            separate();
+            lines.push(inlineMarkdown(heading[1]), "");
+        } else if (item) {
+            flush();
+            const marker = (/\d/).test(item[1]) ? item[1] : "-";
+            wrapText(inlineMarkdown(item[2]), width - marker.length - 3).forEach((text, i) => {
+                lines.push(`  ${i ? " ".repeat(marker.length) : marker} ${text}`);
+            });
+        } else if (line.trim() === "") {
+            separate();
+        } else {
+            paragraph.push(inlineMarkdown(line));
+        }
+    });
+    flush();
+
+    while (lines.length && lines[lines.length - 1] === "") {
+        lines.pop();
+    }
+
+    return lines;
+}
+
+/**
+ * Returns a doc comment for the Go identifier from the description, which
+ * may be null, in which case the comment is empty. Per Go convention the
+ * comment begins with the name: descriptions which start with an article
+ * or a verb such as "Returns the" are joined to it, and others follow the
+ * fallback sentence.
+ */
+function docComment(name: string, description: string, fallback: string): string {
+    if (!description) {
+        return "";
+    }
+
+    const lowerFirst = (str: string) => (/^[A-Z](?![A-Z0-9])/).test(str) ? str[0].toLowerCase() + str.slice(1) : str;
+    if ((/^(A|An|The)\s/).test(description)) {
+        description = `${name} is ${lowerFirst(description)}`;
+    } else if ((/^[A-Z][a-z]+s\s+(the|a|an|all|any|each|every|one|some)\s/).test(description)) {
+        description = `${name} ${lowerFirst(description)}`;
+    } else if (!(new RegExp(`^${name}\\b`)).test(description)) {
+        description = (/^[#*+\-`~]|^\d+\./).test(description)
+            ? `${fallback}\n\n${description}`
+            : `${fallback} ${description}`;
+    }
+
+    return markdownToDoc(description)
+        .map(line => line === "" ? "//" : line[0] === "\t" ? `//${line}` : `// ${line}`)
+        .join("\n") + "\n";
+}
+
+/**
+ * Returns the values of an enum declared on the type, or null if it isn't
+ * an enum. Only enums of built-in scalar types count, since types which
+ * inherit from a declared enum type should just use that type.
+ */
+function enumValues(type: api10.TypeDeclaration): Array<string | number> {
+    if (!("enum" in type) || ["string", "number", "integer"].indexOf(primaryType(type)) === -1) {
+        return null;
+    }
+
+    const values = <Array<string | number>>(<any>type).enum();
+    return values && values.length ? values : null;
+}
+
+/**
+ * Writes a Go type for the enum declared on the type to the file, if it
+ * declares one, returning the type's name. Otherwise returns null. The type
+ * gets a constant for each value, named after the type and the value, and
+ * an IsValid method. If an enum of the same name with different values
+ * already exists, the name is prefixed with the owner's name, such as the
+ * method or struct the enum is used in.
+ */
+function generateEnum(file: File, type: api10.TypeDeclaration, name: string, owner: string): string {
+    const values = enumValues(type);
+    if (!values) {
+        return null;
+    }
+
+    const existing = <Enum>file.module.getIdentifier(name);
+    if (existing) {
+        if (existing instanceof Enum && existing.sameValues(values)) {
+            return name;
+        }
+
+        name = owner + name;
+        if (file.module.getIdentifier(name)) {
+            return name;
+        }
+    }
+
+    // Numeric enums share the Go type of their integer or number, such as
+    // int64 or float64, so that values like 1.5 are valid constants.
+    const base = primaryType(type) === "string" ? "string" : translateType(type).replace(/^\*/, "");
+    file.enumType(name, base, values);
+    return name;
+}
+
+/**
+ * Writes a wrapper struct for a union type to the file, with one pointer
+ * field for each member type, of which at most one is set. It marshals as
+ * whichever member is set. When unmarshalling, it uses the members'
+ * discriminator property if they declare one, or otherwise the first member
+ * which the JSON decodes into without unknown fields.
+ */
+function generateUnion(file: File, api: api10.Api, name: string, expr: string, ns: string="") {
+    const members = expr.split("|").map(member => {
+        const goType = fixCaps(translateTypeString(member, ns));
+        const decl = findType(api, member.trim(), ns);
+        return {
+            goType,
+            field: upperFirst(goType.replace(/^\[\]/, "").replace(/[^a-z0-9]/ig, ""))
+                + (goType.startsWith("[]") ? "List" : ""),
+            discriminator: decl && isObjectType(decl)
+                ? (<api10.ObjectTypeDeclaration>decl).discriminator() : null,
+            discriminatorValue: decl && isObjectType(decl)
+                ? (<api10.ObjectTypeDeclaration>decl).discriminatorValue() || decl.name() : null,
+        };
+    });
+
+    file.import("bytes").import("encoding/json").import("fmt");
+    file.write(`// ${name} holds one of ${members.map(m => m.goType).join(", ")}.\n`);
+    const struct = file.struct(name);
+    members.forEach(m => struct.field(m.field, `*${m.goType}`));
+
+    const marshal = file.func("MarshalJSON").methodOf(`u ${name}`)
+        .returns("[]byte").returns("error");
+    marshal.write("switch {\n");
+    members.forEach(m => marshal.write(`case u.${m.field} != nil:\nreturn json.Marshal(u.${m.field})\n`));
+    marshal.write(`}\n\nreturn []byte("null"), nil\n`);
+
+    const unmarshal = file.func("UnmarshalJSON").methodOf(`u *${name}`).returns("error");
+    unmarshal.arg("data", "[]byte");
+    unmarshal.write(`*u = ${name}{}\n`);
+
+    const discriminator = members[0].discriminator;
+    if (discriminator && members.every(m => m.discriminator === discriminator)) {
+        unmarshal.write(`
+            var probe struct {
+                Value string \`json:${JSON.stringify(discriminator)}\`
+            }
+            if err := json.Unmarshal(data, &probe); err != nil {
+                return err
+            }
+
+            switch probe.Value {
+        `);
+        members.forEach(m => unmarshal.write(`
+            case ${JSON.stringify(m.discriminatorValue)}:
+                u.${m.field} = new(${m.goType})
+                return json.Unmarshal(data, u.${m.field})
+        `));
+        unmarshal.write(`}\n`);
+    } else {
+        members.forEach(m => unmarshal.write(`
+            {
+                v := new(${m.goType})
+                dec := json.NewDecoder(bytes.NewReader(data))
+                dec.DisallowUnknownFields()
+                if dec.Decode(v) == nil {
+                    u.${m.field} = v
+                    return nil
+                }
+            }
+        `));
+    }
+
+    unmarshal.write(`
+        return fmt.Errorf("client: value does not match any member of ${name}: %s", data)
+    `);
+}
+
+/**
+ * Returns the Go type for a JSON schema, writing structs for the object
+ * schemas it contains to the file. Objects are named after their `title`
+ * or the name given, and nested schemas after the path to them. References
+ * to `#/definitions/...` are resolved against the root schema, and named
+ * after the definition. Optional properties are pointers, or slices and
+ * maps, tagged with `omitempty`.
+ */
+function generateSchemaType(file: File, schema: any, name: string, root: any): string {
+    const ref = schema["$ref"];
+    if (typeof ref === "string" && ref.startsWith("#/")) {
+        const target = ref.slice(2).split("/").reduce((node: any, key: string) => node && node[key], root);
+        if (!target) {
+            return "interface{}";
+        }
+
+        return generateSchemaType(file, target, goTypeName(ref.split("/").pop()), root);
+    }
+
+    let type = schema["type"];
+    if (Array.isArray(type)) {
+        type = type.find((t: string) => t !== "null");
+    }
+
+    switch (type) {
+    case "string":
+        if (schema["format"] === "date-time") {
+            file.import("time");
+            return "time.Time";
+        }
+        if (schema["format"] === "date") {
+            return "DateOnly";
+        }
+        return "string";
+    case "integer": return schema["format"] === "int32" ? "int32" : "int64";
+    case "number":  return schema["format"] === "float" ? "float32" : "float64";
+    case "boolean": return "bool";
+    case "array":
+        return "[]" + (schema["items"]
+            ? generateSchemaType(file, schema["items"], `${name}Item`, root)
+            : "interface{}");
+    case "object":
+    case undefined:
+        if (!schema["properties"]) {
+            return type ? "map[string]interface{}" : "interface{}";
+        }
+    break;
+    default:
+        return "interface{}";
+    }
+
+    const structName = schema["title"]
+        ? fixCaps(goTypeName(String(schema["title"]).replace(/[^a-z0-9.]+(.)?/ig,
+            (_: string, c: string) => c ? c.toUpperCase() : "")))
+        : name;
+    if (file.module.getIdentifier(structName)) {
+        return structName;
+    }
+
+    const required : Array<string> = schema["required"] || [];
+    const describe = (node: any) => typeof node["description"] === "string" ? node["description"].trim() || null : null;
+    file.write(docComment(structName, describe(schema), `${structName} is the ${schema["title"] || name} schema.`));
+    const struct = file.struct(structName);
+    Object.keys(schema["properties"]).forEach(prop => {
+        const field = translatePropName(prop);
+        let fieldType = generateSchemaType(file, schema["properties"][prop], structName + field, root);
+        let tag = `json:"${prop}"`;
+        if (required.indexOf(prop) === -1) {
+            if (!(/^(\[\]|map\[|interface\{)/).test(fieldType)) {
+                fieldType = `*${fieldType}`;
+            }
+            tag = `json:"${prop},omitempty"`;
+        }
+
+        struct.field(field, fieldType, tag,
+            docComment(field, describe(schema["properties"][prop]), `${field} holds the "${prop}" property.`));
+    });
+
+    return structName;
+}
+
+/**
+ * Returns a key identifying the structure of a JSON schema, so that equal
+ * schemas have equal keys. Annotations such as titles and descriptions are
+ * left out, and object keys are sorted.
+ */
+function schemaKey(schema: any, names: boolean=false): string {
+    if (Array.isArray(schema)) {
+        return `[${schema.map(item => schemaKey(item)).join(",")}]`;
+    }
+
+    if (!schema || typeof schema !== "object") {
+        return JSON.stringify(schema);
+    }
+
+    const annotations = ["title", "description", "$schema", "id", "example", "examples"];
+    return "{" + Object.keys(schema)
+        .filter(key => names || annotations.indexOf(key) === -1)
+        .sort()
+        .map(key => JSON.stringify(key) + ":" + schemaKey(schema[key],
+            !names && ["properties", "definitions", "patternProperties"].indexOf(key) !== -1))
+        .join(",") + "}";
+}
+
+/**
+ * Returns the JSON schemas of the bodies of the unsuccessful responses of
+ * all of the API's methods, with included files resolved.
+ */
+function errorSchemas(api: api10.Api, includes: IncludeResolver): Array<any> {
+    const out = new Array<any>();
+    const inResource = (resource: api10.Resource) => {
+        resource.methods().forEach(method => method.responses()
+            .filter(res => Number(res.code().value()) >= 300)
+            .forEach(res => res.body()
+                .filter(body => isJSONSchema(body.type()[0]))
+                .forEach(body => out.push(includes.resolveJSON(JSON.parse(body.type()[0]))))));
+        resource.resources().forEach(inResource);
+    };
+
+    api.resources().forEach(inResource);
+    return out;
+}
+
+enum ReqStructKind {
+    Payload = 0,
+    Params
+}
+
+/**
+ * Request is a class that generates a function to make a request against
+ * a particular endpoint.
+ */
+class Request {
+
+    private func : Func;
+    private headers : WriteCollector;
+    private before : WriteCollector;
+    private after : WriteCollector;
+    private body : string;
+    private resultType : string;
+    private resultHeaders : string;
+
+    /**
+     * Creates a generator for the resource's methods. Error bodies with the
+     * sharedError schema key are decoded into the shared Error type.
+     */
+    constructor(
+        private file: File,
+        private resource: api10.Resource,
+        private includes: IncludeResolver,
+        private sharedError: string
+    ) {}
+
+    /**
+     * Returns the Go type for a body whose type is a JSON schema, rather
+     * than a RAML type, generating structs for it as needed. Schemas
+     * included from files may reference further files, which are resolved
+     * first. Untitled schemas are named after the method, with the suffix.
+     */
+    private schemaType(schema: string, suffix: string): string {
+        const resolved = this.includes.resolveJSON(JSON.parse(schema));
+        return generateSchemaType(this.file, resolved, this.func.getName() + suffix, resolved);
+    }
+
+    /**
+     * Returns the URI parameters in the resource's path, relative to the
+     * API's base URI, in the order they appear in it, which is the order
+     * methods take them as arguments.
+     */
+    private uriParameters(): Array<api10.TypeDeclaration> {
+        const uri = this.resource.completeRelativeUri();
+        const position = (param: api10.TypeDeclaration) => uri.indexOf(`{${param.name()}}`);
+        return this.resource.absoluteUriParameters()
+            .filter(param => position(param) !== -1)
+            .sort((a, b) => position(a) - position(b));
+    }
+
+    /**
+     * Returns an array of arguments used to format the query string call.
+     */
+    private getPathFmtArgs(): Array<Arg> {
+        return this.uriParameters().map(param => {
+            const type = generateEnum(this.file, param, translatePropName(param.name()), this.func.getName())
+                || translateType(param);
+            importPackagesOf(this.file, type);
+            return new Arg(translateArgName(param.name()), type);
+        });
+    }
+
+    /**
+     * Returns a string for the method call to fmt.Sprintf to generate
+     * the path to query, which is relative to the client's base URL. Each
+     * parameter is escaped as a path segment.
+     */
+    private getPathFmtCall(): string {
+        let uri = this.resource.completeRelativeUri();
+        const args = this.getPathFmtArgs()
+            .map(arg => new Arg(`pathParam(${arg.argName})`, "string"));
+        if (args.length === 0) {
+            return `"${uri}" + q`;
+        }
+
+        this.file.import("fmt");
+        uri = uri.replace(/%/g, "%%");
+        this.uriParameters().forEach(param => {
+            uri = uri.replace(`{${param.name()}}`, "%s");
+        });
+
+        args.unshift(new Arg(`"${uri}"`, "string"));
+        return new Call("fmt.Sprintf", ...args).toString().trim() + " + q";
+    }
+
+    /**
+     * Returns checks of the parameter's value against the constraints it
+     * declares, which fail with a ValidationError before any request is
+     * made. The value is an expression of the parameter's Go type.
+     */
+    private generateValidation(param: api10.TypeDeclaration, value: string): string {
+        const out = new WriteCollector();
+        const name = JSON.stringify(param.name());
+        const check = (cond: string, reason: string) => {
+            out.write(`
+                if ${cond} {
+                    ${this.fail("nil", `&ValidationError{Param: ${name}, Reason: ${JSON.stringify(reason)}}`)}
+                }
+            `);
+        };
+
+        const values = enumValues(param);
+        if (values) {
+            check(`!${value.startsWith("*") ? `(${value})` : value}.IsValid()`,
+                `must be one of ${values.join(", ")}`);
+            return out.toString();
+        }
+
+        const pattern = facet(param, "pattern");
+        if (pattern !== null) {
+            const fn = this.func.getName();
+            const re = `${fn.slice(0, 1).toLowerCase()}${fn.slice(1)}${translatePropName(param.name())}Pattern`;
+            this.file.import("regexp");
+            this.file.write(`var ${re} = regexp.MustCompile(${goString(pattern)})\n\n`);
+            check(`!${re}.MatchString(${value})`, `must match ${pattern}`);
+        }
+
+        const minLength = facet(param, "minLength");
+        const maxLength = facet(param, "maxLength");
+        if (minLength !== null || maxLength !== null) {
+            this.file.import("unicode/utf8");
+        }
+        if (minLength !== null) {
+            check(`utf8.RuneCountInString(${value}) < ${minLength}`, `must be at least ${minLength} characters`);
+        }
+        if (maxLength !== null) {
+            check(`utf8.RuneCountInString(${value}) > ${maxLength}`, `must be at most ${maxLength} characters`);
+        }
+
+        // Numbers are floats in Go, and keep their bounds as they are, while
+        // bounds of integers are rounded inwards to remain valid constants.
+        const minimum = facet(param, "minimum");
+        const maximum = facet(param, "maximum");
+        const isFloat = (/^float/).test(translateType(param).replace(/^\*?(\[\])?/, ""));
+        if (minimum !== null) {
+            check(`${value} < ${isFloat ? minimum : Math.ceil(minimum)}`, `must be at least ${minimum}`);
+        }
+        if (maximum !== null) {
+            check(`${value} > ${isFloat ? maximum : Math.floor(maximum)}`, `must be at most ${maximum}`);
+        }
+
+        return out.toString();
+    }
+
+    /**
+     * Returns a check that a required parameter isn't left empty, failing
+     * with a ValidationError before any request is made. Strings which are
+     * empty take their default value instead, if there is one, and slices
+     * must have an item. Other types can't be told apart from values the
+     * caller set, so they aren't checked. Optional parameters instead take
+     * their default when they're nil.
+     */
+    private generateRequired(param: api10.TypeDeclaration, value: string, type: string): string {
+        const missing = this.fail("nil", `missingParam(${JSON.stringify(param.name())})`);
+        if ((/^\[\]/).test(type)) {
+            return `
+                if len(${value}) == 0 {
+                    ${missing}
+                }
+            `;
+        }
+
+        if (type !== "string") {
+            return "";
+        }
+
+        const fallback = facet(param, "default");
+        return `
+            if ${value} == "" {
+                ${fallback === null ? missing : `${value} = ${JSON.stringify(String(fallback))}`}
+            }
+        `;
+    }
+
+    /**
+     * Adds query parameter initializations to the current request, if needed.
+     * Required parameters are taken as arguments, while optional ones are
+     * gathered in a `<Func>Params` struct of pointers so that unset
+     * parameters can be told apart from zero values and left out.
+     */
+    private generateQueryParams(method: api10.Method) {
+        const queryParams = method.queryParameters();
+        const queryString = method.queryString();
+        if (queryString && isObjectType(queryString)) {
+            this.generateQueryString(method, <api10.ObjectTypeDeclaration>queryString);
+            return;
+        }
+
+        if (queryParams.length === 0) {
+            this.before.write(`q := ""\n`)
+            return;
+        }
+
+        this.file.import("net/url");
+        this.before.write(`v := url.Values{}\n`)
+
+        let struct : Struct;
+        queryParams.forEach(prop => {
+            const propName = translatePropName(prop.name())
+            // Repeated parameters of RAML 0.8 are taken as slices, like
+            // array-typed parameters.
+            const repeated = facet(prop, "repeat") === true;
+            const isArray = prop.type()[0] === "array" || repeated;
+            let type = generateEnum(this.file, prop, propName, this.func.getName());
+            if (type) {
+                type = prop.required() ? type : `*${type}`;
+            } else {
+                type = translateType(prop);
+                importPackagesOf(this.file, type);
+            }
+            if (repeated) {
+                type = `[]${type.replace(/^\*/, "")}`;
+            }
+
+            let value: string;
+            if (prop.required()) {
+                value = translateArgName(prop.name());
+                this.func.arg(value, type);
+            } else {
+                if (!struct) {
+                    const structName = `${this.func.getName()}Params`;
+                    struct = this.file.struct(structName);
+                    this.func.arg("query", structName);
+                }
+
+                value = `query.${propName}`;
+                struct.field(propName, isArray ? type.replace(/^\*/, "") : type, null,
+                    docComment(propName, descriptionOf(prop), `${propName} sets the "${prop.name()}" query parameter.`));
+            }
+
+            if (prop.required()) {
+                this.before.write(this.generateRequired(prop, value, type));
+            }
+
+            const checks = isArray ? "" : this.generateValidation(prop, prop.required() ? value : `*${value}`);
+            if (checks && prop.required()) {
+                this.before.write(checks);
+            } else if (checks) {
+                this.before.write(`if ${value} != nil {\n${checks}}\n`);
+            }
+
+            if (isArray) {
+                this.before.write(`c.addArrayParam(v, "${prop.name()}", formatParams(${value}))\n`);
+            } else if (prop.required()) {
+                this.before.write(`v.Set("${prop.name()}", formatParam(${value}))\n`);
+            } else {
+                const fallback = facet(prop, "default");
+                this.before.write(`
+                    if ${value} != nil {
+                        v.Set("${prop.name()}", formatParam(*${value}))
+                    }${fallback === null ? "" : ` else {
+                        v.Set("${prop.name()}", ${JSON.stringify(String(fallback))})
+                    }`}
+                `);
+            }
+        });
+
+        this.before.write(`
+            q := ""
+            if len(v) > 0 {
+                q = "?" + v.Encode()
+            }
+        `);
+    }
+
+    /**
+     * Adds a `query` argument for the method's RAML 1.0 queryString, which
+     * declares the query as a whole as an object type, and serializes it.
+     */
+    private generateQueryString(method: api10.Method, type: api10.ObjectTypeDeclaration) {
+        const api = method.ownerApi();
+        const declared = findType(api, type.type()[0]);
+        let name = translateTypeString(type.type()[0]).replace(/^\*/, "");
+        if (!declared || !isObjectType(declared) || !this.file.module.getIdentifier(name)) {
+            name = `${this.func.getName()}Query`;
+            generateStruct(this.file, api, name, type);
+        } else {
+            type = <api10.ObjectTypeDeclaration>declared;
+        }
+
+        this.func.arg("query", name);
+        this.file.import("net/url");
+        this.before.write(`v := url.Values{}\n`);
+        this.before.write(this.serializeQuery(type.properties(), "query", ""));
+        this.before.write(`
+            q := ""
+            if len(v) > 0 {
+                q = "?" + v.Encode()
+            }
+        `);
+    }
+
+    /**
+     * Returns code validating the properties of a queryString object held
+     * in value and adding them to the url.Values v. Properties of nested
+     * objects are named like `filter[name]`, following the prefix.
+     */
+    private serializeQuery(props: Array<api10.TypeDeclaration>, value: string, prefix: string): string {
+        return props.map(prop => {
+            const name = prefix ? `${prefix}[${prop.name()}]` : prop.name();
+            const field = `${value}.${translatePropName(prop.name())}`;
+
+            if (prop.type()[0] === "array") {
+                return `c.addArrayParam(v, ${JSON.stringify(name)}, formatParams(${field}))\n`;
+            }
+
+            if (prop.type()[0] === "object" && isObjectType(prop)
+                && (<api10.ObjectTypeDeclaration>prop).properties().length > 0) {
+                const nested = this.serializeQuery((<api10.ObjectTypeDeclaration>prop).properties(), field, name);
+                return prop.required() ? nested : `if ${field} != nil {\n${nested}}\n`;
+            }
+
+            const type = translateType(prop).replace(/^\*/, "");
+            const checks = this.generateValidation(prop, prop.required() ? field : `*${field}`);
+            if (!prop.required()) {
+                return `
+                    if ${field} != nil {
+                        ${checks}
+                        v.Set(${JSON.stringify(name)}, formatParam(*${field}))
+                    }
+                `;
+            }
+
+            const missing = type !== "string" ? "" : `
+                if ${field} == "" {
+                    ${this.fail("nil", `missingParam(${JSON.stringify(name)})`)}
+                }
+            `;
+
+            return `${missing}${checks}v.Set(${JSON.stringify(name)}, formatParam(${field}))\n`;
+        }).join("");
+    }
+
+    /**
+     * Adds arguments for the method's header parameters, which are set on
+     * the request. Like query parameters, required headers are taken as
+     * arguments, and optional ones gathered in a `<Func>Headers` struct.
+     * Missing required string headers fail with a ValidationError, and
+     * unset headers with a default value are sent with that value.
+     */
+    private generateHeaderParams(method: api10.Method) {
+        let struct : Struct;
+        method.headers().forEach(header => {
+            const field = translatePropName(header.name());
+            const name = JSON.stringify(header.name());
+            const isArray = header.type()[0] === "array";
+            const fallback = facet(header, "default");
+            let type = generateEnum(this.file, header, field, this.func.getName());
+            if (type) {
+                type = header.required() ? type : `*${type}`;
+            } else {
+                type = translateType(header);
+                importPackagesOf(this.file, type);
+            }
+
+            let value: string;
+            if (header.required()) {
+                value = translateArgName(header.name());
+                this.func.arg(value, type);
+            } else {
+                if (!struct) {
+                    const structName = `${this.func.getName()}Headers`;
+                    struct = this.file.struct(structName);
+                    this.func.arg("headers", structName);
+                }
+
+                value = `headers.${field}`;
+                struct.field(field, isArray ? type.replace(/^\*/, "") : type, null,
+                    docComment(field, descriptionOf(header), `${field} sets the "${header.name()}" header.`));
+            }
+
+            if (header.required()) {
+                this.before.write(this.generateRequired(header, value, type));
+            }
+
+            if (isArray) {
+                this.headers.write(`
+                    for _, item := range ${value} {
+                        req.Header.Add(${name}, formatParam(item))
+                    }
+                `);
+                return;
+            }
+
+            const checks = this.generateValidation(header, header.required() ? value : `*${value}`);
+            if (header.required()) {
+                this.before.write(checks);
+                this.headers.write(`req.Header.Set(${name}, formatParam(${value}))\n`);
+                return;
+            }
+
+            if (checks) {
+                this.before.write(`if ${value} != nil {\n${checks}}\n`);
+            }
+            this.headers.write(`
+                if ${value} != nil {
+                    req.Header.Set(${name}, formatParam(*${value}))
+                }${fallback === null ? "" : ` else {
+                    req.Header.Set(${name}, ${JSON.stringify(String(fallback))})
+                }`}
+            `);
+        });
+    }
+
+    private generateBodyParams(method: api10.Method) {
+        const resolved = {};
+        const body = <api10.ObjectTypeDeclaration>method.body()[0];
+
+        if (!body) {
+            return;
+        }
+
+        // Clients refuse to send GET and HEAD requests with bodies, which
+        // servers and proxies may ignore or reject.
+        if (["get", "head"].indexOf(method.method()) !== -1) {
+            console.error(`Ignoring the body of ${method.method().toUpperCase()} ${this.resource.completeRelativeUri()}`);
+            return;
+        }
+
+        const mediaType = mediaTypeOf(method.ownerApi(), body);
+
+        // RAML 0.8 form bodies declare formParameters in place of a type.
+        if (facet(body, "formParameters") && (/^(multipart\/form-data|application\/x-www-form-urlencoded)$/).test(mediaType)) {
+            this.generateFormBody(method, body, mediaType === "multipart/form-data");
+            return;
+        }
+
+        if (isBinaryBody(method.ownerApi(), body)) {
+            this.func.arg("payload", "io.Reader");
+            this.file.import("io");
+            this.body = "payload";
+            this.headers.write(`req.Header.Set("Content-Type", ${JSON.stringify(
+                mediaType === "*/*" ? "application/octet-stream" : mediaType)})\n`);
+            return;
+        }
+
+        // JSON media types, like application/vnd.api+json, are all marshalled
+        // the same way.
+        switch ((/[\/+]json$/).test(mediaType) ? "application/json" : mediaType) {
+        case "application/json":
+            const isPatch = (method.method() === "patch" || mediaType === "application/merge-patch+json")
+                && !isJSONSchema(body.type()[0]) && isObjectType(body);
+            let type = translateTypeString(body.type()[0]);
+            if (isJSONSchema(body.type()[0])) {
+                type = this.schemaType(body.type()[0], "Payload");
+            } else if (isPatch) {
+                type = `${this.func.getName()}Payload`;
+                generatePatchStruct(this.file, method.ownerApi(), type, <api10.ObjectTypeDeclaration>body);
+            } else if (!isObjectType(body)) {
+                // Arrays and scalars, like User[], are sent as they are.
+                type = translateType(body).replace(/^\*/, "");
+                importPackagesOf(this.file, type);
+            } else if (!this.file.module.getIdentifier(type)) {
+                type = `${this.func.getName()}Payload`;
+                generateStruct(this.file, method.ownerApi(), type, body);
+            }
+
+            this.func.arg("payload", type);
+            this.file.import("bytes");
+            this.before.write(`
+                body, err := ${isPatch ? "payload.marshalPatch(c.jsonCodec())" : "c.jsonCodec().Marshal(payload)"}
+                if err != nil {
+                    ${this.fail("nil", "err")}
+                }
+            `);
+            this.body = "bytes.NewReader(body)";
+            this.headers.write(`req.Header.Set("Content-Type", ${JSON.stringify(mediaType)})\n`);
+        break;
+        case "multipart/form-data":
+            this.generateFormBody(method, body, true);
+        break;
+        case "application/x-www-form-urlencoded":
+            this.generateFormBody(method, body, false);
+        break;
+        default:
+            if (!isXMLMediaType(mediaType)) {
+                console.error("Unknown body type:", body.toJSON());
+                break;
+            }
+
+            let xmlType = translateTypeString(body.type()[0]);
+            if (!isObjectType(body)) {
+                xmlType = translateType(body).replace(/^\*/, "");
+                importPackagesOf(this.file, xmlType);
+            } else if (!this.file.module.getIdentifier(xmlType)) {
+                xmlType = `${this.func.getName()}Payload`;
+                generateStruct(this.file, method.ownerApi(), xmlType, body);
+            }
+
+            // A document has a single root, so arrays are wrapped in one,
+            // holding an element per item.
+            let payload = "payload";
+            let root = xmlRootName(body, xmlType);
+            if (xmlType.startsWith("[]")) {
+                const item = (/^[A-Z]\w*$/).test(xmlType.slice(2)) ? xmlType.slice(2) : "item";
+                payload = `struct {\nItems ${xmlType} \`xml:"${item}"\`\n}{payload}`;
+                root = xmlRootName(body, `${item}s`);
+            }
+
+            this.func.arg("payload", xmlType);
+            this.file.import("bytes");
+            this.before.write(`
+                body, err := encodeXML(${payload}, ${JSON.stringify(root)})
+                if err != nil {
+                    ${this.fail("nil", "err")}
+                }
+            `);
+            this.body = "bytes.NewReader(body)";
+            this.headers.write(`req.Header.Set("Content-Type", ${JSON.stringify(mediaType)})\n`);
+        }
+    }
+
+    /**
+     * Adds a `<Func>Payload` argument for a multipart/form-data or
+     * application/x-www-form-urlencoded body, whose properties are sent as
+     * form fields. In multipart bodies, file properties are FileParts, which
+     * are streamed into the body rather than buffered. Unset optional fields
+     * are left out, or take their default. The body may also be of a declared
+     * object type, or list RAML 0.8 formParameters.
+     */
+    private generateFormBody(method: api10.Method, body: api10.ObjectTypeDeclaration, multipart: boolean) {
+        const type = `${this.func.getName()}Payload`;
+        const struct = this.file.struct(type);
+        this.func.arg("payload", type);
+
+        // add returns a statement adding a field to the body.
+        let add: (name: string, value: string) => string;
+        if (multipart) {
+            this.before.write("var parts []formPart\n");
+            add = (name, value) => `parts = append(parts, formPart{name: ${name}, value: formatParam(${value})})\n`;
+        } else {
+            this.file.import("net/url").import("strings");
+            this.before.write("form := url.Values{}\n");
+            add = (name, value) => `form.Add(${name}, formatParam(${value}))\n`;
+        }
+
+        let props = <Array<api10.TypeDeclaration>>facet(body, "formParameters");
+        if (!props) {
+            let decl = body;
+            if (!isObjectType(decl) || decl.properties().length === 0) {
+                decl = <api10.ObjectTypeDeclaration>findType(method.ownerApi(), body.type()[0]);
+            }
+            props = decl && isObjectType(decl) ? decl.properties() : [];
+        }
+
+        props.forEach(prop => {
+            const field = translatePropName(prop.name());
+            const value = `payload.${field}`;
+            const name = JSON.stringify(prop.name());
+            // Repeated form parameters of RAML 0.8 are taken as slices.
+            const repeated = facet(prop, "repeat") === true;
+            const isArray = primaryType(prop) === "array" || repeated;
+            const itemType = primaryType(prop) === "array"
+                ? (<api10.ArrayTypeDeclaration>prop).items().type()[0]
+                : primaryType(prop);
+
+            if (itemType === "file" && !multipart) {
+                console.error(`File property "${prop.name()}" can't be sent in a url-encoded body`);
+                return;
+            }
+
+            if (itemType === "file") {
+                struct.field(field, isArray ? "[]FilePart" : "*FilePart", null,
+                    docComment(field, descriptionOf(prop), `${field} holds the "${prop.name()}" file.`));
+                this.before.write(isArray ? `
+                    for i := range ${value} {
+                        parts = append(parts, formPart{name: ${name}, file: &${value}[i]})
+                    }
+                ` : `
+                    if ${value} != nil {
+                        parts = append(parts, formPart{name: ${name}, file: ${value}})
+                    }
+                `);
+                return;
+            }
+
+            let fieldType = generateEnum(this.file, prop, field, type);
+            fieldType = fieldType ? (prop.required() ? "" : "*") + fieldType : translateType(prop);
+            if (repeated) {
+                fieldType = `[]${fieldType.replace(/^\*/, "")}`;
+            }
+            importPackagesOf(this.file, fieldType);
+            fieldType = isArray ? fieldType.replace(/^\*/, "") : fieldType;
+            struct.field(field, fieldType, null,
+                docComment(field, descriptionOf(prop), `${field} holds the "${prop.name()}" field.`));
+
+            if (prop.required()) {
+                this.before.write(this.generateRequired(prop, value, fieldType));
+            }
+
+            const checks = isArray ? "" : this.generateValidation(prop, prop.required() ? value : `*${value}`);
+            if (checks && prop.required()) {
+                this.before.write(checks);
+            } else if (checks) {
+                this.before.write(`if ${value} != nil {\n${checks}}\n`);
+            }
+
+            const fallback = facet(prop, "default");
+            if (isArray) {
+                this.before.write(`for _, item := range ${value} {\n${add(name, "item")}}\n`);
+            } else if (prop.required()) {
+                this.before.write(add(name, value));
+            } else if (fallback === null) {
+                this.before.write(`if ${value} != nil {\n${add(name, `*${value}`)}}\n`);
+            } else {
+                this.before.write(`if ${value} != nil {\n${add(name, `*${value}`)}} else {\n${add(name, JSON.stringify(String(fallback)))}}\n`);
+            }
+        });
+
+        if (multipart) {
+            this.before.write("body, contentType := multipartBody(parts)\n");
+            this.body = "body";
+            this.headers.write(`req.Header.Set("Content-Type", contentType)\n`);
+        } else {
+            this.body = "strings.NewReader(form.Encode())";
+            this.headers.write(`req.Header.Set("Content-Type", "application/x-www-form-urlencoded")\n`);
+        }
+    }
+
+    /**
+     * Returns a statement which returns from the function under
+     * construction with the given response and error, and an empty result.
+     */
+    private fail(res: string, err: string): string {
+        const values = [res];
+        if (this.resultType) {
+            values.push("result");
+        }
+        if (this.resultHeaders) {
+            values.push(`${this.resultHeaders}{}`);
+        }
+
+        return `return ${values.concat(err).join(", ")}`;
+    }
+
+    /**
+     * Returns a statement which returns from the function under
+     * construction with the response, the result, if it has one, and the
+     * parsed response headers.
+     */
+    private succeed(result: string): string {
+        const values = ["res"];
+        if (this.resultType) {
+            values.push(result);
+        }
+        if (this.resultHeaders) {
+            values.push("resHeaders");
+        }
+
+        return `return ${values.concat("nil").join(", ")}`;
+    }
+
+    /**
+     * Adds the trailing return values of the function under construction,
+     * which are the parsed response headers, if any, and the error.
+     */
+    private returnsError() {
+        if (this.resultHeaders) {
+            this.func.returns(this.resultHeaders);
+        }
+        this.func.returns("error");
+    }
+
+    /**
+     * Writes a `<Func>ResponseHeaders` struct for the headers the successful
+     * response declares, such as `X-Total-Count`, which the function under
+     * construction returns parsed into their declared types after its
+     * result. Optional headers are pointers, left nil when they're absent.
+     */
+    private generateResponseHeaders(response: api10.Response) {
+        const headers = response ? response.headers() : [];
+        if (headers.length === 0) {
+            return;
+        }
+
+        const name = `${this.func.getName()}ResponseHeaders`;
+        this.file.write(`// ${name} holds the headers of the successful response to\n`);
+        this.file.write(`// ${this.func.getName()}.\n`);
+        const struct = this.file.struct(name);
+        headers.forEach(header => {
+            const field = translatePropName(header.name());
+            const type = translateType(header);
+            importPackagesOf(this.file, type);
+            struct.field(field, type, null,
+                docComment(field, descriptionOf(header), `${field} holds the "${header.name()}" response header.`));
+        });
+
+        this.resultHeaders = name;
+    }
+
+    /**
+     * Returns code parsing the response headers into the `resHeaders` the
+     * function under construction returns, if it returns any. The name
+     * keeps clear of the `headers` argument of optional request headers.
+     */
+    private parseResponseHeaders(response: api10.Response): string {
+        if (!this.resultHeaders) {
+            return "";
+        }
+
+        return `var resHeaders ${this.resultHeaders}\n` + response.headers().map(header => `
+            if err := parseHeader(res, ${JSON.stringify(header.name())}, &resHeaders.${translatePropName(header.name())}); err != nil {
+                ${this.fail("res", "err")}
+            }
+        `).join("");
+    }
+
+    /**
+     * Generates the post-request deserialization and return values for
+     * the function under construction. Response bodies which are decoded
+     * are buffered, so the caller can still read the returned response's
+     * body afterwards. Binary bodies are instead returned unread.
+     */
+    private generateFuncReturns(method: api10.Method) {
+        const goodRes = getSuccessfulResponse(method);
+
+        this.func.returns("*http.Response");
+        this.generateResponseHeaders(goodRes);
+        if (goodRes && goodRes.body().length > 0) {
+            const accept = acceptHeader(method.ownerApi(), goodRes.body());
+            this.headers.write(`req.Header.Set("Accept", ${JSON.stringify(accept)})\n`);
+        }
+
+        if (goodRes && goodRes.body().length > 0 && isBinaryBody(method.ownerApi(), goodRes.body()[0])) {
+            this.file.import("io");
+            this.resultType = "io.ReadCloser";
+            this.func.returns(this.resultType);
+            this.returnsError();
+            this.before.write(`var result ${this.resultType}\n`);
+            this.after.write(`
+                if err != nil {
+                    ${this.fail("res", "err")}
+                }
+                ${this.generateStatusCheck(method)}
+                ${this.parseResponseHeaders(goodRes)}
+                ${this.succeed("res.Body")}
+            `);
+            return;
+        }
+
+        if (goodRes && goodRes.body().length > 1 && this.generateVariants(method, goodRes.body())) {
+            return;
+        }
+
+        // The JSON schema of the result is baked in, for clients which
+        // validate responses.
+        let schema = "";
+        if (goodRes && goodRes.body().length > 0) {
+            const body = goodRes.body()[0];
+            if (isJSONSchema(body.type()[0])) {
+                const fn = this.func.getName();
+                schema = `${fn.slice(0, 1).toLowerCase()}${fn.slice(1)}ResultSchema`;
+                const resolved = this.includes.resolveJSON(JSON.parse(body.type()[0]));
+                this.file.write(`var ${schema} = newSchema(${goString(JSON.stringify(resolved))})\n\n`);
+            }
+            this.resultType = isJSONSchema(body.type()[0])
+                ? this.schemaType(body.type()[0], "Result")
+                : translateType(body);
+            importPackagesOf(this.file, this.resultType);
+            this.func.returns(this.resultType);
+            this.before.write(`var result ${this.resultType}\n`);
+        }
+        this.returnsError();
+
+        this.after.write(`
+            if err != nil {
+                ${this.fail("res", "err")}
+            }
+            ${this.generateStatusCheck(method)}
+            ${this.parseResponseHeaders(goodRes)}
+        `);
+
+        if (!this.resultType) {
+            this.after.write(`${this.succeed(null)}\n`);
+            return;
+        }
+
+        this.after.write(`
+            if streamed(opts) {
+                ${this.succeed("result")}
+            }
+        `);
+        if (schema) {
+            this.after.write(`
+                if err := c.validateResponse(res, ${schema}); err != nil {
+                    ${this.fail("res", "err")}
+                }
+            `);
+        }
+        this.after.write(`
+            if err := decodeResponse(res, &result); err != nil {
+                ${this.fail("res", "err")}
+            }
+
+            ${this.succeed("result")}
+        `);
+    }
+
+    /**
+     * Makes the function under construction return a `<Func>Result` struct
+     * with a field for each of the successful response's media types, if
+     * they're declared with different types, returning whether it did. The
+     * response body is decoded into the field for its Content-Type, leaving
+     * the others nil.
+     */
+    private generateVariants(method: api10.Method, bodies: Array<api10.TypeDeclaration>): boolean {
+        const api = method.ownerApi();
+        const variants = bodies
+            .filter(body => !isBinaryBody(api, body))
+            .map(body => ({ body, mediaType: mediaTypeOf(api, body) }))
+            .filter((v, i, all) => all.findIndex(other => other.mediaType === v.mediaType) === i)
+            .map(({ body, mediaType }, i, all) => {
+                const subtype = mediaType.split("/").pop().split("+").pop();
+                let field = (/^(json|xml)$/i).test(subtype) ? subtype.toUpperCase() : translatePropName(subtype);
+                if (all.some((other, j) => j !== i && other.mediaType.split("/").pop().split("+").pop() === subtype)) {
+                    field = fixCaps(translatePropName(mediaType).replace(/Json$/, "JSON").replace(/Xml$/, "XML"));
+                }
+
+                const type = isJSONSchema(body.type()[0])
+                    ? this.schemaType(body.type()[0], `Result${field}`)
+                    : translateType(body).replace(/^\*/, "");
+                return { mediaType, field, type };
+            });
+
+        if (variants.length < 2 || variants.every(v => v.type === variants[0].type)) {
+            return false;
+        }
+
+        const name = `${this.func.getName()}Result`;
+        this.file.write(`// ${name} holds the response body of ${this.func.getName()}, which is decoded\n`);
+        this.file.write(`// into the field for its media type. The others are left nil.\n`);
+        const struct = this.file.struct(name);
+        variants.forEach(v => {
+            importPackagesOf(this.file, v.type);
+            struct.field(v.field, `*${v.type}`, null, `// ${v.field} holds a body of type ${v.mediaType}.\n`);
+        });
+
+        this.resultType = name;
+        this.func.returns(name);
+        this.returnsError();
+        this.before.write(`var result ${name}\n`);
+        this.file.import("fmt");
+        this.after.write(`
+            if err != nil {
+                ${this.fail("res", "err")}
+            }
+            ${this.generateStatusCheck(method)}
+            ${this.parseResponseHeaders(getSuccessfulResponse(method))}
+            if streamed(opts) {
+                ${this.succeed("result")}
+            }
+
+            switch mediaType := responseMediaType(res); mediaType {
+            ${variants.map(v => `
+                case ${JSON.stringify(v.mediaType.toLowerCase())}:
+                    result.${v.field} = new(${v.type})
+                    if err := decodeResponse(res, result.${v.field}); err != nil {
+                        ${this.fail("res", "err")}
+                    }
+            `).join("")}
+            default:
+                ${this.fail("res", `fmt.Errorf("client: unexpected response media type %q", mediaType)`)}
+            }
+
+            ${this.succeed("result")}
+        `);
+
+        return true;
+    }
+
+    /**
+     * Returns a check failing the function under construction with an
+     * APIError if the response is unsuccessful. Bodies of responses whose
+     * status the method declares a body for are decoded into that type.
+     */
+    private generateStatusCheck(method: api10.Method): string {
+        const cases = method.responses()
+            .filter(res => Number(res.code().value()) >= 300 && res.body().length > 0)
+            .map(res => {
+                const code = res.code().value();
+                const body = res.body()[0];
+                let type = translateType(body).replace(/^\*/, "");
+                if (isJSONSchema(body.type()[0])) {
+                    const schema = this.includes.resolveJSON(JSON.parse(body.type()[0]));
+                    type = schemaKey(schema) === this.sharedError
+                        ? "Error"
+                        : this.schemaType(body.type()[0], `Error${code}`);
+                }
+
+                return `
+                    case ${code}:
+                        ${this.fail("res", `newAPIError(res, new(${type}))`)}
+                `;
+            });
+
+        return `
+            if res.StatusCode >= 300 {
+                ${cases.length ? `switch res.StatusCode {${cases.join("")}}` : ""}
+                ${this.fail("res", "newAPIError(res, nil)")}
+            }
+        `;
+    }
+
+    /**
+     * Writes an iterator over the pages of results of the function under
+     * construction, if the method is paginated. Pagination is detected by
+     * naming convention: methods taking an `offset` or `page` parameter and
+     * returning an array are paged by position, while those taking a
+     * `cursor` and returning an object with an array and a `next` cursor
+     * property are paged by cursor. A `limit` parameter, if any, is set to
+     * the page size the caller asks for. Besides stepping through pages with
+     * Next, iterators can collect the items of all of them with All.
+     */
+    private generateIterator(method: api10.Method) {
+        const params = method.queryParameters();
+        const typeOf = (param: api10.TypeDeclaration) => translateType(param).replace(/^\*/, "");
+        const find = (re: RegExp, type: RegExp) => params.find(p => re.test(p.name()) && type.test(typeOf(p)));
+        const integer = /^u?int(8|16|32|64)?$/;
+        const limit = find(/^(limit|per_?page|page_?size)$/i, integer);
+        let position = find(/^(offset|page)$/i, integer);
+        let itemType = this.resultType;
+        let items = "items := result";
+        let next: string;
+
+        if (this.resultType === `${this.func.getName()}Result`) {
+            return;
+        }
+
+        if (position && !(/^\[\]/).test(itemType || "")) {
+            return;
+        }
+
+        if (!position) {
+            position = find(/^cursor$/i, /^string$/);
+            const body = position && getSuccessfulResponse(method).body()[0];
+            const decl = body && findType(method.ownerApi(), body.type()[0]);
+            if (!decl || !isObjectType(decl)) {
+                return;
+            }
+
+            const props = (<api10.ObjectTypeDeclaration>decl).properties();
+            const list = props.find(p => p.type()[0] === "array");
+            const cursor = props.find(p => (/^next(_?cursor)?$/i).test(p.name()));
+            if (!list || !cursor || translateType(cursor).replace(/^\*/, "") !== "string") {
+                return;
+            }
+
+            const listField = `result.${translatePropName(list.name())}`;
+            itemType = translateType(list).replace(/^\*/, "");
+            items = list.required() ? `items := ${listField}` : `
+                var items ${itemType}
+                if ${listField} != nil {
+                    items = *${listField}
+                }
+            `;
+
+            next = `result.${translatePropName(cursor.name())}`;
+            next = cursor.required() ? `pos = ${next}` : `
+                pos = ""
+                if ${next} != nil {
+                    pos = *${next}
+                }
+            `;
+        }
+
+        // Required parameters are arguments to the function, which the
+        // iterator reassigns, while optional ones are fields of the query.
+        const assign = (param: api10.TypeDeclaration, value: string) => param.required()
+            ? `${translateArgName(param.name())} = ${value}`
+            : `query.${translatePropName(param.name())} = &${value}`;
+
+        const name = this.func.getName();
+        const field = `query.${translatePropName(position.name())}`;
+        let start = next ? `""` : position.name().toLowerCase() === "page" ? "1" : "0";
+        if (position.required()) {
+            start = translateArgName(position.name());
+        } else if (facet(position, "default") !== null) {
+            start = JSON.stringify(facet(position, "default"));
+        }
+        if (!next && !position.required()) {
+            start = `${typeOf(position)}(${start})`;
+        }
+
+        this.file.write(`// ${name}Iterator pages through the results of ${name}.\n`);
+        const iter = this.file.struct(`${name}Iterator`);
+        iter.field("fetch", `func(ctx context.Context) (${itemType}, bool, error)`);
+        iter.field("done", "bool");
+
+        this.file.write(`
+            // HasMore returns whether there may be further pages to fetch.
+            func (it *${name}Iterator) HasMore() bool {
+                return !it.done
+            }
+
+            // Next fetches the next page, returning no items once there are no
+            // more. If fetching a page fails, calling Next again retries it.
+            func (it *${name}Iterator) Next(ctx context.Context) (${itemType}, error) {
+                if it.done {
+                    return nil, nil
+                }
+
+                items, more, err := it.fetch(ctx)
+                if err != nil {
+                    return nil, err
+                }
+
+                it.done = !more
+                return items, nil
+            }
+
+            // All fetches the remaining pages, returning their items in order.
+            // If max is positive, it stops fetching once it has that many
+            // items, and returns only the first max. If fetching a page fails,
+            // it returns the items fetched before it along with the error.
+            func (it *${name}Iterator) All(ctx context.Context, max int) (${itemType}, error) {
+                var all ${itemType}
+                for it.HasMore() && (max <= 0 || len(all) < max) {
+                    items, err := it.Next(ctx)
+                    if err != nil {
+                        return all, err
+                    }
+                    all = append(all, items...)
+                }
+
+                if max > 0 && len(all) > max {
+                    all = all[:max]
+                }
+                return all, nil
+            }
+
+        `);
+
+        const args = this.func.getArgs().slice(1, -1);
+        this.file.write(`// ${name}Pages returns an iterator over the pages of ${name}. Pages\n`);
+        this.file.write(`// hold pageSize items, or the API's default number if it's zero.\n`);
+        this.file.write(`// Iteration stops after the first ${next ? "page without a next cursor" : "empty page"}.\n`);
+        const fn = this.file.func(`${name}Pages`).methodOf("c *Client");
+        fn.addArgs(...args);
+        fn.arg("pageSize", "int");
+        fn.arg("opts", "CallOption").variadic = true;
+        fn.returns(`*${name}Iterator`);
+
+        fn.write(`
+            pos := ${start}
+            ${position.required() ? "" : `if ${field} != nil {\npos = *${field}\n}`}
+
+            return &${name}Iterator{fetch: func(ctx context.Context) (${itemType}, bool, error) {
+                ${next ? `if pos != "" {\n${assign(position, "pos")}\n}` : assign(position, "pos")}
+                ${limit ? `if pageSize > 0 {\nsize := ${typeOf(limit)}(pageSize)\n${assign(limit, "size")}\n}` : ""}
+                _, result, ${this.resultHeaders ? "_, " : ""}err := c.${name}(${["ctx"].concat(args.map(a => a.argName), ["opts..."]).join(", ")})
+                if err != nil {
+                    return nil, false, err
+                }
+
+                ${items}
+                ${next
+                    ? `${next}\nreturn items, pos != "", nil`
+                    : `pos += ${position.name().toLowerCase() === "page" ? "1" : `${typeOf(position)}(len(items))`}\nreturn items, len(items) > 0, nil`}
+            }}
+        `);
+    }
+
+    /**
+     * Returns a statement restricting the call to the protocols the method
+     * allows, if it declares different ones from the API.
+     */
+    private generateProtocols(method: api10.Method): string {
+        const protocols = protocolsOf(method.protocols());
+        if (!protocols.length || protocols.join() === protocolsOf(method.ownerApi().protocols()).join()) {
+            return "";
+        }
+
+        return `ctx = withProtocols(ctx, ${protocols.map(p => JSON.stringify(p)).join(", ")})`;
+    }
 
-        this.options = new WriteCollector();
+    /**
+     * Adds a method with the given name to query the endpoint on the
+     * resource to the associated file, returning the generated function.
+     */
+    method(method: api10.Method, name: string): Func {
+        this.file.import("context").import("net/http");
+
+        const goodRes = getSuccessfulResponse(method);
+        const verb = method.method().toUpperCase();
+        const doc = docComment(name, descriptionOf(method),
+            `${name} calls ${verb} ${this.resource.completeRelativeUri()}.`);
+        this.file.write(doc);
+        if (goodRes && goodRes.body().length > 0 && isBinaryBody(method.ownerApi(), goodRes.body()[0])) {
+            this.file.write(doc ? "//\n" : "");
+            this.file.write(`// ${name} streams the response body, which is returned unread. The\n`);
+            this.file.write(`// caller is responsible for closing it.\n`);
+        }
+        const timeout = goTimeout(method);
+        if (timeout) {
+            this.file.write(doc ? "//\n" : "");
+            this.file.write(`// ${name} times out after ${goAnnotation(method, "timeout")}, unless the call is made\n`);
+            this.file.write(`// WithTimeout.\n`);
+        }
+
+        this.func = this.file.func(name);
+        this.func.methodOf("c *Client").arg("ctx", "context.Context");
+        if (timeout) {
+            this.file.import("time");
+        }
+        this.func.addArgs(...this.getPathFmtArgs());
+
+        this.headers = new WriteCollector();
         this.before = new WriteCollector();
         this.after = new WriteCollector();
+        this.body = "nil";
+        this.resultType = null;
 
+        this.generateFuncReturns(method);
+        this.getPathFmtArgs().forEach((arg, i) => {
+            this.before.write(this.generateRequired(this.uriParameters()[i], arg.argName, arg.argType));
+        });
+        this.uriParameters().forEach(param => {
+            this.before.write(this.generateValidation(param, translateArgName(param.name())));
+        });
         this.generateQueryParams(method);
+        this.generateHeaderParams(method);
         this.generateBodyParams(method);
-        this.generateFuncReturns(method);
+        this.func.arg("opts", "CallOption").variadic = true;
 
         this.func.write(`
+            ctx = withRoute(ctx, "${this.resource.completeRelativeUri()}")
+            ${isUnsecured(this.resource, method) ? "ctx = withoutAuth(ctx)" : ""}
+            ${this.generateProtocols(method)}
+            ${timeout ? `opts = append([]CallOption{WithTimeout(${timeout})}, opts...)` : ""}
             ${this.before.toString()}
-            res, err := c.do(&http.Request{
-                Method: "${method.method()}",
-                URL: ${this.getPathFmtCall()},
-                ${this.options}
-            })
+            req, err := http.NewRequest("${verb}", ${this.getPathFmtCall()}, ${this.body})
+            if err != nil {
+                ${this.fail("nil", "err")}
+            }
+            ${this.headers.toString()}
+            res, err := c.do(ctx, req, opts...)
             ${this.after.toString()}
         `);
+
+        this.generateIterator(method);
+
+        return this.func;
+    }
+
+    /**
+     * Adds a Ping method calling the health check endpoint on the resource,
+     * which returns nil on a 2xx response, returning the generated function.
+     * The response body is discarded.
+     */
+    ping(method: api10.Method): Func {
+        this.file.import("context").import("net/http").import("io");
+
+        const verb = method.method().toUpperCase();
+        const uri = this.resource.completeRelativeUri();
+        const timeout = goTimeout(method);
+        if (timeout) {
+            this.file.import("time");
+        }
+
+        this.file.write(`// Ping calls ${verb} ${uri}, returning nil if the API responds with a 2xx\n`);
+        this.file.write(`// status, such as for readiness probes. Other statuses are returned as an\n`);
+        this.file.write(`// APIError, and failures to reach the API as a TransportError.\n`);
+        this.func = this.file.func("Ping").methodOf("c *Client").returns("error");
+        this.func.arg("ctx", "context.Context");
+        this.func.arg("opts", "CallOption").variadic = true;
+        this.func.write(`
+            ctx = withRoute(ctx, "${uri}")
+            ${isUnsecured(this.resource, method) ? "ctx = withoutAuth(ctx)" : ""}
+            ${this.generateProtocols(method)}
+            ${timeout ? `opts = append([]CallOption{WithTimeout(${timeout})}, opts...)` : ""}
+            req, err := http.NewRequest("${verb}", "${uri}", nil)
+            if err != nil {
+                return err
+            }
+
+            res, err := c.do(ctx, req, opts...)
+            if err != nil {
+                return err
+            }
+            if res.StatusCode >= 300 {
+                return newAPIError(res, nil)
+            }
+
+            io.Copy(io.Discard, res.Body)
+            res.Body.Close()
+            return nil
+        `);
+
+        return this.func;
     }
 }
 
+/**
+ * Returns the call which checks the API's health, for Ping: the method
+ * annotated `(go.ping)`, or else a GET of a `/health` or `/healthz`
+ * resource. The call must take no parameters, as Ping sends none, or
+ * there's no health check.
+ */
+function findHealthCheck(calls: Array<{ resource: api10.Resource, method: api10.Method }>) {
+    const annotated = calls.filter(({ method }) => goAnnotation(method, "ping") !== undefined);
+    const candidates = annotated.length ? annotated : calls.filter(({ resource, method }) =>
+        method.method() === "get" && (/^\/healthz?$/).test(resource.completeRelativeUri()));
+
+    return candidates.find(({ resource, method }) => {
+        const required = method.queryParameters().concat(method.headers())
+            .filter(param => param.required() && facet(param, "default") === null);
+        if ((/\{[^}]+\}/).test(resource.completeRelativeUri()) || required.length || method.body().length) {
+            console.warn(`Not generating Ping for ${resource.completeRelativeUri()}, which takes parameters`);
+            return false;
+        }
+
+        return true;
+    });
+}
+
+/**
+ * SubClient describes a sub-client generated for a resource path, holding
+ * the calls on it and the sub-clients for resources nested beneath it.
+ */
+interface SubClient {
+    name: string;
+    path: string;
+    description: string;
+    children: { [name: string]: SubClient };
+    calls: Array<{ verb: string, fn: Func }>;
+}
+
 export class GoTarget implements Target {
 
     check(): Promise<void> {
         return Promise.resolve();
     }
 
-    private createEndpoints(api: api10.Api, file: File) {
-        const generateMethods = (resource: api10.Resource) => {
-            const generator = new Request(file, resource);
-            resource.methods().forEach(m => generator.method(m));
-            resource.resources().forEach(generateMethods);
+    /**
+     * Writes an Error type for the error body schema shared by the most
+     * unsuccessful responses, if any is shared by more than one, returning
+     * the schema's key. The type implements error with its message, and
+     * records the status of the response it was decoded from.
+     */
+    private createSharedError(api: api10.Api, file: File, includes: IncludeResolver): string {
+        const counts : { [key: string]: number } = {};
+        const schemas : { [key: string]: any } = {};
+        errorSchemas(api, includes).forEach(schema => {
+            const key = schemaKey(schema);
+            counts[key] = (counts[key] || 0) + 1;
+            schemas[key] = schema;
+        });
+
+        const key = Object.keys(counts).sort((a, b) => counts[b] - counts[a])[0];
+        if (!key || counts[key] < 2 || file.module.getIdentifier("Error")) {
+            return null;
+        }
+
+        const schema = JSON.parse(JSON.stringify(schemas[key]));
+        delete schema["title"];
+        if (generateSchemaType(file, schema, "Error", schema) !== "Error") {
+            return null;
+        }
+
+        const props = Object.keys(schema["properties"]);
+        const struct = <Struct>file.module.getIdentifier("Error");
+        const status = props.some(prop => translatePropName(prop) === "Status") ? "HTTPStatus" : "Status";
+        struct.field(status, "int", `json:"-"`);
+
+        const message = props.find(prop => (/^message$/i).test(prop)
+            && schema["properties"][prop]["type"] === "string");
+        let describe = "";
+        if (message) {
+            const field = `e.${translatePropName(message)}`;
+            describe = (schema["required"] || []).indexOf(message) === -1
+                ? `if ${field} != nil && *${field} != "" {\nreturn *${field}\n}\n`
+                : `if ${field} != "" {\nreturn ${field}\n}\n`;
+        }
+
+        file.import("fmt").import("net/http");
+        file.write(`
+            // Error implements error, describing the error by its message.
+            func (e *Error) Error() string {
+                ${describe}
+                return fmt.Sprintf("%d %s", e.${status}, http.StatusText(e.${status}))
+            }
+
+            // setStatus records the status of the response e was decoded from.
+            func (e *Error) setStatus(status int) {
+                e.${status} = status
+            }
+
+        `);
+
+        return key;
+    }
+
+    /**
+     * Writes the calls of the API. Those on each top-level resource, with
+     * their sub-clients and types, go in a file of their own named after
+     * it, in the order of their names so that regenerating the client from
+     * a changed definition makes small diffs. Calls not beneath a named
+     * resource go in the given file.
+     */
+    private createEndpoints(api: api10.Api, file: File, includes: IncludeResolver): Array<Func> {
+        const funcs = new Array<Func>();
+        const root: SubClient = { name: "", path: "", description: null, children: {}, calls: [] };
+        const sharedError = this.createSharedError(api, file, includes);
+        const segmentName = (seg: string) => fixCaps(upperFirst(seg).replace(/[^a-z0-9]/ig, ""));
+
+        const calls: Array<{ resource: api10.Resource, method: api10.Method }> = [];
+        const collectMethods = (resource: api10.Resource) => {
+            if (goAnnotation(resource, "skip") !== undefined) {
+                return;
+            }
+
+            resource.methods()
+                .filter(method => goAnnotation(method, "skip") === undefined)
+                .forEach(method => calls.push({ resource, method }));
+            resource.resources().forEach(collectMethods);
+        };
+        api.resources().forEach(collectMethods);
+
+        // Generated methods share the Client with the runtime's methods and
+        // the accessors of top-level sub-clients.
+        const reserved = runtimeClientMethods().concat(calls
+            .map(({ resource }) => resourcePath(resource)[0])
+            .filter(seg => seg !== undefined)
+            .map(segmentName));
+        const names = nameMethods(calls, reserved);
+
+        const subClientFiles: { [name: string]: File } = {};
+        const files: { [name: string]: { file: File, tests: File } } = {};
+        const groupOf = (resource: api10.Resource) => {
+            const seg = resourcePath(resource)[0];
+            const name = seg === undefined ? "endpoints" : goFileName(seg);
+            if (!files[name]) {
+                files[name] = { file: name === "endpoints" ? file : file.module.file(`${name}.go`), tests: null };
+            }
+
+            const group = files[name];
+            return {
+                file: group.file,
+                tests: () => group.tests || (group.tests = file.module.file(`${name}_test.go`)),
+            };
         };
 
-        api.resources().forEach(generateMethods);
+        calls
+            .map((call, i) => ({ call, name: names[i] }))
+            .sort((a, b) => a.name < b.name ? -1 : a.name > b.name ? 1 : 0)
+            .forEach(({ call: { resource, method }, name }) => {
+                const group = groupOf(resource);
+                const generator = new Request(group.file, resource, includes, sharedError);
+                const fn = generator.method(method, name);
+                funcs.push(fn);
+                this.createExampleTest(method, fn, group.tests);
+
+                let node = root;
+                let path = "";
+                resource.completeRelativeUri().split("/").slice(1).forEach(seg => {
+                    path += `/${seg}`;
+                    if ((/^\{.+\}$/).test(seg)) {
+                        return;
+                    }
+
+                    const name = segmentName(seg);
+                    if (node === root) {
+                        subClientFiles[name] = group.file;
+                    }
+                    if (!node.children[name]) {
+                        node.children[name] = {
+                            name: node.name + name,
+                            path,
+                            description: null,
+                            children: {},
+                            calls: [],
+                        };
+                    }
+                    node = node.children[name];
+                });
+
+                if (node !== root) {
+                    if (node.path === resource.completeRelativeUri()) {
+                        node.description = node.description || descriptionOf(resource);
+                    }
+
+                    const verb = hasCustomName(method) ? null : methodVerb(resource, method);
+                    node.calls.push({ verb, fn });
+                }
+            });
+
+        const health = findHealthCheck(calls);
+        if (health && reserved.concat(names).indexOf("Ping") !== -1) {
+            console.warn(`Not generating Ping for ${health.resource.completeRelativeUri()}, as the name is taken`);
+        } else if (health) {
+            funcs.push(new Request(file, health.resource, includes, sharedError).ping(health.method));
+        }
+
+        // Each top-level sub-client goes in the file of its resource's calls.
+        Object.keys(root.children).sort().forEach(key => {
+            const top: SubClient = { name: "", path: "", description: null, children: {}, calls: [] };
+            top.children[key] = root.children[key];
+            this.createSubClients(top, "c *Client", "c", subClientFiles[key]);
+        });
+
+        return funcs;
     }
 
-    private createModels(api: api10.Api, file: File) {
-        api.types().forEach(type => {
-            if (!("properties" in type)) {
+    /**
+     * Writes a test of the call against a server which responds with each
+     * of the successful response's JSON examples, checking that they can be
+     * decoded. If the request body has a JSON example, the payload is
+     * unmarshalled from it and the server checks that the request body
+     * matches. Arguments are otherwise left zero, so the test is skipped if
+     * they fail validation. Nothing is written if there are no examples.
+     */
+    private createExampleTest(method: api10.Method, fn: Func, testFile: () => File) {
+        const api = method.ownerApi();
+        const res = getSuccessfulResponse(method);
+        const resBody = res && res.body()[0];
+        const reqBody = method.body()[0];
+        const isJSON = (body: api10.TypeDeclaration) => body && !isBinaryBody(api, body)
+            && (/[\/+]json$/).test(mediaTypeOf(api, body));
+
+        const responses = isJSON(resBody) ? jsonExamples(resBody) : [];
+        const request = isJSON(reqBody) ? jsonExamples(reqBody)[0] : null;
+        if (!responses.length && !request) {
+            return;
+        }
+
+        const file = testFile();
+        file.import("context").import("io").import("net/http").import("net/http/httptest")
+            .import("errors").import("testing");
+
+        const verb = method.method().toUpperCase();
+        const status = res ? res.code().value() : "200";
+        const protocols = protocolsOf(method.protocols().length ? method.protocols() : api.protocols());
+        const tls = protocols.length > 0 && protocols.indexOf("http") === -1;
+        const cases = responses.length ? responses : [{ name: request.name, value: "" }];
+        const test = file.func(`Test${fn.getName()}Examples`);
+        test.arg("t", "*testing.T");
+
+        test.write(`examples := []struct{ name, body string }{\n`);
+        cases.forEach(example => test.write(`{${JSON.stringify(example.name)}, ${goString(example.value)}},\n`));
+        test.write(`}\n\n`);
+        if (request) {
+            test.write(`const requestExample = ${goString(request.value)}\n\n`);
+        }
+
+        const args = fn.getArgs().filter(arg => !arg.variadic).map(arg => {
+            if (arg.argName === "ctx") {
+                return "context.Background()";
+            }
+
+            importPackagesOf(file, arg.argType);
+            return arg.argName;
+        });
+
+        test.write(`
+            for _, example := range examples {
+                t.Run(example.name, func(t *testing.T) {
+                    server := httptest.${tls ? "NewTLSServer" : "NewServer"}(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                        if r.Method != "${verb}" {
+                            t.Errorf("method = %s, want ${verb}", r.Method)
+                        }
+        `);
+        if (request) {
+            test.write(`
+                body, _ := io.ReadAll(r.Body)
+                if !jsonEqual(body, []byte(requestExample)) {
+                    t.Errorf("request body = %s, want %s", body, requestExample)
+                }
+            `);
+        }
+        test.write(`
+                w.Header().Set("Content-Type", ${JSON.stringify(resBody ? mediaTypeOf(api, resBody) : "application/json")})
+                w.WriteHeader(${status})
+                io.WriteString(w, example.body)
+            }))
+            defer server.Close()
+
+        `);
+
+        fn.getArgs().filter(arg => !arg.variadic && arg.argName !== "ctx").forEach(arg => {
+            test.write(`var ${arg.argName} ${arg.argType}\n`);
+        });
+        if (request && fn.getArg("payload")) {
+            file.import("encoding/json");
+            test.write(`
+                if err := json.Unmarshal([]byte(requestExample), &payload); err != nil {
+                    t.Fatal(err)
+                }
+            `);
+        }
+
+        const results = fn.getReturns().slice(0, -1).map(() => "_").concat("err").join(", ");
+        test.write(`
+                    codec := &recordingCodec{Codec: JSONCodec}
+                    client := NewClient(server.URL, WithHTTPClient(server.Client()), WithCodec(codec))
+                    ${results} := client.${fn.getName()}(${args.join(", ")})
+                    var invalid *ValidationError
+                    if errors.As(err, &invalid) {
+                        t.Skipf("the zero arguments are invalid: %v", err)
+                    }
+                    if err != nil {
+                        t.Fatal(err)
+                    }
+        `);
+        if (request && fn.getArg("payload")) {
+            test.write(`
+                if codec.marshalled == 0 {
+                    t.Error("the request body wasn't marshalled with the client's codec")
+                }
+            `);
+        }
+        if (responses.length) {
+            test.write(`
+                if codec.unmarshalled == 0 {
+                    t.Error("the response body wasn't unmarshalled with the client's codec")
+                }
+            `);
+        }
+        test.write(`
+                })
+            }
+        `);
+
+        if (!file.module.getIdentifier("recordingCodec")) {
+            file.write("// recordingCodec is a Codec counting the bodies it marshals and unmarshals.\n");
+            file.struct("recordingCodec").composes("Codec")
+                .field("marshalled", "int")
+                .field("unmarshalled", "int");
+
+            const marshal = new Func("Marshal").methodOf("r *recordingCodec").returns("[]byte").returns("error");
+            marshal.arg("v", "interface{}");
+            marshal.write("r.marshalled++\nreturn r.Codec.Marshal(v)\n");
+            const unmarshal = new Func("Unmarshal").methodOf("r *recordingCodec").returns("error");
+            unmarshal.addArgs(new Arg("data", "[]byte"), new Arg("v", "interface{}"));
+            unmarshal.write("r.unmarshalled++\nreturn r.Codec.Unmarshal(data, v)\n");
+            const decoder = new Func("NewDecoder").methodOf("r *recordingCodec").returns("Decoder");
+            decoder.arg("body", "io.Reader");
+            decoder.write("r.unmarshalled++\nreturn r.Codec.NewDecoder(body)\n");
+            file.write(marshal);
+            file.write(unmarshal);
+            file.write(decoder);
+        }
+
+        if (request && !file.module.getIdentifier("jsonEqual")) {
+            file.import("encoding/json").import("reflect");
+            file.write("// jsonEqual reports whether a and b hold equal JSON values.\n");
+            const jsonEqual = file.func("jsonEqual").returns("bool");
+            jsonEqual.addArgs(new Arg("a", "[]byte"), new Arg("b", "[]byte"));
+            jsonEqual.write(`
+                var x, y interface{}
+                return json.Unmarshal(a, &x) == nil && json.Unmarshal(b, &y) == nil && reflect.DeepEqual(x, y)
+            `);
+        }
+    }
+
+    /**
+     * Writes an accessor on the receiver for each of the node's children,
+     * returning a sub-client with a method for each call on the resource,
+     * named with its verb. Calls whose verb doesn't identify them within
+     * the resource keep their full name.
+     */
+    private createSubClients(node: SubClient, receiver: string, client: string, file: File) {
+        Object.keys(node.children).sort().forEach(key => {
+            const child = node.children[key];
+            const type = `${child.name}Client`;
+
+            file.write(`// ${key} returns a client for the calls on the ${child.path} resource.\n`);
+            const accessor = new Func(key).methodOf(receiver).returns(`*${type}`);
+            accessor.write(`return &${type}{c: ${client}}\n`);
+            file.write(accessor);
+
+            file.write(`// ${type} makes the calls on the ${child.path} resource, sharing\n`);
+            file.write(`// the filters and HTTP client of the Client it was obtained from.\n`);
+            if (child.description) {
+                file.write(`//\n${markdownToDoc(child.description).map(line => `// ${line}`.trim()).join("\n")}\n`);
+            }
+            file.struct(type).field("c", "*Client");
+
+            const taken = child.calls.map(call => call.verb).concat(Object.keys(child.children));
+            child.calls.forEach(({ verb, fn }) => {
+                const unique = verb !== null && taken.filter(name => name === verb).length === 1;
+                const name = unique ? verb : fn.getName();
+                const method = new Func(name).methodOf(`s *${type}`);
+                method.addArgs(...fn.getArgs());
+                fn.getReturns().forEach(ret => method.returns(ret));
+                method.write(`return s.c.${fn.getName()}(${fn.getArgs()
+                    .map(a => a.argName + (a.variadic ? "..." : "")).join(", ")})\n`);
+
+                file.write(`// ${name} calls Client.${fn.getName()}.\n`);
+                file.write(method);
+            });
+
+            this.createSubClients(child, `s *${type}`, "s.c", file);
+        });
+    }
+
+    /**
+     * Writes the API interface implemented by the Client to the file, and a
+     * stub implementation of it to the stub file for use in tests. Methods
+     * are listed in the order of their names.
+     */
+    private createInterface(funcs: Array<Func>, file: File, stubFile: File) {
+        funcs = funcs.slice().sort((a, b) => a.getName() < b.getName() ? -1 : a.getName() > b.getName() ? 1 : 0);
+        file.write("// API is the set of calls offered by the Client. It can be used to\n");
+        file.write("// substitute the Client with StubAPI or another fake in tests.\n");
+        const iface = file.interfaceType("API");
+        funcs.forEach(fn => iface.method(fn.signature()));
+        file.write("var _ API = (*Client)(nil)\n\n");
+
+        stubFile.write("// StubAPI implements API by calling the function set for each method.\n");
+        stubFile.write("// Calling a method whose function is unset panics.\n");
+        const stub = stubFile.struct("StubAPI");
+        funcs.forEach(fn => {
+            const type = `func${fn.signature().slice(fn.getName().length)}`;
+            importPackagesOf(stubFile, type);
+            stub.field(`${fn.getName()}Func`, type);
+
+            const method = stubFile.func(fn.getName()).methodOf("s *StubAPI");
+            method.addArgs(...fn.getArgs());
+            fn.getReturns().forEach(type => method.returns(type));
+            method.write(`return s.${fn.getName()}Func(${fn.getArgs()
+                .map(a => a.argName + (a.variadic ? "..." : "")).join(", ")})\n`);
+        });
+        stubFile.write("var _ API = (*StubAPI)(nil)\n\n");
+    }
+
+    private createInfo(api: api10.Api, file: File) {
+        const product = [api.title(), api.version()]
+            .filter(part => !!part)
+            .map(part => part.replace(/[^a-z0-9.!#$%&'*+^_`|~-]+/ig, "-"))
+            .join("/");
+
+        file.write(`
+            // APIVersion is the version of the API this client was generated
+            // from.
+            const APIVersion = ${JSON.stringify(api.version() || "")}
+
+            // DefaultMediaType is the media type of bodies whose type the API
+            // doesn't declare.
+            const DefaultMediaType = ${JSON.stringify(api.mediaType().length ? api.mediaType()[0].value() : "application/json")}
+
+            // DefaultUserAgent identifies this client to the API. Install it
+            // with Client.SetUserAgent.
+            const DefaultUserAgent = ${JSON.stringify(product)}
+
+            // allowedProtocols are the protocols which the API may be called
+            // over, unless a method says otherwise. Any are allowed if empty.
+            var allowedProtocols = []string{${protocolsOf(api.protocols()).map(p => JSON.stringify(p)).join(", ")}}
+
+            // defaultOAuthScheme is the Authorization scheme used by UseOAuth.
+            const defaultOAuthScheme = "${hasSecurityScheme(api, "OAuth 2.0") ? "Bearer" : "OAuth"}"
+        `);
+    }
+
+    /**
+     * Writes the default base URL of clients, which is the API's base URI
+     * with its version and any parameter defaults substituted. If the base
+     * URI has parameters, also writes a BaseURIParameters struct to hold
+     * their values and a constructor substituting them.
+     */
+    private createBaseURI(api: api10.Api, file: File) {
+        const template = api.baseUri() ? api.baseUri().value() : "";
+        const declared = api.baseUriParameters();
+        const names = (template.match(/\{[^}]+\}/g) || []).map(param => param.slice(1, -1));
+        const params = names.map(name => {
+            const decl = declared.find(param => param.name() === name);
+            let fallback = decl && facet(decl, "default") !== null ? String(facet(decl, "default")) : null;
+            if (name === "version" && fallback === null && api.version()) {
+                fallback = api.version();
+            }
+
+            return { name, field: translatePropName(name), fallback };
+        });
+
+        let defaultURL = template;
+        params.forEach(param => {
+            defaultURL = defaultURL.replace(`{${param.name}}`, param.fallback);
+        });
+        if (params.some(param => param.fallback === null)) {
+            defaultURL = "";
+        }
+
+        file.write(`
+            // BaseURL is the API's base URI with its version and parameter
+            // defaults substituted, which clients created without a base URL
+            // send requests to. It's empty if a parameter has no default.
+            const BaseURL = ${JSON.stringify(defaultURL)}
+
+        `);
+
+        if (params.length === 0) {
+            return;
+        }
+
+        file.write(`// BaseURIParameters holds the values substituted into the API's base\n`);
+        file.write(`// URI, ${JSON.stringify(template)}.\n`);
+        const struct = file.struct("BaseURIParameters");
+        params.forEach(param => struct.field(param.field, "string"));
+
+        file.import("strings");
+        file.write(`// NewClientWithParameters creates a Client whose base URL is the API's\n`);
+        file.write(`// base URI with the parameters substituted. Empty parameters take their\n`);
+        file.write(`// default value, and it's an error if they have none.\n`);
+        const fn = file.func("NewClientWithParameters");
+        fn.arg("params", "BaseURIParameters");
+        fn.arg("opts", "Option").variadic = true;
+        fn.returns("*Client").returns("error");
+        params.forEach(param => {
+            fn.write(`if params.${param.field} == "" {\n`);
+            fn.write(param.fallback !== null
+                ? `params.${param.field} = ${JSON.stringify(param.fallback)}\n`
+                : `return nil, missingParam(${JSON.stringify(param.name)})\n`);
+            fn.write(`}\n`);
+        });
+        fn.write(`
+            baseURL := strings.NewReplacer(
+                ${params.map(param => `"{${param.name}}", params.${param.field},`).join("\n")}
+            ).Replace(${JSON.stringify(template)})
+
+            return NewClient(baseURL, opts...), nil
+        `);
+    }
+
+    private createSecurity(api: api10.Api, file: File) {
+        api.securitySchemes().forEach(scheme => {
+            const login = getPasswordLogin(scheme);
+            if (login) {
+                let uri = login.uri;
+                if (!(/^[a-z]+:\/\//i).test(uri)) {
+                    uri = "/" + uri.replace(/^\//, "");
+                }
+
+                const name = `Login${translatePropName(scheme.name())}`;
+                file.import("context");
+                file.write(`// ${name} logs in with the "${scheme.name()}" security scheme,\n`);
+                file.write(`// enabling cookies on the client to hold the session.\n`);
+                const fn = file.func(name);
+                fn.methodOf("c *Client").returns("error");
+                fn.arg("ctx", "context.Context");
+                fn.arg("username", "string");
+                fn.arg("password", "string");
+                fn.write(`
+                    return c.loginWithPassword(ctx, ${JSON.stringify(uri)}, ${login.form}, map[string]string{
+                        ${JSON.stringify(login.usernameField)}: username,
+                        ${JSON.stringify(login.passwordField)}: password,
+                    })
+                `);
                 return;
             }
 
-            generateStruct(file, api, type.name(), <api10.ObjectTypeDeclaration>type);
+            const prefix = translatePropName(scheme.name());
+            const name = `Use${prefix}Auth`;
+            const setup = (doc: string, args: Array<string>, call: string) => {
+                // Schemes named like "basic" would shadow the runtime's
+                // helpers, which do the same when they're the one called.
+                if (runtimeClientMethods().indexOf(name) !== -1) {
+                    if (!call.startsWith(`c.${name}(`)) {
+                        console.warn(`Not generating ${name} for the "${scheme.name()}" scheme, as the name is taken`);
+                    }
+                    return;
+                }
+
+                file.write(`// ${name} authenticates requests ${doc}.\n`);
+                const fn = file.func(name).methodOf("c *Client");
+                args.forEach(arg => fn.arg(arg, "string"));
+                fn.write(`${call}\n`);
+            };
+
+            switch (scheme.type()) {
+            case "OAuth 2.0":
+                this.createSchemeURIs(scheme, file, prefix, {
+                    authorizationUri: "AuthorizationURI",
+                    accessTokenUri: "AccessTokenURI",
+                });
+                setup(`with an access token from the "${scheme.name()}" OAuth 2.0 scheme`,
+                    ["token"], "c.UseBearerToken(token)");
+            break;
+            case "OAuth 1.0":
+                this.createSchemeURIs(scheme, file, prefix, {
+                    requestTokenUri: "RequestTokenURI",
+                    authorizationUri: "AuthorizationURI",
+                    tokenCredentialsUri: "TokenCredentialsURI",
+                });
+                setup(`by signing them following the "${scheme.name()}" OAuth 1.0 scheme`,
+                    ["consumerKey", "consumerSecret", "token", "tokenSecret"],
+                    "c.UseOAuth1(consumerKey, consumerSecret, token, tokenSecret)");
+            break;
+            case "Basic Authentication":
+                setup(`with the "${scheme.name()}" basic authentication scheme`,
+                    ["username", "password"], "c.UseBasicAuth(username, password)");
+            break;
+            case "Digest Authentication":
+                setup(`with the "${scheme.name()}" digest authentication scheme`,
+                    ["username", "password"], "c.UseDigestAuth(username, password)");
+            break;
+            default:
+                const key = getAPIKeyParam(scheme);
+                if (key) {
+                    setup(`with the "${scheme.name()}" API key`, ["key"],
+                        `c.UseAPIKey(${JSON.stringify(key.name)}, key, ${key.in})`);
+                }
+            }
+        });
+    }
+
+    /**
+     * Writes constants for the URIs in a security scheme's settings, named
+     * after the scheme with the suffix given for each setting.
+     */
+    private createSchemeURIs(scheme: api10.AbstractSecurityScheme, file: File,
+        prefix: string, suffixes: { [setting: string]: string }) {
+
+        const settings = getSchemeSettings(scheme);
+        Object.keys(suffixes)
+            .filter(setting => typeof settings[setting] === "string")
+            .forEach(setting => {
+                file.write(`// ${prefix}${suffixes[setting]} is the ${setting} of the "${scheme.name()}" scheme.\n`);
+                file.write(`const ${prefix}${suffixes[setting]} = ${JSON.stringify(settings[setting])}\n\n`);
+            });
+    }
+
+    private createModels(api: api10.Api, file: File) {
+        // Types are written in the order of their Go names, so that moving
+        // declarations in the definition doesn't move them in the file.
+        const types = declaredTypes(api).map((decl, i) => ({ decl, i, name: fixCaps(goTypeName(decl.name)) }))
+            .sort((a, b) => a.name < b.name ? -1 : a.name > b.name ? 1 : a.i - b.i)
+            .map(({ decl }) => decl);
+        types.forEach(({ name: ramlName, ns, decl: type }) => {
+            const name = fixCaps(goTypeName(ramlName));
+            const expr = type.type()[0] || "string";
+            const doc = file.module.getIdentifier(name)
+                ? ""
+                : docComment(name, descriptionOf(type), `${name} is the ${ramlName} type.`);
+            file.write(doc);
+            if (expr.indexOf("|") !== -1) {
+                file.write(doc ? "//\n" : "");
+                generateUnion(file, api, name, expr, ns);
+            } else if (isObjectType(type)) {
+                generateStruct(file, api, name, <api10.ObjectTypeDeclaration>type, ns);
+            } else if (enumValues(type)) {
+                generateEnum(file, type, name, "");
+            } else {
+                const goType = translateType(type, ns).replace(/^\*/, "");
+                importPackagesOf(file, goType);
+                file.write(`type ${name} ${goType}\n\n`);
+            }
         });
     }
 
-    generate(api: api10.Api, output: string): Promise<void> {
+    generate(api: api10.Api, output: string, includes: IncludeResolver, options: GenerateOptions): Promise<void> {
+        const packageName = options.packageName || goAnnotation(api, "package") || "client";
+        if (!(/^[a-z_][a-z0-9_]*$/i).test(packageName) || keywords.indexOf(packageName) !== -1) {
+            return Promise.reject(new Error(`Invalid Go package name "${packageName}"`));
+        }
+
         const todo = new Todo();
         todo.start("Generating Go code");
+        warnUnknownAnnotations(api);
 
-        const module = new Module(output, "client");
-        module.include(path.join(__dirname, "../../../src/targets/go/bootstrap.go"));
+        const module = new Module(output, packageName);
+        runtimeFiles.forEach(file => {
+            module.include(path.join(runtimeDir, file));
+        });
+        const info = module.file("api.go");
+        this.createInfo(api, info);
+        this.createBaseURI(api, info);
+        this.createSecurity(api, info);
         this.createModels(api, module.file("models.go"));
-        this.createEndpoints(api, module.file("endpoints.go"));
+        const endpoints = module.file("endpoints.go");
+        const funcs = this.createEndpoints(api, endpoints, includes);
+        this.createInterface(funcs, endpoints, module.file("stub.go"));
 
-        todo.start("Running gofmt");
+        todo.start("Formatting Go code");
 
         return module.save().then(() => todo.finish());
     }
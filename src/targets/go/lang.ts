@@ -3,19 +3,47 @@ import * as child from "child_process";
 import * as path from "path";
 import * as fs from "fs";
 
+let formatter: string;
+
+/**
+ * The comment which generated files start with.
+ */
+const generatedHeader = "// AUTOGENERATED FILE, DO NOT EDIT";
+
+/**
+ * Returns the command used to format Go source: goimports, which also adds
+ * missing imports and removes unused ones, if it's installed, or gofmt.
+ */
+function getFormatter(): string {
+    if (!formatter) {
+        formatter = child.spawnSync("goimports", ["-h"]).error ? "gofmt" : "goimports";
+    }
+
+    return formatter;
+}
+
+/**
+ * Formats the Go source written to the input by fn, saving it to the target
+ * file. The file is only written if the source parses; otherwise the
+ * promise is rejected with a GofmtError holding the formatter's errors.
+ */
 function fmtPipe(target: string, fn: (input: NodeJS.WritableStream) => void): Promise<void> {
-    const fmt = child.spawn("gofmt");
-    fmt.stderr.pipe(process.stderr);
-    fmt.stdout.pipe(fs.createWriteStream(target));
+    const fmt = child.spawn(getFormatter());
+    const stdout = new Array<Buffer>();
+    const stderr = new Array<Buffer>();
+    fmt.stdout.on("data", (data: Buffer) => stdout.push(data));
+    fmt.stderr.on("data", (data: Buffer) => stderr.push(data));
     fn(fmt.stdin);
 
     return new Promise<void>((resolve, reject) => {
-        fmt.once("exit", (status: number) => {
-            if (status === 0) {
-                resolve();
-            } else {
-                reject(new GofmtError(`Error formatting file, exit code ${status}`));
+        fmt.once("close", (status: number) => {
+            if (status !== 0) {
+                const errors = Buffer.concat(stderr).toString().replace(/<standard input>/g, target);
+                reject(new GofmtError(`Error formatting ${target}, exit code ${status}:\n${errors}`));
+                return;
             }
+
+            fs.writeFile(target, Buffer.concat(stdout), err => err ? reject(err) : resolve());
         });
 
         fmt.once("error", (err: any) => {
@@ -30,6 +58,7 @@ function fmtPipe(target: string, fn: (input: NodeJS.WritableStream) => void): Pr
 export class Module {
 
     private files = new Array<{ save(): Promise<void> }>();
+    private paths = new Array<string>();
     private identifiers : { [name: string]: any } = {};
 
     /**
@@ -49,32 +78,53 @@ export class Module {
     file(name: string): File {
         const f = new File(path.join(this.dir, name), this);
         this.files.push(f)
+        this.paths.push(path.join(this.dir, name));
         return f;
     }
 
     /**
-     * Queues a filed to be copied over into the target directory.
+     * Queues a filed to be copied over into the target directory. Its
+     * package clause is replaced with the module's.
      */
     include(filePath: string) {
         const target = path.join(this.dir, path.basename(filePath));
-        const rs = fs.createReadStream(filePath);
+        this.paths.push(target);
+        const source = fs.readFileSync(filePath, "utf8")
+            .replace(/^package \w+$/m, `package ${this.name}`);
 
         this.files.push({
             save(): Promise<void> {
-                return fmtPipe(target, s => rs.pipe(s));
+                return fmtPipe(target, s => {
+                    s.write(source);
+                    s.end();
+                });
             }
         });
     }
 
     /**
      * Save signals that you are finished operations on the module and saves
-     * out all files.
+     * out all files. Generated files left in the directory which the module
+     * no longer has, such as those of removed resources, are deleted.
      */
     save(): Promise<void> {
+        this.removeStale();
         return Promise.all(this.files.map(f => f.save()))
         .then(() => {});
     }
 
+    private removeStale() {
+        if (!fs.existsSync(this.dir)) {
+            return;
+        }
+
+        fs.readdirSync(this.dir)
+            .map(name => path.join(this.dir, name))
+            .filter(file => (/\.go$/).test(file) && this.paths.indexOf(file) === -1)
+            .filter(file => fs.readFileSync(file, "utf8").indexOf(generatedHeader) === 0)
+            .forEach(file => fs.unlinkSync(file));
+    }
+
     /**
      * Records a new indentifier in the module. This is called when functions,
      * structs, etc. are created in Files.
@@ -110,7 +160,7 @@ export class File {
     private imports = new Imports();
 
     constructor(private path: string, public module: Module) {
-        this.write(`// AUTOGENERATED FILE, DO NOT EDIT\n\npackage ${module.getName()}\n\n`);
+        this.write(`${generatedHeader}\n\npackage ${module.getName()}\n\n`);
         this.content.write(this.imports);
     }
 
@@ -131,6 +181,24 @@ export class File {
         return s;
     }
 
+    /**
+     * Creates a new enum type appended to this file.
+     */
+    enumType(name: string, base: string, values: Array<string | number>): Enum {
+        const e = new Enum(name, base, values);
+        this.addAndWrite(name, e);
+        return e;
+    }
+
+    /**
+     * Creates a new interface appended to this file.
+     */
+    interfaceType(name: string): Interface {
+        const i = new Interface(name);
+        this.addAndWrite(name, i);
+        return i;
+    }
+
     /**
      * Creates a new func appended to this file.
      */
@@ -170,18 +238,22 @@ export class File {
 export class Struct implements Stringable {
 
     private fields : { [field: string]: string } = {};
+    private docs : { [field: string]: string } = {};
     private parents : { [name: string]: void } = {};
 
     constructor(private name: string) {}
 
     /**
-     * Adds a new field to the struct.
+     * Adds a new field to the struct, preceded by the doc comment if given.
      */
-    field(name: string, type: string, annotation: string=null): Struct {
+    field(name: string, type: string, annotation: string=null, doc: string=null): Struct {
         this.fields[name] = type;
         if (annotation) {
             this.fields[name] += " `" + annotation + "`";
         }
+        if (doc) {
+            this.docs[name] = doc;
+        }
 
         return this;
     }
@@ -207,6 +279,7 @@ export class Struct implements Stringable {
             out += `\t${parent}\n`;
         });
         Object.keys(this.fields).forEach(name => {
+            out += this.docs[name] || "";
             out += `\t${name} ${this.fields[name]}\n`;
         });
         out += `}\n\n`;
@@ -215,6 +288,118 @@ export class Struct implements Stringable {
     }
 }
 
+/**
+ * An Enum builds a named Go type with a constant for each of its values,
+ * and an IsValid method reporting whether a value is one of them.
+ */
+export class Enum implements Stringable {
+
+    constructor(
+        private name: string,
+        private base: string,
+        private values: Array<string | number>
+    ) {}
+
+    /**
+     * Returns whether the enum has exactly the given values.
+     */
+    sameValues(values: Array<string | number>): boolean {
+        return values.length === this.values.length
+            && values.every((v, i) => v === this.values[i]);
+    }
+
+    /**
+     * Returns the name of the constant for a value. Characters which can't
+     * appear in identifiers are dropped, capitalizing the next character,
+     * so "in-progress" becomes "InProgress". Numbers keep their sign and
+     * decimal point, so 1.5 becomes "1_5" and -1 becomes "Minus1", rather
+     * than colliding with 15 and 1.
+     */
+    constName(value: string | number): string {
+        if (typeof value === "number") {
+            return this.name + String(value).replace(/-/g, "Minus").replace(/\./g, "_").replace(/\+/g, "");
+        }
+
+        const suffix = String(value)
+            .split(/[^a-z0-9]+/i)
+            .map(part => part.slice(0, 1).toUpperCase() + part.slice(1))
+            .join("");
+
+        return this.name + (suffix || "Empty");
+    }
+
+    /**
+     * Returns the names of the constants for the values, in order. Values
+     * whose names collide, like "on-hold" and "on_hold", are told apart
+     * with a numeric suffix, becoming StatusOnHold and StatusOnHold2.
+     */
+    constNames(): Array<string> {
+        const natural = this.values.map(v => this.constName(v));
+        const names = new Array<string>();
+        natural.forEach(base => {
+            // Suffixed names mustn't take another value's own name either.
+            let name = base;
+            let n = 1;
+            while (names.indexOf(name) !== -1 || (name !== base && natural.indexOf(name) !== -1)) {
+                name = `${base}${++n}`;
+            }
+            names.push(name);
+        });
+
+        return names;
+    }
+
+    toString(): string {
+        const literal = (v: string | number) => typeof v === "number" && this.base !== "string"
+            ? String(v)
+            : JSON.stringify(String(v));
+
+        const names = this.constNames();
+        let out = `type ${this.name} ${this.base}\n\n`;
+        out += `const (\n`;
+        this.values.forEach((v, i) => {
+            out += `\t${names[i]} ${this.name} = ${literal(v)}\n`;
+        });
+        out += `)\n\n`;
+
+        out += `// IsValid returns whether the value is one of the known ${this.name} values.\n`;
+        out += `func (v ${this.name}) IsValid() bool {\n\tswitch v {\n\tcase `;
+        out += names.join(", ");
+        out += `:\n\t\treturn true\n\t}\n\n\treturn false\n}\n\n`;
+
+        return out;
+    }
+}
+
+/**
+ * An Interface is a builder class to build interface definitions in Go.
+ */
+export class Interface implements Stringable {
+
+    private methods = new Array<string>();
+
+    constructor(private name: string) {}
+
+    /**
+     * Adds a method to the interface, given its signature, as returned by
+     * Func.signature().
+     */
+    method(signature: string): Interface {
+        this.methods.push(signature);
+        return this;
+    }
+
+    toString(): string {
+        let out = `type ${this.name} interface {\n`;
+        this.methods.forEach(m => {
+            out += `\t${m}\n`;
+        });
+        out += `}\n\n`;
+
+        return out;
+    }
+}
+
 /**
  * Imports is a builder for the "import" declaration at the top of Go files.
  */
@@ -246,7 +431,7 @@ class Imports implements Stringable {
 
         const count = stdlib.length + external.length;
         if (count === 0) return "";
-        if (count === 1) return `import ${stdlib.concat(external).join()}`;
+        if (count === 1) return `import "${stdlib.concat(external).join()}"\n\n`;
 
         let out = stdlib.map(i => `\t"${i}"`).join("\n")
             + "\n" + external.map(i => `\t"${i}"`).join("\n");
@@ -299,6 +484,33 @@ export class Func implements Stringable {
         return this.args.find(a => a.argName === name);
     }
 
+    /**
+     * Returns the function's arguments.
+     */
+    getArgs(): Array<Arg> {
+        return this.args;
+    }
+
+    /**
+     * Returns the function's return types.
+     */
+    getReturns(): Array<string> {
+        return this.returnsTypes;
+    }
+
+    /**
+     * Returns the signature of the function, without the "func" keyword or
+     * method receiver, as it would appear in an interface or func type.
+     */
+    signature(): string {
+        let out = `${this.name}(${this.args.map(a => a.toString()).join(", ")})`;
+        if (this.returnsTypes.length) {
+            out += ` (${this.returnsTypes.join(", ")})`;
+        }
+
+        return out;
+    }
+
     /**
      * Sets this argument to be a method of some struct type.
      */
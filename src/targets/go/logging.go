@@ -0,0 +1,39 @@
+package client
+
+import (
+	"net/http"
+	"time"
+)
+
+// LogFunc receives the outcome of a request. Exactly one of res and err is
+// non-nil. The elapsed duration covers only the HTTP round trip, not the
+// time spent in other filters.
+type LogFunc func(req *http.Request, res *http.Response, elapsed time.Duration, err error)
+
+// LoggingFilter passes every request the client sends to a LogFunc once it
+// completes or fails. It does not read the response body, so the caller
+// still receives it intact.
+type LoggingFilter struct{ fn LogFunc }
+
+var _ Filter = new(LoggingFilter)
+
+// NewLoggingFilter creates a LoggingFilter which reports to fn.
+func NewLoggingFilter(fn LogFunc) *LoggingFilter {
+	return &LoggingFilter{fn: fn}
+}
+
+func (l *LoggingFilter) Before(req *http.Request) error { return nil }
+
+func (l *LoggingFilter) After(res *http.Response) {
+	ex := exchangeFrom(res.Request.Context())
+	req := ex.req
+	if req == nil {
+		req = res.Request
+	}
+
+	l.fn(req, res, ex.elapsed, nil)
+}
+
+func (l *LoggingFilter) AfterError(req *http.Request, err error) {
+	l.fn(req, nil, exchangeFrom(req.Context()).elapsed, err)
+}
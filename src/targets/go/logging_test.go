@@ -0,0 +1,68 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLoggingFilter(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		io.WriteString(w, "body")
+	})
+
+	type entry struct {
+		req     *http.Request
+		res     *http.Response
+		elapsed time.Duration
+		err     error
+	}
+	var logged []entry
+	c := NewClient(srv.URL)
+	c.UseLogger(func(req *http.Request, res *http.Response, elapsed time.Duration, err error) {
+		logged = append(logged, entry{req, res, elapsed, err})
+	})
+	c.AddFilter(&testFilter{before: func(req *http.Request) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}})
+
+	res, err := get(t, c, "/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readAll(t, res); body != "body" {
+		t.Errorf("got body %q after logging, want it intact", body)
+	}
+
+	if len(logged) != 1 {
+		t.Fatalf("logged %d times, want once", len(logged))
+	}
+	e := logged[0]
+	if e.req.URL.Path != "/users" || e.res != res || e.err != nil {
+		t.Errorf("logged (%v, %v, %v), want the request and its response", e.req.URL, e.res, e.err)
+	}
+	if e.elapsed < 20*time.Millisecond || e.elapsed >= 100*time.Millisecond {
+		t.Errorf("logged elapsed %v, want the round trip alone", e.elapsed)
+	}
+}
+
+func TestLoggingFilterErrors(t *testing.T) {
+	refused := errors.New("connection refused")
+	c := NewClient("https://api.example.com", WithTransport(failingTransport{refused}))
+
+	var logged error
+	c.UseLogger(func(req *http.Request, res *http.Response, elapsed time.Duration, err error) {
+		if res != nil {
+			t.Error("logged a response to a failed request")
+		}
+		logged = err
+	})
+
+	if _, err := get(t, c, "/"); err == nil || logged != err {
+		t.Errorf("logged %v, want the call's error %v", logged, err)
+	}
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"net/http"
+	"time"
+)
+
+// A MetricsSink receives a record of every request sent by a client using
+// a MetricsFilter. The route is the endpoint's RAML route template, such as
+// "/users/{userId}", rather than the concrete path, which keeps it suitable
+// for use as a metric label. The status is zero if no response was received.
+type MetricsSink interface {
+	RecordRequest(method, route string, status int, d time.Duration)
+}
+
+// MetricsFilter reports the method, route, status and round trip duration
+// of requests to a MetricsSink. Requests which weren't made by a generated
+// method, and so lack a route template, are reported with their path.
+type MetricsFilter struct{ sink MetricsSink }
+
+var _ Filter = new(MetricsFilter)
+
+// NewMetricsFilter creates a MetricsFilter reporting to the sink.
+func NewMetricsFilter(sink MetricsSink) *MetricsFilter {
+	return &MetricsFilter{sink: sink}
+}
+
+func (m *MetricsFilter) Before(req *http.Request) error { return nil }
+
+func (m *MetricsFilter) After(res *http.Response) {
+	ex := exchangeFrom(res.Request.Context())
+	req := ex.req
+	if req == nil {
+		req = res.Request
+	}
+
+	m.sink.RecordRequest(req.Method, metricsRoute(req), res.StatusCode, ex.elapsed)
+}
+
+func (m *MetricsFilter) AfterError(req *http.Request, err error) {
+	m.sink.RecordRequest(req.Method, metricsRoute(req), 0, exchangeFrom(req.Context()).elapsed)
+}
+
+func metricsRoute(req *http.Request) string {
+	if route := routeFrom(req.Context()); route != "" {
+		return route
+	}
+
+	return req.URL.Path
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recordingSink is a MetricsSink recording the requests it's given, without
+// their durations.
+type recordingSink struct{ records []string }
+
+func (s *recordingSink) RecordRequest(method, route string, status int, d time.Duration) {
+	if d < 0 {
+		panic("negative duration")
+	}
+	s.records = append(s.records, fmt.Sprintf("%s %s %d", method, route, status))
+}
+
+func TestMetricsFilter(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			w.WriteHeader(http.StatusCreated)
+		}
+	})
+
+	sink := &recordingSink{}
+	c := NewClient(srv.URL)
+	c.UseMetrics(sink)
+
+	req, _ := http.NewRequest("POST", "/users/42/posts", nil)
+	res, err := c.do(withRoute(context.Background(), "/users/{userId}/posts"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	res, err = get(t, c, "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	c = NewClient("https://api.example.com", WithTransport(failingTransport{errors.New("refused")}))
+	c.UseMetrics(sink)
+	req, _ = http.NewRequest("GET", "/users/42", nil)
+	c.do(withRoute(context.Background(), "/users/{userId}"), req)
+
+	want := []string{"POST /users/{userId}/posts 201", "GET /health 200", "GET /users/{userId} 0"}
+	if !reflect.DeepEqual(sink.records, want) {
+		t.Errorf("recorded %q, want %q", sink.records, want)
+	}
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// FilePart is a file uploaded in a multipart/form-data request body.
+type FilePart struct {
+	// Name is the file name reported to the server.
+	Name string
+	// ContentType is the media type of the file. It defaults to
+	// application/octet-stream.
+	ContentType string
+	// Content is streamed into the request body as it is sent.
+	Content io.Reader
+}
+
+// formPart is a field of a multipart/form-data body, holding either a
+// value or a file.
+type formPart struct {
+	name  string
+	value string
+	file  *FilePart
+}
+
+// multipartBody streams a multipart/form-data body built from the parts,
+// which is written as it is read, so files are never held in memory. It
+// returns the body along with its Content-Type, including the boundary.
+func multipartBody(parts []formPart) (io.ReadCloser, string) {
+	pr, pw := io.Pipe()
+	body := &multipartReader{pr: pr, pw: pw, mw: multipart.NewWriter(pw), parts: parts}
+	return body, body.mw.FormDataContentType()
+}
+
+// multipartReader writes its parts into a pipe from a goroutine, which is
+// started on the first read so that nothing is leaked if the body is never
+// sent. Closing the reader stops the goroutine.
+type multipartReader struct {
+	once  sync.Once
+	pr    *io.PipeReader
+	pw    *io.PipeWriter
+	mw    *multipart.Writer
+	parts []formPart
+}
+
+func (m *multipartReader) Read(p []byte) (int, error) {
+	m.once.Do(func() {
+		go func() { m.pw.CloseWithError(m.write()) }()
+	})
+
+	return m.pr.Read(p)
+}
+
+func (m *multipartReader) Close() error {
+	return m.pr.Close()
+}
+
+// write writes each part followed by the closing boundary.
+func (m *multipartReader) write() error {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+	for _, part := range m.parts {
+		if part.file == nil {
+			if err := m.mw.WriteField(part.name, part.value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		contentType := part.file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quote.Replace(part.name), quote.Replace(part.file.Name)))
+		h.Set("Content-Type", contentType)
+
+		w, err := m.mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if part.file.Content != nil {
+			if _, err := io.Copy(w, part.file.Content); err != nil {
+				return err
+			}
+		}
+	}
+
+	return m.mw.Close()
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMultipartBody(t *testing.T) {
+	body, contentType := multipartBody([]formPart{
+		{name: "title", value: "Holiday"},
+		{name: "photo", file: &FilePart{Name: `b"e\ach.jpg`, ContentType: "image/jpeg", Content: strings.NewReader("JPEG")}},
+		{name: "notes", file: &FilePart{Name: "notes.txt", Content: strings.NewReader("sunny")}},
+	})
+	defer body.Close()
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		t.Fatalf("got Content-Type %q, want multipart/form-data with a boundary", contentType)
+	}
+
+	r := multipart.NewReader(body, params["boundary"])
+	for _, want := range []struct {
+		name, file, contentType, content string
+	}{
+		{"title", "", "", "Holiday"},
+		{"photo", `b"e\ach.jpg`, "image/jpeg", "JPEG"},
+		{"notes", "notes.txt", "application/octet-stream", "sunny"},
+	} {
+		part, err := r.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(part)
+		if part.FormName() != want.name || part.FileName() != want.file || string(content) != want.content {
+			t.Errorf("got part %q with file %q and content %q, want %+v",
+				part.FormName(), part.FileName(), content, want)
+		}
+		if want.file != "" && part.Header.Get("Content-Type") != want.contentType {
+			t.Errorf("part %q has Content-Type %q, want %q", want.name, part.Header.Get("Content-Type"), want.contentType)
+		}
+	}
+	if _, err := r.NextPart(); err != io.EOF {
+		t.Errorf("got %v after the last part, want io.EOF", err)
+	}
+}
+
+// endlessFile is a file of endless content, counting the reads of it.
+type endlessFile struct {
+	reads int32
+}
+
+func (f *endlessFile) Read(p []byte) (int, error) {
+	atomic.AddInt32(&f.reads, 1)
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
+
+func TestMultipartBodyStreamsFiles(t *testing.T) {
+	file := new(endlessFile)
+	body, _ := multipartBody([]formPart{{name: "file", file: &FilePart{Name: "big.bin", Content: file}}})
+
+	// The file is only read as the body is, so the body can be sent
+	// without holding the file in memory.
+	buf := make([]byte, 64<<10)
+	for i := 0; i < 4; i++ {
+		if _, err := io.ReadFull(body, buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := atomic.LoadInt32(&file.reads); n > 16 {
+		t.Errorf("read the file %d times for 256KB of body", n)
+	}
+
+	// Closing the body stops reading the file.
+	body.Close()
+	time.Sleep(20 * time.Millisecond)
+	before := atomic.LoadInt32(&file.reads)
+	time.Sleep(20 * time.Millisecond)
+	if after := atomic.LoadInt32(&file.reads); after != before {
+		t.Errorf("read the file %d more times after closing the body", after-before)
+	}
+}
+
+func TestMultipartBodyCloseBeforeRead(t *testing.T) {
+	body, _ := multipartBody([]formPart{{name: "a", value: "b"}})
+	if err := body.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := body.Read(make([]byte, 1)); err == nil {
+		t.Error("read from a closed body")
+	}
+}
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OAuth1Filter signs requests following OAuth 1.0a (RFC 5849) using
+// HMAC-SHA1, sending the signature and protocol parameters in the
+// `Authorization` header. Form-encoded request bodies are included in the
+// signature, which requires them to be buffered.
+type OAuth1Filter struct {
+	consumerKey, consumerSecret string
+	token, tokenSecret          string
+
+	// now and nonce may be replaced to produce deterministic signatures.
+	now   func() time.Time
+	nonce func() string
+}
+
+var _ Filter = new(OAuth1Filter)
+
+// NewOAuth1Filter creates an OAuth1Filter. The token and its secret may be
+// empty, for instance when requesting temporary credentials.
+func NewOAuth1Filter(consumerKey, consumerSecret, token, tokenSecret string) *OAuth1Filter {
+	return &OAuth1Filter{
+		consumerKey:    consumerKey,
+		consumerSecret: consumerSecret,
+		token:          token,
+		tokenSecret:    tokenSecret,
+		now:            time.Now,
+		nonce:          oauthNonce,
+	}
+}
+
+func (o *OAuth1Filter) Before(req *http.Request) error {
+	if !RequiresAuth(req) {
+		return nil
+	}
+
+	params := map[string]string{
+		"oauth_consumer_key":     o.consumerKey,
+		"oauth_nonce":            o.nonce(),
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(o.now().Unix(), 10),
+		"oauth_version":          "1.0",
+	}
+	if o.token != "" {
+		params["oauth_token"] = o.token
+	}
+
+	form, err := oauthFormParams(req)
+	if err != nil {
+		return err
+	}
+
+	base := oauthBaseString(req, params, form)
+	params["oauth_signature"] = o.sign(base)
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = oauthEscape(k) + `="` + oauthEscape(params[k]) + `"`
+	}
+	req.Header.Set("Authorization", "OAuth "+strings.Join(parts, ", "))
+
+	return nil
+}
+
+func (o *OAuth1Filter) After(res *http.Response)                {}
+func (o *OAuth1Filter) AfterError(req *http.Request, err error) {}
+
+// sign returns the base64 HMAC-SHA1 signature of the base string.
+func (o *OAuth1Filter) sign(base string) string {
+	mac := hmac.New(sha1.New, []byte(oauthEscape(o.consumerSecret)+"&"+oauthEscape(o.tokenSecret)))
+	mac.Write([]byte(base))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// oauthBaseString builds the signature base string from the request method,
+// its base string URI, and the normalized request parameters, which are the
+// protocol parameters plus those from the query string and form body.
+func oauthBaseString(req *http.Request, params map[string]string, form url.Values) string {
+	type pair struct{ k, v string }
+	var pairs []pair
+	for k, v := range params {
+		pairs = append(pairs, pair{oauthEscape(k), oauthEscape(v)})
+	}
+	for _, values := range []url.Values{req.URL.Query(), form} {
+		for k, vs := range values {
+			for _, v := range vs {
+				pairs = append(pairs, pair{oauthEscape(k), oauthEscape(v)})
+			}
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].k != pairs[j].k {
+			return pairs[i].k < pairs[j].k
+		}
+		return pairs[i].v < pairs[j].v
+	})
+
+	normalized := make([]string, len(pairs))
+	for i, p := range pairs {
+		normalized[i] = p.k + "=" + p.v
+	}
+
+	return strings.ToUpper(req.Method) + "&" +
+		oauthEscape(oauthBaseURI(req.URL)) + "&" +
+		oauthEscape(strings.Join(normalized, "&"))
+}
+
+// oauthBaseURI returns the URL without its query or fragment, with the
+// scheme and host lowercased and default ports removed.
+func oauthBaseURI(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Host)
+	if (scheme == "http" && strings.HasSuffix(host, ":80")) ||
+		(scheme == "https" && strings.HasSuffix(host, ":443")) {
+		host = host[:strings.LastIndex(host, ":")]
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	return scheme + "://" + host + path
+}
+
+// oauthFormParams returns the parameters of a form-encoded request body,
+// which are covered by the signature, leaving the body readable.
+func oauthFormParams(req *http.Request) (url.Values, error) {
+	if req.Body == nil || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return nil, nil
+	}
+
+	if err := bufferBody(req); err != nil {
+		return nil, err
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return url.ParseQuery(string(data))
+}
+
+// oauthEscape percent-encodes the string as required by RFC 5849, leaving
+// only unreserved characters unescaped.
+func oauthEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			b.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+		}
+	}
+
+	return b.String()
+}
+
+// oauthNonce returns a random nonce.
+func oauthNonce() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
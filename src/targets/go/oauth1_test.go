@@ -0,0 +1,88 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOAuth1Signature(t *testing.T) {
+	// The example of Appendix A.5 of the OAuth 1.0 specification, which
+	// RFC 5849 supersedes without changing how signatures are made.
+	f := NewOAuth1Filter("dpf43f3p2l4k3l03", "kd94hf93k423kf44", "nnch734d00sl2jdk", "pfkkdhi9sl3r4s00")
+	f.now = func() time.Time { return time.Unix(1191242096, 0) }
+	f.nonce = func() string { return "kllo9940pd9333jh" }
+
+	req, _ := http.NewRequest("GET", "http://photos.example.net/photos?file=vacation.jpg&size=original", nil)
+	if err := f.Before(req); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `OAuth oauth_consumer_key="dpf43f3p2l4k3l03", oauth_nonce="kllo9940pd9333jh", ` +
+		`oauth_signature="tR3%2BTy81lMeYAr%2FFid0kMTYa%2FWM%3D", oauth_signature_method="HMAC-SHA1", ` +
+		`oauth_timestamp="1191242096", oauth_token="nnch734d00sl2jdk", oauth_version="1.0"`
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("got Authorization\n\t%s\nwant\n\t%s", got, want)
+	}
+}
+
+func TestOAuth1BaseString(t *testing.T) {
+	// The example of section 3.4.1.1 of RFC 5849, whose parameters come
+	// from the query string, the form body and the protocol parameters.
+	req, _ := http.NewRequest("POST", "http://example.com/request?b5=%3D%253D&a3=a&c%40=&a2=r%20b", strings.NewReader("c2&a3=2+q"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	params := map[string]string{
+		"oauth_consumer_key":     "9djdj82h48djs9d2",
+		"oauth_token":            "kkk9d7dh3k39sjv7",
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        "137131201",
+		"oauth_nonce":            "7d8f3e4a",
+	}
+
+	form, err := oauthFormParams(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "POST&http%3A%2F%2Fexample.com%2Frequest&a2%3Dr%2520b%26a3%3D2%2520q" +
+		"%26a3%3Da%26b5%3D%253D%25253D%26c%2540%3D%26c2%3D%26oauth_consumer_" +
+		"key%3D9djdj82h48djs9d2%26oauth_nonce%3D7d8f3e4a%26oauth_signature_m" +
+		"ethod%3DHMAC-SHA1%26oauth_timestamp%3D137131201%26oauth_token%3Dkkk" +
+		"9d7dh3k39sjv7"
+	if got := oauthBaseString(req, params, form); got != want {
+		t.Errorf("got base string\n\t%s\nwant\n\t%s", got, want)
+	}
+
+	// The body is still sent in full.
+	if body, _ := io.ReadAll(req.Body); string(body) != "c2&a3=2+q" {
+		t.Errorf("got body %q after signing it, want it intact", body)
+	}
+}
+
+func TestOAuth1BaseURI(t *testing.T) {
+	for in, want := range map[string]string{
+		"HTTP://EXAMPLE.COM:80/r%20v/X?id=123": "http://example.com/r%20v/X",
+		"https://www.example.net:8080/?q=1":    "https://www.example.net:8080/",
+		"https://example.com:443":              "https://example.com/",
+	} {
+		u, _ := url.Parse(in)
+		if got := oauthBaseURI(u); got != want {
+			t.Errorf("oauthBaseURI(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestOAuth1Escape(t *testing.T) {
+	for in, want := range map[string]string{
+		"abcABC123-._~": "abcABC123-._~",
+		"a b+c":         "a%20b%2Bc",
+		"=%3D":          "%3D%253D",
+		"ü":             "%C3%BC",
+	} {
+		if got := oauthEscape(in); got != want {
+			t.Errorf("oauthEscape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
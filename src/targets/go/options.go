@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// A CallOption customizes a single call made by one of the generated
+// methods, without affecting the Client or any other call.
+type CallOption func(*callOptions)
+
+// callOptions is the result of applying a list of CallOptions.
+type callOptions struct {
+	timeout time.Duration
+	stream  bool
+	edits   []func(*http.Request)
+}
+
+func newCallOptions(opts []CallOption) *callOptions {
+	call := &callOptions{}
+	for _, opt := range opts {
+		opt(call)
+	}
+
+	return call
+}
+
+// apply makes the edits requested by the options to the call's request.
+func (c *callOptions) apply(req *http.Request) {
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	for _, edit := range c.edits {
+		edit(req)
+	}
+}
+
+// WithHeader sets a header on the call's request, replacing any value the
+// method set for it. When several WithHeader options name the same header,
+// compared case-insensitively, the last one wins; the header is only ever
+// sent with one value. It's set before the request is passed to the
+// filters, so they see it and may change it, and it only affects this
+// call's request, not later calls made with the same Client.
+func WithHeader(key, value string) CallOption {
+	return func(c *callOptions) {
+		c.edits = append(c.edits, func(req *http.Request) { req.Header.Set(key, value) })
+	}
+}
+
+// WithQuery sets a parameter in the query string of the call's request,
+// such as one the API accepts but doesn't declare. It replaces any values
+// the method set for the same key, leaving the other parameters as they
+// were encoded. Like WithHeader, the last option for a key wins, and it's
+// set before the request is passed to the filters.
+func WithQuery(key, value string) CallOption {
+	return func(c *callOptions) {
+		c.edits = append(c.edits, func(req *http.Request) { setQueryParam(req.URL, key, value) })
+	}
+}
+
+// setQueryParam replaces the values of the key in the URL's query string
+// with the value. Other parameters are kept in order, without re-encoding
+// them, so parameters serialized in a particular array style keep it.
+func setQueryParam(u *url.URL, key, value string) {
+	var pairs []string
+	if u.RawQuery != "" {
+		for _, pair := range strings.Split(u.RawQuery, "&") {
+			name := pair
+			if i := strings.IndexByte(pair, '='); i >= 0 {
+				name = pair[:i]
+			}
+			if unescaped, err := url.QueryUnescape(name); err == nil && unescaped == key {
+				continue
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+
+	pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+	u.RawQuery = strings.Join(pairs, "&")
+}
+
+// WithTimeout bounds the time the call may take, including reading the
+// response body. It composes with any deadline on the context passed to
+// the method, whichever is sooner taking effect, and doesn't change the
+// Timeout of the Client's underlying http.Client.
+func WithTimeout(d time.Duration) CallOption {
+	return func(c *callOptions) { c.timeout = d }
+}
+
+// WithStreaming leaves the response body of a successful call unread, so
+// that the caller can stream it, such as to process a large list of
+// results or newline-delimited JSON as it arrives rather than holding it
+// all in memory. The method returns a zero result, and the caller must
+// read and close the body of the *http.Response it returns. Filters still
+// see the response, but the Client doesn't buffer it for them.
+func WithStreaming() CallOption {
+	return func(c *callOptions) { c.stream = true }
+}
+
+// streamed returns whether the options ask for the call's response body to
+// be left unread.
+func streamed(opts []CallOption) bool {
+	return newCallOptions(opts).stream
+}
+
+type streamKey struct{}
+
+// IsStreaming returns whether the request is for a call whose response body
+// is streamed to the caller, as requested by WithStreaming. Filters should
+// neither read nor buffer the response bodies of such calls.
+func IsStreaming(req *http.Request) bool {
+	return req.Context().Value(streamKey{}) != nil
+}
+
+// cancelBody releases a call's context once its response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelBody) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
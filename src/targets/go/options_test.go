@@ -0,0 +1,202 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}
+		w.Write([]byte("ok"))
+	})
+	defer close(release)
+
+	c := NewClient(srv.URL)
+	start := time.Now()
+	if _, err := get(t, c, "/slow", WithTimeout(20*time.Millisecond)); err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("the call took %v to time out", d)
+	}
+	if c.HTTP.Timeout != DefaultTimeout {
+		t.Errorf("the http.Client's Timeout changed to %v", c.HTTP.Timeout)
+	}
+
+	// The body may be read once the call returns, as the timeout lasts
+	// until it's closed.
+	res, err := get(t, c, "/fast", WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readAll(t, res); body != "ok" {
+		t.Errorf("got body %q, want ok", body)
+	}
+}
+
+func TestWithTimeoutKeepsShorterDeadline(t *testing.T) {
+	release := make(chan struct{})
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequest("GET", "/", nil)
+	start := time.Now()
+	if _, err := NewClient(srv.URL).do(ctx, req, WithTimeout(time.Hour)); err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("the call took %v, ignoring the context's deadline", d)
+	}
+}
+
+func TestCallOptionsApplyBeforeFilters(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Tenant-Id"); got != "acme" {
+			t.Errorf("server got X-Tenant-Id %q, want acme", got)
+		}
+		if got := r.URL.Query().Get("trace"); got != "1" {
+			t.Errorf("server got trace %q, want 1", got)
+		}
+	})
+
+	c := NewClient(srv.URL)
+	var seen []string
+	c.AddFilter(&testFilter{before: func(req *http.Request) error {
+		seen = append(seen, req.Header.Get("X-Tenant-Id")+" "+req.URL.RawQuery)
+		return nil
+	}})
+
+	res, err := get(t, c, "/", WithHeader("X-Tenant-Id", "acme"), WithQuery("trace", "1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if len(seen) != 1 || seen[0] != "acme trace=1" {
+		t.Errorf("filters saw %q, want the options applied", seen)
+	}
+}
+
+func TestWithStreaming(t *testing.T) {
+	const chunk, chunks = 64 << 10, 512
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		data := bytes.Repeat([]byte("x"), chunk)
+		for i := 0; i < chunks; i++ {
+			w.Write(data)
+			w.(http.Flusher).Flush()
+		}
+	})
+
+	// Buffering every body would hold the whole stream in memory, but the
+	// After filters still see the response.
+	c := NewClient(srv.URL)
+	c.BufferResponses(1 << 40)
+	var streaming bool
+	c.AddFilter(&testFilter{after: func(res *http.Response) {
+		streaming = IsStreaming(res.Request)
+	}})
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	res, err := get(t, c, "/", WithStreaming())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.ContentLength != -1 {
+		t.Fatalf("got ContentLength %d, want a chunked response", res.ContentLength)
+	}
+	if !streaming {
+		t.Error("After filters didn't see a streaming request")
+	}
+	n, err := io.Copy(io.Discard, res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runtime.ReadMemStats(&after)
+	if n != chunk*chunks {
+		t.Errorf("read %d bytes, want %d", n, chunk*chunks)
+	}
+	if grown := after.TotalAlloc - before.TotalAlloc; grown > chunk*chunks/4 {
+		t.Errorf("allocated %d bytes streaming a %d byte body", grown, chunk*chunks)
+	}
+}
+
+func TestWithHeader(t *testing.T) {
+	var got [][]string
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Values("X-Tenant-Id"))
+	})
+
+	c := NewClient(srv.URL)
+	for _, opts := range [][]CallOption{
+		{WithHeader("X-Tenant-Id", "acme"), WithHeader("x-tenant-id", "globex")},
+		nil,
+	} {
+		// The method's own value is replaced too.
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Tenant-Id", "method")
+		res, err := c.do(context.Background(), req, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	res, err := get(t, c, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if len(got) != 3 {
+		t.Fatalf("server got %d requests, want 3", len(got))
+	}
+	if len(got[0]) != 1 || got[0][0] != "globex" {
+		t.Errorf("server got X-Tenant-Id %q, want only the last option's", got[0])
+	}
+	if len(got[1]) != 1 || got[1][0] != "method" {
+		t.Errorf("server got X-Tenant-Id %q, want the method's", got[1])
+	}
+	if len(got[2]) != 0 {
+		t.Errorf("server got X-Tenant-Id %q on a later call, want none", got[2])
+	}
+}
+
+func TestSetQueryParam(t *testing.T) {
+	for _, tc := range []struct {
+		query, key, value, want string
+	}{
+		{"", "trace", "on", "trace=on"},
+		{"page=2", "trace", "on", "page=2&trace=on"},
+		{"page=2&tag=a&tag=b", "tag", "c", "page=2&tag=c"},
+		{"ids=1,2&page=2", "page", "3", "ids=1,2&page=3"},
+		{"filter%5Bauthor%5D=Austen&q=emma", "filter[author]", "Brontë", "q=emma&filter%5Bauthor%5D=Bront%C3%AB"},
+		{"flag&q=emma", "flag", "", "q=emma&flag="},
+	} {
+		u := &url.URL{Path: "/", RawQuery: tc.query}
+		setQueryParam(u, tc.key, tc.value)
+		if u.RawQuery != tc.want {
+			t.Errorf("setting %s in %q: got %q, want %q", tc.key, tc.query, u.RawQuery, tc.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// marshalPatch marshals the body of a partial update, v, which is a struct
+// whose fields are left out when nil, with the codec, then sets the
+// properties of the fields named in nulls to null. Naming a field the
+// struct doesn't have is an error, so that a typo doesn't leave the field
+// unchanged.
+func marshalPatch(codec Codec, v interface{}, nulls []string) ([]byte, error) {
+	data, err := codec.Marshal(v)
+	if err != nil || len(nulls) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := codec.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	t := reflect.TypeOf(v)
+	for _, name := range nulls {
+		f, ok := t.FieldByName(name)
+		if !ok || f.Tag.Get("json") == "-" {
+			return nil, fmt.Errorf("client: NullFields names %s, which isn't a field", name)
+		}
+
+		key := strings.Split(f.Tag.Get("json"), ",")[0]
+		if key == "" {
+			key = f.Name
+		}
+		fields[key] = json.RawMessage("null")
+	}
+
+	return codec.Marshal(fields)
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+type testPatch struct {
+	Name     *string  `json:"name,omitempty"`
+	Nickname *string  `json:"nickname,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Untagged *int     `json:",omitempty"`
+	Fields   []string `json:"-"`
+}
+
+func TestMarshalPatch(t *testing.T) {
+	name := "Ada"
+	cases := []struct {
+		patch testPatch
+		nulls []string
+		want  string
+	}{
+		{testPatch{}, nil, `{}`},
+		{testPatch{Name: &name}, nil, `{"name":"Ada"}`},
+		{testPatch{Name: &name}, []string{"Nickname"}, `{"name":"Ada","nickname":null}`},
+		{testPatch{}, []string{"Tags", "Untagged"}, `{"Untagged":null,"tags":null}`},
+
+		// Null wins over a value set on the same field.
+		{testPatch{Name: &name}, []string{"Name"}, `{"name":null}`},
+	}
+	for _, c := range cases {
+		data, err := marshalPatch(JSONCodec, c.patch, c.nulls)
+		if err != nil {
+			t.Errorf("nulling %v: %v", c.nulls, err)
+			continue
+		}
+		if string(data) != c.want {
+			t.Errorf("nulling %v: got %s, want %s", c.nulls, data, c.want)
+		}
+	}
+}
+
+func TestMarshalPatchUnknownFields(t *testing.T) {
+	for _, name := range []string{"name", "Nick", "Fields"} {
+		_, err := marshalPatch(JSONCodec, testPatch{}, []string{name})
+		if err == nil || !strings.Contains(err.Error(), "NullFields names "+name) {
+			t.Errorf("nulling %s: got error %v, want one naming it", name, err)
+		}
+	}
+}
+
+func TestMarshalPatchCodec(t *testing.T) {
+	codec := &recordingCodec{}
+	if _, err := marshalPatch(codec, testPatch{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := codec.count("Marshal"); n != 1 {
+		t.Errorf("marshalled %d times without nulls, want once", n)
+	}
+
+	if _, err := marshalPatch(codec, testPatch{}, []string{"Name"}); err != nil {
+		t.Fatal(err)
+	}
+	if n, m := codec.count("Marshal"), codec.count("Unmarshal"); n != 3 || m != 1 {
+		t.Errorf("made %d Marshal and %d Unmarshal calls, want 3 and 1", n, m)
+	}
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimitFilter throttles outgoing requests using a token bucket which
+// refills at a fixed number of requests per second, and holds up to a
+// burst of tokens. Before blocks until a token is available or the
+// request's context is done. It is safe for concurrent use.
+type RateLimitFilter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+var _ Filter = new(RateLimitFilter)
+
+// NewRateLimitFilter creates a RateLimitFilter allowing rps requests per
+// second on average, with bursts of up to burst requests. The rate must be
+// greater than zero; NewRateLimitFilter panics otherwise.
+func NewRateLimitFilter(rps float64, burst int) *RateLimitFilter {
+	if !(rps > 0) {
+		panic(fmt.Sprintf("client: non-positive rate %v for NewRateLimitFilter", rps))
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimitFilter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Before waits for a token to become available.
+func (r *RateLimitFilter) Before(req *http.Request) error {
+	wait := r.reserve()
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		r.release()
+		return req.Context().Err()
+	}
+}
+
+func (r *RateLimitFilter) After(res *http.Response)                {}
+func (r *RateLimitFilter) AfterError(req *http.Request, err error) {}
+
+// reserve takes a token from the bucket, returning how long the caller
+// must wait until the token is actually available.
+func (r *RateLimitFilter) reserve() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.tokens += now.Sub(r.last).Seconds() * r.rate
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	r.last = now
+
+	r.tokens--
+	if r.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-r.tokens / r.rate * float64(time.Second))
+}
+
+// release returns a reserved token which went unused.
+func (r *RateLimitFilter) release() {
+	r.mu.Lock()
+	r.tokens++
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	r.mu.Unlock()
+}
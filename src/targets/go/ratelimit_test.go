@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimitConcurrentRequests(t *testing.T) {
+	const (
+		rps      = 50
+		burst    = 2
+		requests = 12
+	)
+
+	var mu sync.Mutex
+	var sent []time.Time
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sent = append(sent, time.Now())
+		mu.Unlock()
+	})
+
+	c := NewClient(srv.URL)
+	c.UseRateLimit(rps, burst)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := get(t, c, "/")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	// The burst goes out at once, then a request may be made every 1/rps.
+	min := time.Duration(float64(requests-burst) / rps * float64(time.Second))
+	if elapsed := time.Since(start); elapsed < min*9/10 {
+		t.Errorf("%d requests took %v, want at least %v at %d per second", requests, elapsed, min, rps)
+	}
+
+	// Within any 100ms, at most the burst and the tokens added meanwhile
+	// may be spent.
+	sort.Slice(sent, func(i, j int) bool { return sent[i].Before(sent[j]) })
+	for i, at := range sent {
+		n := 0
+		for _, other := range sent[i:] {
+			if other.Sub(at) < 100*time.Millisecond {
+				n++
+			}
+		}
+		if limit := burst + int(math.Ceil(rps*0.1)); n > limit {
+			t.Errorf("sent %d requests within 100ms, want at most %d", n, limit)
+		}
+	}
+}
+
+func TestRateLimitRespectsContext(t *testing.T) {
+	f := NewRateLimitFilter(0.001, 1)
+	req, _ := http.NewRequest("GET", "https://api.example.com/", nil)
+	if err := f.Before(req); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := f.Before(req.WithContext(ctx)); err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRateLimitRejectsNonPositiveRates(t *testing.T) {
+	for _, rps := range []float64{0, -1, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewRateLimitFilter(%v, 1) didn't panic", rps)
+				}
+			}()
+			NewRateLimitFilter(rps, 1)
+		}()
+	}
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryOptions configures a RetryFilter. Zero values are replaced with
+// the defaults noted on each field.
+type RetryOptions struct {
+	// MaxAttempts is the number of times a request will be sent, including
+	// the first attempt. Defaults to 3.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles after each
+	// subsequent attempt. Defaults to 100 milliseconds.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between attempts. It does not limit delays
+	// requested by the server via Retry-After. Defaults to 10 seconds.
+	MaxDelay time.Duration
+}
+
+// RetryFilter retries idempotent requests which fail with a connection
+// error or a 5xx or 429 response. Requests with an `Idempotency-Key` header
+// are taken to be idempotent, whatever their method. Delays between
+// attempts grow exponentially with jitter, unless the server sends a
+// Retry-After header along with a 429 or 503 response, in which case that
+// is honored instead.
+type RetryFilter struct {
+	opts RetryOptions
+}
+
+var _ bodyBufferer = new(RetryFilter)
+
+// NewRetryFilter creates a RetryFilter using the given options.
+func NewRetryFilter(opts RetryOptions) *RetryFilter {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = 100 * time.Millisecond
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = 10 * time.Second
+	}
+
+	return &RetryFilter{opts: opts}
+}
+
+func (r *RetryFilter) Before(req *http.Request) error          { return nil }
+func (r *RetryFilter) After(res *http.Response)                {}
+func (r *RetryFilter) AfterError(req *http.Request, err error) {}
+
+func (r *RetryFilter) buffersBodies() {}
+
+// Retry implements Retrier.Retry.
+func (r *RetryFilter) Retry(req *http.Request, res *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= r.opts.MaxAttempts || !isIdempotent(req) {
+		return 0, false
+	}
+
+	if err != nil {
+		// Errors from the context being done aren't transport errors, and
+		// aren't worth retrying.
+		if !IsTransportError(err) {
+			return 0, false
+		}
+
+		return r.backoff(attempt), true
+	}
+
+	if res.StatusCode != http.StatusTooManyRequests && res.StatusCode < 500 {
+		return 0, false
+	}
+
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable {
+		if d, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			return d, true
+		}
+	}
+
+	return r.backoff(attempt), true
+}
+
+// backoff returns the delay to wait after the given attempt, picked at
+// random from the upper half of the exponential delay.
+func (r *RetryFilter) backoff(attempt int) time.Duration {
+	d := r.opts.BaseDelay << uint(attempt-1)
+	if d <= 0 || d > r.opts.MaxDelay {
+		d = r.opts.MaxDelay
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// isIdempotent returns whether the request may safely be sent twice, which
+// is the case if its method is idempotent or it has an idempotency key.
+func isIdempotent(req *http.Request) bool {
+	return isIdempotentMethod(req.Method) || req.Header.Get(idempotencyHeader) != ""
+}
+
+// isIdempotentMethod returns whether requests with the method may safely be
+// sent twice.
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return true
+	}
+
+	return false
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which may be
+// either a number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+
+		return 0, true
+	}
+
+	return 0, false
+}
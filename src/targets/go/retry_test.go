@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryReplaysBody(t *testing.T) {
+	var attempts int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("attempt got body %q, want payload", body)
+		}
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		io.WriteString(w, "done")
+	})
+
+	c := NewClient(srv.URL)
+	c.UseRetry(RetryOptions{BaseDelay: time.Millisecond})
+
+	// A reader without a Len hides the body from http.NewRequest, which
+	// would otherwise set GetBody itself.
+	req, _ := http.NewRequest("PUT", "/", io.MultiReader(strings.NewReader("payload")))
+	res, err := c.do(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readAll(t, res); body != "done" {
+		t.Errorf("got body %q, want done", body)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 3 {
+		t.Errorf("made %d attempts, want 3", n)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	var attempts int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	c := NewClient(srv.URL)
+	c.UseRetry(RetryOptions{MaxAttempts: 2, BaseDelay: time.Millisecond})
+
+	res, err := get(t, c, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want the last attempt's", res.StatusCode)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 2 {
+		t.Errorf("made %d attempts, want 2", n)
+	}
+}
+
+func TestRetrySkipsNonIdempotentMethods(t *testing.T) {
+	var attempts int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	c := NewClient(srv.URL)
+	c.UseRetry(RetryOptions{BaseDelay: time.Millisecond})
+
+	req, _ := http.NewRequest("POST", "/", strings.NewReader("{}"))
+	res, err := c.do(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Errorf("made %d attempts of a POST, want 1", n)
+	}
+}
+
+func TestRetryRetriesTransportErrors(t *testing.T) {
+	var attempts int32
+	c := NewClient("https://api.example.com", WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&attempts, 1)
+		return nil, errors.New("connection reset")
+	})))
+	c.UseRetry(RetryOptions{MaxAttempts: 4, BaseDelay: time.Millisecond})
+
+	if _, err := get(t, c, "/"); !IsTransportError(err) {
+		t.Fatalf("got error %v, want a TransportError", err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 4 {
+		t.Errorf("made %d attempts, want 4", n)
+	}
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	c := NewClient(srv.URL)
+	c.UseRetry(RetryOptions{BaseDelay: time.Hour, MaxDelay: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequest("GET", "/", nil)
+	start := time.Now()
+	if _, err := c.do(ctx, req); err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("the call took %v to notice its deadline", d)
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	r := NewRetryFilter(RetryOptions{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	req, _ := http.NewRequest("GET", "https://api.example.com/", nil)
+
+	for _, tc := range []struct {
+		status int
+		header string
+		want   time.Duration
+	}{
+		{http.StatusTooManyRequests, "7", 7 * time.Second},
+		{http.StatusServiceUnavailable, "120", 2 * time.Minute},
+		{http.StatusServiceUnavailable, time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0},
+	} {
+		res := &http.Response{StatusCode: tc.status, Header: http.Header{"Retry-After": {tc.header}}}
+		if d, ok := r.Retry(req, res, nil, 1); !ok || d != tc.want {
+			t.Errorf("%d with Retry-After %q: got (%v, %v), want (%v, true)", tc.status, tc.header, d, ok, tc.want)
+		}
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	res := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {future}}}
+	if d, ok := r.Retry(req, res, nil, 1); !ok || d < 58*time.Minute || d > time.Hour {
+		t.Errorf("Retry-After %q: got (%v, %v), want about an hour", future, d, ok)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	r := NewRetryFilter(RetryOptions{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		max *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			if d := r.backoff(attempt + 1); d < max/2 || d > max {
+				t.Fatalf("backoff after attempt %d is %v, want between %v and %v", attempt+1, d, max/2, max)
+			}
+		}
+	}
+}
@@ -0,0 +1,439 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// SchemaError is returned by calls whose response body doesn't match the
+// JSON schema the API declares for it, when the Client validates responses.
+type SchemaError struct {
+	// StatusCode is the status of the response.
+	StatusCode int
+	// Path locates the offending value in the body, such as "$.items[2].id".
+	Path string
+	// Reason describes the constraint which was violated.
+	Reason string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("client: %d response doesn't match its schema at %s: %s", e.StatusCode, e.Path, e.Reason)
+}
+
+// ValidateResponses sets whether calls check JSON response bodies against
+// the schemas the API declares for them, failing with a SchemaError when
+// one doesn't match. It's meant for catching drift between the API and its
+// definition while debugging, and is off by default, since the body has to
+// be decoded twice. The keywords of JSON schema draft 4 which constrain
+// values are checked, with the exception of `format`, `dependencies` and
+// `patternProperties`.
+func (c *Client) ValidateResponses(enabled bool) {
+	c.validateResponses = enabled
+}
+
+// jsonSchema is a JSON schema baked into the client, which is parsed the
+// first time a response is validated against it.
+type jsonSchema struct {
+	src  string
+	once sync.Once
+	root interface{}
+	err  error
+}
+
+// newSchema returns a jsonSchema for the JSON source.
+func newSchema(src string) *jsonSchema {
+	return &jsonSchema{src: src}
+}
+
+// validateResponse checks the response body against the schema, if the
+// Client validates responses and the body is JSON. The body is buffered,
+// so it can still be read afterwards.
+func (c *Client) validateResponse(res *http.Response, schema *jsonSchema) error {
+	if !c.validateResponses {
+		return nil
+	}
+
+	mediaType := responseMediaType(res)
+	if mediaType != "" && !strings.HasSuffix(mediaType, "/json") && !strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+
+	data, err := readBody(res)
+	if err != nil || len(bytes.TrimSpace(data)) == 0 {
+		return err
+	}
+
+	schema.once.Do(func() { schema.root, schema.err = decodeNumbers([]byte(schema.src)) })
+	if schema.err != nil {
+		return schema.err
+	}
+
+	value, err := decodeNumbers(data)
+	if err != nil {
+		return newDecodeError(res, data, err)
+	}
+
+	if path, reason := checkSchema(schema.root, schema.root, value, "$"); reason != "" {
+		return &SchemaError{StatusCode: res.StatusCode, Path: path, Reason: reason}
+	}
+
+	return nil
+}
+
+// decodeNumbers decodes JSON, keeping numbers as json.Numbers so integers
+// can be told apart from other numbers.
+func decodeNumbers(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	err := dec.Decode(&v)
+	return v, err
+}
+
+// checkSchema checks the value at path against the schema, within the root
+// schema which references are resolved against. It returns the path of
+// the first value found to violate a constraint, and the reason, which is
+// empty if the value matches.
+func checkSchema(root, schema, v interface{}, path string) (string, string) {
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		return "", ""
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		target, ok := schemaPointer(root, ref)
+		if !ok {
+			return path, fmt.Sprintf("unresolvable reference %q", ref)
+		}
+		return checkSchema(root, target, v, path)
+	}
+
+	if types := schemaTypes(s["type"]); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			matched = matched || hasJSONType(v, t)
+		}
+		if !matched {
+			return path, "must be of type " + strings.Join(types, " or ")
+		}
+	}
+
+	if values, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, value := range values {
+			found = found || jsonValuesEqual(v, value)
+		}
+		if !found {
+			return path, "must be one of the enumerated values"
+		}
+	}
+
+	for _, sub := range schemaList(s["allOf"]) {
+		if p, reason := checkSchema(root, sub, v, path); reason != "" {
+			return p, reason
+		}
+	}
+	if subs := schemaList(s["anyOf"]); len(subs) > 0 {
+		matched := false
+		for _, sub := range subs {
+			_, reason := checkSchema(root, sub, v, path)
+			matched = matched || reason == ""
+		}
+		if !matched {
+			return path, "must match a schema in anyOf"
+		}
+	}
+	if subs := schemaList(s["oneOf"]); len(subs) > 0 {
+		matched := 0
+		for _, sub := range subs {
+			if _, reason := checkSchema(root, sub, v, path); reason == "" {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return path, "must match exactly one schema in oneOf"
+		}
+	}
+	if not, ok := s["not"]; ok {
+		if _, reason := checkSchema(root, not, v, path); reason == "" {
+			return path, "must not match the schema in not"
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return checkObject(root, s, v, path)
+	case []interface{}:
+		return checkArray(root, s, v, path)
+	case string:
+		if n, ok := schemaNumber(s["minLength"]); ok && float64(utf8.RuneCountInString(v)) < n {
+			return path, fmt.Sprintf("must be at least %v characters", n)
+		}
+		if n, ok := schemaNumber(s["maxLength"]); ok && float64(utf8.RuneCountInString(v)) > n {
+			return path, fmt.Sprintf("must be at most %v characters", n)
+		}
+		if pattern, ok := s["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				return path, "must match " + pattern
+			}
+		}
+	case json.Number:
+		return checkNumber(s, v, path)
+	}
+
+	return "", ""
+}
+
+func checkObject(root interface{}, s, v map[string]interface{}, path string) (string, string) {
+	for _, name := range schemaList(s["required"]) {
+		if name, ok := name.(string); ok {
+			if _, ok := v[name]; !ok {
+				return path, fmt.Sprintf("missing required property %q", name)
+			}
+		}
+	}
+
+	if n, ok := schemaNumber(s["minProperties"]); ok && float64(len(v)) < n {
+		return path, fmt.Sprintf("must have at least %v properties", n)
+	}
+	if n, ok := schemaNumber(s["maxProperties"]); ok && float64(len(v)) > n {
+		return path, fmt.Sprintf("must have at most %v properties", n)
+	}
+
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	props, _ := s["properties"].(map[string]interface{})
+	for _, name := range names {
+		sub, declared := props[name]
+		if !declared {
+			sub = s["additionalProperties"]
+			if allowed, ok := sub.(bool); ok && !allowed {
+				return path, fmt.Sprintf("unexpected property %q", name)
+			}
+		}
+		if p, reason := checkSchema(root, sub, v[name], path+"."+name); reason != "" {
+			return p, reason
+		}
+	}
+
+	return "", ""
+}
+
+func checkArray(root interface{}, s map[string]interface{}, v []interface{}, path string) (string, string) {
+	if n, ok := schemaNumber(s["minItems"]); ok && float64(len(v)) < n {
+		return path, fmt.Sprintf("must have at least %v items", n)
+	}
+	if n, ok := schemaNumber(s["maxItems"]); ok && float64(len(v)) > n {
+		return path, fmt.Sprintf("must have at most %v items", n)
+	}
+	if unique, _ := s["uniqueItems"].(bool); unique {
+		for i := range v {
+			for j := i + 1; j < len(v); j++ {
+				if jsonValuesEqual(v[i], v[j]) {
+					return path, "must have unique items"
+				}
+			}
+		}
+	}
+
+	tuple, isTuple := s["items"].([]interface{})
+	for i, item := range v {
+		sub := s["items"]
+		if isTuple {
+			if i < len(tuple) {
+				sub = tuple[i]
+			} else {
+				sub = s["additionalItems"]
+				if allowed, ok := sub.(bool); ok && !allowed {
+					return path, fmt.Sprintf("must have at most %d items", len(tuple))
+				}
+			}
+		}
+		if p, reason := checkSchema(root, sub, item, fmt.Sprintf("%s[%d]", path, i)); reason != "" {
+			return p, reason
+		}
+	}
+
+	return "", ""
+}
+
+func checkNumber(s map[string]interface{}, v json.Number, path string) (string, string) {
+	n, err := v.Float64()
+	if err != nil {
+		return path, "must be a number"
+	}
+
+	// Draft 4 makes the exclusive bounds flags on minimum and maximum,
+	// while later drafts make them bounds of their own.
+	if min, ok := schemaNumber(s["minimum"]); ok {
+		if exclusive, _ := s["exclusiveMinimum"].(bool); exclusive && n <= min {
+			return path, fmt.Sprintf("must be greater than %v", min)
+		} else if n < min {
+			return path, fmt.Sprintf("must be at least %v", min)
+		}
+	}
+	if min, ok := schemaNumber(s["exclusiveMinimum"]); ok && n <= min {
+		return path, fmt.Sprintf("must be greater than %v", min)
+	}
+	if max, ok := schemaNumber(s["maximum"]); ok {
+		if exclusive, _ := s["exclusiveMaximum"].(bool); exclusive && n >= max {
+			return path, fmt.Sprintf("must be less than %v", max)
+		} else if n > max {
+			return path, fmt.Sprintf("must be at most %v", max)
+		}
+	}
+	if max, ok := schemaNumber(s["exclusiveMaximum"]); ok && n >= max {
+		return path, fmt.Sprintf("must be less than %v", max)
+	}
+	if m, ok := schemaNumber(s["multipleOf"]); ok && m > 0 {
+		if q := n / m; q != float64(int64(q)) {
+			return path, fmt.Sprintf("must be a multiple of %v", m)
+		}
+	}
+
+	return "", ""
+}
+
+// hasJSONType returns whether the decoded value is of the JSON schema type.
+func hasJSONType(v interface{}, t string) bool {
+	switch v := v.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case string:
+		return t == "string"
+	case map[string]interface{}:
+		return t == "object"
+	case []interface{}:
+		return t == "array"
+	case json.Number:
+		if t == "number" {
+			return true
+		}
+		if t == "integer" {
+			f, err := v.Float64()
+			return err == nil && f == float64(int64(f))
+		}
+	}
+
+	return false
+}
+
+// jsonValuesEqual returns whether two decoded values are equal, comparing
+// numbers by value so that 1 and 1.0 are the same.
+func jsonValuesEqual(a, b interface{}) bool {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		af, aerr := an.Float64()
+		bf, berr := bn.Float64()
+		return aerr == nil && berr == nil && af == bf
+	}
+
+	switch a := a.(type) {
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonValuesEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			if !jsonValuesEqual(v, b[k]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// schemaPointer resolves a reference within the root schema, such as
+// "#/definitions/user".
+func schemaPointer(root interface{}, ref string) (interface{}, bool) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, false
+	}
+
+	node := root
+	for _, segment := range strings.Split(strings.TrimPrefix(ref[1:], "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		segment = strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+		switch n := node.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if node, ok = n[segment]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, false
+			}
+			node = n[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return node, true
+}
+
+// schemaTypes returns the types a schema's `type` keyword allows.
+func schemaTypes(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var types []string
+		for _, t := range v {
+			if t, ok := t.(string); ok {
+				types = append(types, t)
+			}
+		}
+		return types
+	}
+
+	return nil
+}
+
+func schemaList(v interface{}) []interface{} {
+	list, _ := v.([]interface{})
+	return list
+}
+
+func schemaNumber(v interface{}) (float64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+
+	f, err := n.Float64()
+	return f, err == nil
+}
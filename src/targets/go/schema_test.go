@@ -0,0 +1,92 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+const userSchema = `{
+	"type": "object",
+	"required": ["id", "name"],
+	"properties": {
+		"id": {"type": "integer", "minimum": 1},
+		"name": {"type": "string", "minLength": 1},
+		"tags": {"type": "array", "items": {"$ref": "#/definitions/tag"}, "uniqueItems": true}
+	},
+	"additionalProperties": false,
+	"definitions": {
+		"tag": {"enum": ["admin", "staff"]}
+	}
+}`
+
+func TestValidateResponses(t *testing.T) {
+	schema := newSchema(userSchema)
+	c := NewClient("https://api.example.com")
+	const body = `{"id": 1}`
+
+	if err := c.validateResponse(testResponse(200, "application/json", body), schema); err != nil {
+		t.Errorf("got error %v, want none while validation is off", err)
+	}
+
+	c.ValidateResponses(true)
+	res := testResponse(200, "application/json", body)
+	err := c.validateResponse(res, schema)
+	var serr *SchemaError
+	if !errors.As(err, &serr) {
+		t.Fatalf("got error %v, want a SchemaError", err)
+	}
+	if serr.StatusCode != 200 || serr.Path != "$" || serr.Reason != `missing required property "name"` {
+		t.Errorf("got %+v, want name to be missing at $", serr)
+	}
+	if data, _ := io.ReadAll(res.Body); string(data) != body {
+		t.Errorf("left the body %q, want it readable", data)
+	}
+
+	// Bodies which aren't JSON aren't checked.
+	if err := c.validateResponse(testResponse(200, "text/plain", body), schema); err != nil {
+		t.Errorf("got error %v for a text body", err)
+	}
+	if err := c.validateResponse(testResponse(200, "application/vnd.user+json", `{"id": 1, "name": "ada"}`), schema); err != nil {
+		t.Errorf("got error %v for a matching body", err)
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	root, err := decodeNumbers([]byte(userSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		body, path, reason string
+	}{
+		{`{"id": 1, "name": "ada", "tags": ["admin"]}`, "", ""},
+		{`[]`, "$", "must be of type object"},
+		{`{"id": 1.5, "name": "ada"}`, "$.id", "must be of type integer"},
+		{`{"id": 0, "name": "ada"}`, "$.id", "must be at least 1"},
+		{`{"id": 1, "name": ""}`, "$.name", "must be at least 1 characters"},
+		{`{"id": 1, "name": "ada", "tags": ["admin", "admin"]}`, "$.tags", "must have unique items"},
+		{`{"id": 1, "name": "ada", "tags": ["staff", "root"]}`, "$.tags[1]", "must be one of the enumerated values"},
+		{`{"id": 1, "name": "ada", "email": "ada@example.com"}`, "$", `unexpected property "email"`},
+	} {
+		v, err := decodeNumbers([]byte(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path, reason := checkSchema(root, root, v, "$"); path != tc.path || reason != tc.reason {
+			t.Errorf("%s: got (%q, %q), want (%q, %q)", tc.body, path, reason, tc.path, tc.reason)
+		}
+	}
+}
+
+func TestValidateResponsesDecodeError(t *testing.T) {
+	c := NewClient("https://api.example.com")
+	c.ValidateResponses(true)
+	err := c.validateResponse(testResponse(http.StatusOK, "application/json", `{"id":`), newSchema(userSchema))
+	var derr *DecodeError
+	if !errors.As(err, &derr) {
+		t.Errorf("got error %v, want a DecodeError", err)
+	}
+}
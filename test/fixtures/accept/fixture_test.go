@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestAcceptHeaders(t *testing.T) {
+	accepts := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accepts[r.Method+" "+r.URL.Path] = r.Header.Get("Accept")
+		switch {
+		case r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/notes":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `[]`)
+		default:
+			w.Header().Set("Content-Type", "application/vnd.report+json")
+			io.WriteString(w, `{"title":"Q3"}`)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	ctx := context.Background()
+	if _, _, err := c.ListNotes(ctx); err != nil {
+		t.Fatal(err)
+	}
+	_, report, err := c.GetReport(ctx, "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Title != "Q3" {
+		t.Errorf("got report %+v, want Q3", report)
+	}
+	if _, err := c.DeleteReports(ctx, "3"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"GET /notes":        "application/json",
+		"GET /reports/3":    "application/json, application/vnd.report+json;q=0.9",
+		"DELETE /reports/3": "",
+	}
+	if !reflect.DeepEqual(accepts, want) {
+		t.Errorf("sent Accept headers %q, want %q", accepts, want)
+	}
+}
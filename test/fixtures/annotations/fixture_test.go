@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAnnotatedMethodName(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users" {
+			t.Errorf("got path %s, want /users", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[{"name":"ada"},{}]`)
+	}))
+	defer srv.Close()
+
+	_, users, err := NewClient(srv.URL).ListAllUsers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("got %d users, want 2", len(users))
+	}
+
+	// The required name is a pointer, so a missing one can be told apart.
+	if users[0].Name == nil || *users[0].Name != "ada" {
+		t.Errorf("got name %v, want ada", users[0].Name)
+	}
+	if users[1].Name != nil {
+		t.Errorf("got name %q, want nil", *users[1].Name)
+	}
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIKeySchemes(t *testing.T) {
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	c.UseAPIKeyAuth("s3cret")
+	if _, err := c.GetItems(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if key := got.URL.Query().Get("api_key"); key != "s3cret" {
+		t.Errorf("sent the api_key parameter %q, want s3cret", key)
+	}
+
+	c = NewClient(srv.URL)
+	c.UseHeaderKeyAuth("s3cret")
+	if _, err := c.GetItems(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if key := got.Header.Get("X-API-Key"); key != "s3cret" {
+		t.Errorf("sent the X-API-Key header %q, want s3cret", key)
+	}
+	if got.URL.RawQuery != "" {
+		t.Errorf("sent the query %q, want none", got.URL.RawQuery)
+	}
+}
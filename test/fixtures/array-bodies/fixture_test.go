@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestArrayBodies(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	ctx := context.Background()
+	if _, err := c.CreateUsers(ctx, []User{{Name: "Ada"}, {Name: "Alan"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UpdateTags(ctx, []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{`[{"name":"Ada"},{"name":"Alan"}]`, `["a","b"]`}
+	if len(got) != len(want) {
+		t.Fatalf("sent %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sent %s, want %s", got[i], want[i])
+		}
+	}
+}
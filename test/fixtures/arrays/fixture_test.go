@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestArrayParams(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		style ArrayStyle
+		tags  []string
+		want  string
+	}{
+		{ArrayRepeat, []string{"go", "raml"}, "ids=1&ids=2&tag=go&tag=raml"},
+		{ArrayComma, []string{"go", "raml"}, "ids=1%2C2&tag=go%2Craml"},
+		{ArrayRepeat, nil, "ids=1&ids=2"},
+	} {
+		c := NewClient(srv.URL, WithArrayStyle(tc.style))
+		if _, _, err := c.ListPosts(context.Background(), []int64{1, 2}, ListPostsParams{Tag: tc.tags}); err != nil {
+			t.Fatal(err)
+		}
+		if query != tc.want {
+			t.Errorf("style %d: got query %q, want %q", tc.style, query, tc.want)
+		}
+	}
+}
+
+func TestArrayParamsRequired(t *testing.T) {
+	c := NewClient("http://127.0.0.1:1")
+	_, _, err := c.ListPosts(context.Background(), nil, ListPostsParams{})
+	if verr, ok := err.(*ValidationError); !ok || verr.Param != "ids" {
+		t.Errorf("got error %v, want ids to be missing", err)
+	}
+}
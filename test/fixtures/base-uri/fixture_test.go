@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc is an http.RoundTripper calling the function.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestBaseURIParameters(t *testing.T) {
+	var sent string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req.URL.String()
+		return &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	})
+
+	c, err := NewClientWithParameters(BaseURIParameters{Region: "eu"}, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetStatus(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := "http://eu.api.example.com/v3/status"; sent != want {
+		t.Errorf("sent the request to %s, want %s", sent, want)
+	}
+
+	// The version defaults to the API's, but may be set.
+	c, _ = NewClientWithParameters(BaseURIParameters{Region: "us", Version: "v4"}, WithTransport(transport))
+	c.GetStatus(context.Background())
+	if want := "http://us.api.example.com/v4/status"; sent != want {
+		t.Errorf("sent the request to %s, want %s", sent, want)
+	}
+}
+
+func TestBaseURIParametersRequired(t *testing.T) {
+	_, err := NewClientWithParameters(BaseURIParameters{})
+	if verr, ok := err.(*ValidationError); !ok || verr.Param != "region" {
+		t.Errorf("got error %v, want region to be required", err)
+	}
+	if BaseURL != "" {
+		t.Errorf("got BaseURL %q, want none as the region has no default", BaseURL)
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBinaryDownloadsStream(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		io.WriteString(w, "first ")
+		w.(http.Flusher).Flush()
+		<-release
+		io.WriteString(w, "second")
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	_, body, err := NewClient(srv.URL).GetFiles(context.Background(), "big.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+
+	// The call returns, and the start of the body can be read, before the
+	// server has sent the rest of it.
+	buf := make([]byte, len("first "))
+	if _, err := io.ReadFull(body, buf); err != nil || string(buf) != "first " {
+		t.Fatalf("read %q, %v before the rest was sent", buf, err)
+	}
+	release <- struct{}{}
+	rest, _ := io.ReadAll(body)
+	if string(rest) != "second" {
+		t.Errorf("read %q, want second", rest)
+	}
+}
+
+func TestBinaryUploadsStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != -1 {
+			t.Errorf("got Content-Length %d, want it unknown", r.ContentLength)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/octet-stream" {
+			t.Errorf("got Content-Type %q", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "streamed content" {
+			t.Errorf("got body %q", body)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	pr, pw := io.Pipe()
+	go func() {
+		io.WriteString(pw, "streamed ")
+		io.WriteString(pw, "content")
+		pw.Close()
+	}()
+	if _, err := NewClient(srv.URL).UpdateFiles(context.Background(), "big.bin", pr); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOnlyDeclaredBodiesAreSent(t *testing.T) {
+	type sent struct{ method, contentType, body string }
+	var got []sent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, sent{r.Method, r.Header.Get("Content-Type"), string(body)})
+		if r.Method == "GET" && r.URL.Path == "/notes" {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `[]`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	ctx := context.Background()
+	if _, _, err := c.ListNotes(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateNotes(ctx, Note{Text: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DeleteNotes(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The body declared on the GET is left out.
+	if _, err := c.GetSearch(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []sent{
+		{"GET", "", ""},
+		{"POST", "application/json", `{"text":"hi"}`},
+		{"DELETE", "", ""},
+		{"GET", "", ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("sent %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sent %q, want %q", got[i], want[i])
+		}
+	}
+}
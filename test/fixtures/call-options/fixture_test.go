@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCallOptions(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") != "" {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		if got := r.Header.Get("X-Tenant-Id"); got != "acme" {
+			t.Errorf("got X-Tenant-Id %q, want acme", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `["a"]`)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := NewClient(srv.URL)
+	ctx := context.Background()
+	_, items, err := c.ListItems(ctx, ListItemsParams{}, WithHeader("X-Tenant-Id", "acme"), WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0] != "a" {
+		t.Errorf("got items %v, want [a]", items)
+	}
+
+	if _, _, err := c.ListItems(ctx, ListItemsParams{}, WithQuery("slow", "1"), WithTimeout(20*time.Millisecond)); err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestWithQueryAndTypedParams(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	page := int64(2)
+	for _, tc := range []struct {
+		opts []CallOption
+		want string
+	}{
+		{[]CallOption{WithQuery("trace", "on")}, "page=2&trace=on"},
+		{[]CallOption{WithQuery("page", "5")}, "page=5"},
+	} {
+		if _, _, err := c.ListItems(context.Background(), ListItemsParams{Page: &page}, tc.opts...); err != nil {
+			t.Fatal(err)
+		}
+		if query != tc.want {
+			t.Errorf("got query %q, want %q", query, tc.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetUser(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/users/42" {
+			t.Errorf("got %s %s, want GET /users/42", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id": 42, "name": "Ada"}`)
+	}))
+	defer srv.Close()
+
+	_, user, err := NewClient(srv.URL).GetUser(context.Background(), "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 42 || user.Name != "Ada" {
+		t.Errorf("got user %+v, want 42 Ada", user)
+	}
+}
+
+// vetoFilter fails every request in Before.
+type vetoFilter struct{ err error }
+
+func (v vetoFilter) Before(req *http.Request) error          { return v.err }
+func (v vetoFilter) After(res *http.Response)                {}
+func (v vetoFilter) AfterError(req *http.Request, err error) {}
+
+func TestBeforeVetoesCall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a vetoed call was sent")
+	}))
+	defer srv.Close()
+
+	veto := errors.New("no token")
+	c := NewClient(srv.URL, WithFilters(vetoFilter{veto}))
+	if _, _, err := c.GetUser(context.Background(), "42"); err != veto {
+		t.Errorf("got error %v, want the filter's", err)
+	}
+}
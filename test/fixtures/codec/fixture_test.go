@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// countingCodec counts its calls, delegating to JSONCodec.
+type countingCodec struct{ marshal, unmarshal int }
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshal++
+	return JSONCodec.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshal++
+	return JSONCodec.Unmarshal(data, v)
+}
+
+func (c *countingCodec) NewDecoder(r io.Reader) Decoder { return JSONCodec.NewDecoder(r) }
+
+func TestCallsUseTheCodec(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
+
+	codec := &countingCodec{}
+	_, note, err := NewClient(srv.URL, WithCodec(codec)).CreateNotes(context.Background(), Note{Text: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if note.Text != "hi" {
+		t.Errorf("got note %+v, want the one sent", note)
+	}
+	if codec.marshal != 1 || codec.unmarshal != 1 {
+		t.Errorf("the codec marshalled %d and unmarshalled %d bodies, want one each", codec.marshal, codec.unmarshal)
+	}
+}
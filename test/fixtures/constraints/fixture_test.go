@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConstraints(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	short := "e"
+	for _, tc := range []struct {
+		slug   string
+		page   int64
+		params GetPagesParams
+		param  string
+		reason string
+	}{
+		{"home", 0, GetPagesParams{}, "page", "must be at least 1"},
+		{"Home!", 1, GetPagesParams{}, "slug", "must match ^[a-z-]+$"},
+		{"a-very-long-slug-indeed", 1, GetPagesParams{}, "slug", "must be at most 20 characters"},
+		{"home", 1, GetPagesParams{Lang: &short}, "lang", "must be at least 2 characters"},
+	} {
+		_, err := c.GetPages(context.Background(), tc.slug, tc.page, tc.params)
+		verr, ok := err.(*ValidationError)
+		if !ok || verr.Param != tc.param || verr.Reason != tc.reason {
+			t.Errorf("%q, page %d: got error %v, want %s %s", tc.slug, tc.page, err, tc.param, tc.reason)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("made %d invalid calls", calls)
+	}
+
+	if _, err := c.GetPages(context.Background(), "home", 1, GetPagesParams{}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("made %d calls, want 1", calls)
+	}
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const eventJSON = `{"at":"2016-02-28T16:41:41.09Z","day":"2015-05-23","starts":"12:30:00",` +
+	`"local":"2015-07-04T21:00:00","seen":"Sun, 28 Feb 2016 16:41:41 GMT"}`
+
+func TestDatesRoundTripFormats(t *testing.T) {
+	var e Event
+	if err := json.Unmarshal([]byte(eventJSON), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Day.Format("Jan 2 2006") != "May 23 2015" || e.Seen.Hour() != 16 {
+		t.Errorf("got day %v and seen %v", e.Day.Time, e.Seen.Time)
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != eventJSON {
+		t.Errorf("got %s, want %s", data, eventJSON)
+	}
+}
+
+func TestDatesParams(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.RawQuery, "before=2016-02-28T16%3A41%3A41Z&since=2015-05-23"; got != want {
+			t.Errorf("got query %q, want %q", got, want)
+		}
+		if got, want := r.Header.Get("If-Modified-Since"), "Sun, 28 Feb 2016 16:41:41 GMT"; got != want {
+			t.Errorf("got If-Modified-Since %q, want %q", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, "["+eventJSON+"]")
+	}))
+	defer srv.Close()
+
+	at := time.Date(2016, 2, 28, 16, 41, 41, 0, time.UTC)
+	since := DateOnly{time.Date(2015, 5, 23, 0, 0, 0, 0, time.UTC)}
+	modified := HTTPTime{at}
+
+	c := NewClient(srv.URL)
+	_, events, err := c.ListEvents(context.Background(), since, ListEventsParams{Before: &at}, ListEventsHeaders{IfModifiedSince: &modified})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Local.String() != "2015-07-04T21:00:00" {
+		t.Errorf("got events %+v", events)
+	}
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParamDefaults(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	limit := int64(50)
+	for _, tc := range []struct {
+		order  string
+		params ListItemsParams
+		want   string
+	}{
+		{"", ListItemsParams{}, "limit=20&order=asc"},
+		{"desc", ListItemsParams{Limit: &limit}, "limit=50&order=desc"},
+	} {
+		if _, _, err := c.ListItems(context.Background(), tc.order, tc.params); err != nil {
+			t.Fatal(err)
+		}
+		if query != tc.want {
+			t.Errorf("got query %q, want %q", query, tc.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDocComments checks that godoc reads the comments carried over from
+// the definition as the docs of what they precede.
+func TestDocComments(t *testing.T) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	names, _ := filepath.Glob("*.go")
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	pkg, err := doc.NewFromFiles(fset, files, "example.com/client")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs := map[string]string{}
+	for _, typ := range pkg.Types {
+		docs[typ.Name] = typ.Doc
+		for _, method := range typ.Methods {
+			docs[typ.Name+"."+method.Name] = method.Doc
+		}
+	}
+
+	for name, want := range map[string]string{
+		"Book":               "Book is a book in the catalogue, which lists every book the library holds,",
+		"Client.ListBooks":   "ListBooks returns all the books in the catalogue, sorted by title.",
+		"Client.CreateBooks": "CreateBooks adds a book.",
+	} {
+		if got := docs[name]; !strings.HasPrefix(got, want) {
+			t.Errorf("%s has doc %q, want it to start with %q", name, got, want)
+		}
+	}
+}
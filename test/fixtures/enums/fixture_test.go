@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnumConstants(t *testing.T) {
+	for _, tc := range []struct {
+		got, want Status
+	}{
+		{StatusActive, "active"},
+		{StatusInactive, "inactive"},
+		{StatusOnHold, "on-hold"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("got %q, want %q", tc.got, tc.want)
+		}
+		if !tc.got.IsValid() {
+			t.Errorf("%q isn't valid", tc.got)
+		}
+	}
+	if Status("deleted").IsValid() {
+		t.Error("deleted is valid")
+	}
+
+	if PriorityMinus1 != -1 || Priority0 != 0 || Priority1 != 1 {
+		t.Errorf("got priorities %d, %d and %d, want -1, 0 and 1", PriorityMinus1, Priority0, Priority1)
+	}
+	if Priority(2).IsValid() {
+		t.Error("2 is a valid priority")
+	}
+}
+
+func TestCollidingEnumConstants(t *testing.T) {
+	for _, tc := range []struct {
+		got, want Sort
+	}{
+		{SortCreated, "created"},
+		{SortCreated2, "-created"},
+		{SortName, "name"},
+		{SortName2, "-name"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("got %q, want %q", tc.got, tc.want)
+		}
+		if !tc.got.IsValid() {
+			t.Errorf("%q isn't valid", tc.got)
+		}
+	}
+}
+
+func TestEnumParams(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if got, want := r.URL.RawQuery, "priority=-1&status=on-hold"; got != want {
+			t.Errorf("got query %q, want %q", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	status, priority := StatusOnHold, PriorityMinus1
+	if _, _, err := c.ListTasks(context.Background(), ListTasksParams{Status: &status, Priority: &priority}); err != nil {
+		t.Fatal(err)
+	}
+
+	status = "deleted"
+	_, _, err := c.ListTasks(context.Background(), ListTasksParams{Status: &status})
+	if verr, ok := err.(*ValidationError); !ok || verr.Param != "status" {
+		t.Errorf("got error %v, want status to be invalid", err)
+	}
+	if calls != 1 {
+		t.Errorf("made %d calls, want only the valid one", calls)
+	}
+}
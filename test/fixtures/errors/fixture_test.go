@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorsPerStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/users/missing":
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message": "no such user", "resource": "users/missing"}`)
+		case "/users/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, `{"trace": "..."}`)
+		default:
+			w.WriteHeader(http.StatusTeapot)
+			io.WriteString(w, `short and stout`)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	ctx := context.Background()
+
+	_, _, err := c.GetUser(ctx, "missing")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !IsStatusError(err, http.StatusNotFound) {
+		t.Fatalf("got error %v, want a 404 APIError", err)
+	}
+	notFound, ok := apiErr.Body.(*NotFound)
+	if !ok || notFound.Message != "no such user" || notFound.Resource != "users/missing" {
+		t.Errorf("got body %#v, want the decoded NotFound", apiErr.Body)
+	}
+
+	// Statuses without a declared body, and undeclared ones, only carry the
+	// raw body.
+	for _, tc := range []struct {
+		id     string
+		status int
+		raw    string
+	}{
+		{"broken", http.StatusInternalServerError, `{"trace": "..."}`},
+		{"teapot", http.StatusTeapot, "short and stout"},
+	} {
+		_, _, err := c.GetUser(ctx, tc.id)
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != tc.status || apiErr.Body != nil || string(apiErr.Raw) != tc.raw {
+			t.Errorf("got error %#v, want a %d APIError holding %q", err, tc.status, tc.raw)
+		}
+	}
+}
+
+func TestTransportErrorsFromCalls(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	_, _, err := NewClient(srv.URL).GetUser(context.Background(), "1")
+	if !IsTransportError(err) || IsStatusError(err, 0) {
+		t.Errorf("got error %v, want a TransportError", err)
+	}
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFieldNamesKeepWireNames(t *testing.T) {
+	const wire = `{"user-id":"u1","created_at":"2020-01-02T03:04:05Z","api_url":"https://api.example.com","3d_secure":true}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != wire {
+			t.Errorf("server got %s, want %s", body, wire)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, wire)
+	}))
+	defer srv.Close()
+
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	account := Account{UserID: "u1", CreatedAt: created, APIURL: "https://api.example.com", N3dSecure: true}
+	_, got, err := NewClient(srv.URL).CreateAccounts(context.Background(), account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != account {
+		t.Errorf("decoded %+v, want %+v", got, account)
+	}
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFormParametersAreSentInTheBody(t *testing.T) {
+	var query, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	ctx := context.Background()
+	plan := Plan("pro")
+	for _, tc := range []struct {
+		payload CreateSubscriptionsPayload
+		want    string
+	}{
+		{CreateSubscriptionsPayload{Email: "ada@example.com"}, "email=ada%40example.com&plan=free"},
+		{CreateSubscriptionsPayload{Email: "ada@example.com", Plan: &plan, Tag: []string{"a", "b"}}, "email=ada%40example.com&plan=pro&tag=a&tag=b"},
+	} {
+		if _, err := c.CreateSubscriptions(ctx, tc.payload); err != nil {
+			t.Fatal(err)
+		}
+		if query != "" {
+			t.Errorf("sent the query %q, want none", query)
+		}
+		if body != tc.want {
+			t.Errorf("sent the body %q, want %q", body, tc.want)
+		}
+	}
+
+	if _, err := c.CreateSubscriptions(ctx, CreateSubscriptionsPayload{}); err == nil || !strings.Contains(err.Error(), `"email"`) {
+		t.Errorf("got error %v, want email to be required", err)
+	}
+}
+
+func TestMultipartFormParameters(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery != "" {
+			t.Errorf("sent the query %q, want none", r.URL.RawQuery)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		if got := r.MultipartForm.Value["caption"]; len(got) != 1 || got[0] != "Cats" {
+			t.Errorf("got caption %q, want Cats", got)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+		if header.Filename != "cats.txt" || string(data) != "meow" {
+			t.Errorf("got file %s holding %q, want cats.txt holding meow", header.Filename, data)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	caption := "Cats"
+	_, err := NewClient(srv.URL).CreateUploads(context.Background(), CreateUploadsPayload{
+		Caption: &caption,
+		File:    &FilePart{Name: "cats.txt", Content: strings.NewReader("meow")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
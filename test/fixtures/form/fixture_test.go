@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestURLEncodedBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/x-www-form-urlencoded" {
+			t.Errorf("got Content-Type %q", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		want := "code=a%2Fb+c&grant_type=authorization_code&redirect_uri=https%3A%2F%2Fapp.example.com%2Fcb%3Fx%3D1%26y%3D2"
+		if string(body) != want {
+			t.Errorf("got body %q, want %q", body, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"access_token": "t0ken"}`)
+	}))
+	defer srv.Close()
+
+	_, token, err := NewClient(srv.URL).ExchangeCode(context.Background(), ExchangeCodePayload{
+		GrantType:   "authorization_code",
+		Code:        "a/b c",
+		RedirectURI: "https://app.example.com/cb?x=1&y=2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "t0ken" {
+		t.Errorf("got token %+v, want t0ken", token)
+	}
+}
+
+func TestURLEncodedBodyRequiresFields(t *testing.T) {
+	_, _, err := NewClient("http://api.example.com").ExchangeCode(context.Background(), ExchangeCodePayload{
+		GrantType:   "authorization_code",
+		RedirectURI: "https://app.example.com/cb",
+	})
+	if verr, ok := err.(*ValidationError); !ok || verr.Param != "code" {
+		t.Errorf("got error %v, want code to be required", err)
+	}
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeaderParams(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	ctx := context.Background()
+	if _, err := c.DeleteDocs(ctx, "1", `"v1"`, DeleteDocsHeaders{}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("If-Match") != `"v1"` || got.Get("X-Priority") != "low" || got.Get("X-Request-Id") != "" {
+		t.Errorf("sent headers %v, want If-Match and the default priority", got)
+	}
+
+	id, priority := "0f-1e", XPriorityHigh
+	if _, err := c.DeleteDocs(ctx, "1", `"v2"`, DeleteDocsHeaders{XRequestID: &id, XPriority: &priority}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("If-Match") != `"v2"` || got.Get("X-Priority") != "high" || got.Get("X-Request-Id") != "0f-1e" {
+		t.Errorf("sent headers %v, want the ones set", got)
+	}
+}
+
+func TestInvalidHeaderParams(t *testing.T) {
+	c := NewClient("http://api.example.com")
+	bad := "not hex"
+	for _, tc := range []struct {
+		ifMatch string
+		headers DeleteDocsHeaders
+		param   string
+	}{
+		{"", DeleteDocsHeaders{}, "If-Match"},
+		{`"v1"`, DeleteDocsHeaders{XRequestID: &bad}, "X-Request-Id"},
+	} {
+		_, err := c.DeleteDocs(context.Background(), "1", tc.ifMatch, tc.headers)
+		if verr, ok := err.(*ValidationError); !ok || verr.Param != tc.param {
+			t.Errorf("got error %v, want %s to be invalid", err, tc.param)
+		}
+	}
+}
+
+func TestHeaderParamsNamedLikeLocals(t *testing.T) {
+	var version string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version = r.Header.Get("V")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL).CreateDocs(context.Background(), "2"); err != nil {
+		t.Fatal(err)
+	}
+	if version != "2" {
+		t.Errorf("sent V %q, want 2", version)
+	}
+}
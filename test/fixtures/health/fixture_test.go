@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/v2/health" {
+			t.Errorf("got %s %s, want GET /v2/health", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("got Authorization %q, want the Client's", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, `{"ok": true}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL + "/v2")
+	c.UseBearerToken("token")
+	if err := c.Ping(context.Background()); err != nil {
+		t.Errorf("got error %v against a 200, want nil", err)
+	}
+
+	status = http.StatusServiceUnavailable
+	err := c.Ping(context.Background())
+	if !IsStatusError(err, http.StatusServiceUnavailable) {
+		t.Errorf("got error %v against a 503, want an APIError", err)
+	}
+}
+
+func TestPingTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	var terr *TransportError
+	if err := NewClient(srv.URL).Ping(context.Background()); !errors.As(err, &terr) {
+		t.Errorf("got error %v from a closed server, want a TransportError", err)
+	}
+}
+
+func TestStubPing(t *testing.T) {
+	want := errors.New("down")
+	var api API = &StubAPI{PingFunc: func(ctx context.Context, opts ...CallOption) error { return want }}
+	if err := api.Ping(context.Background()); err != want {
+		t.Errorf("got error %v, want the stub's", err)
+	}
+}
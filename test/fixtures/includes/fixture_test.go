@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIncludedSchemas(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"name": "Grace", "address": {"street": "1 Main St", "country": {"code": "US"}}}`)
+	}))
+	defer srv.Close()
+
+	_, profile, err := NewClient(srv.URL).GetProfile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Address and Country come from the schemas the included one includes.
+	var address Address = profile.Address
+	var country Country = address.Country
+	if profile.Name != "Grace" || address.Street != "1 Main St" || country.Code != "US" {
+		t.Errorf("got profile %+v", profile)
+	}
+}
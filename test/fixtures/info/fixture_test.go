@@ -0,0 +1,22 @@
+package client
+
+import "testing"
+
+func TestInfoConstants(t *testing.T) {
+	for _, tc := range []struct {
+		name, got, want string
+	}{
+		{"APIVersion", APIVersion, "v2"},
+		{"DefaultMediaType", DefaultMediaType, "application/vnd.store+json"},
+		{"BaseURL", BaseURL, "https://api.example.com/v2"},
+		{"DefaultUserAgent", DefaultUserAgent, "Example-Store/v2"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s is %q, want %q", tc.name, tc.got, tc.want)
+		}
+	}
+
+	if url := NewClient("").BaseURL(); url != BaseURL {
+		t.Errorf("clients created without a base URL use %q, want BaseURL", url)
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// removeUsers is a caller written against the API interface, rather than
+// the Client.
+func removeUsers(ctx context.Context, api API, ids ...string) error {
+	for _, id := range ids {
+		if _, err := api.DeleteUsers(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fakeAPI overrides the one call removeUsers makes, leaving the others to
+// the embedded interface.
+type fakeAPI struct {
+	API
+	deleted []string
+}
+
+func (f *fakeAPI) DeleteUsers(ctx context.Context, userID string, opts ...CallOption) (*http.Response, error) {
+	f.deleted = append(f.deleted, userID)
+	return &http.Response{StatusCode: http.StatusNoContent}, nil
+}
+
+func TestCallersUseFakes(t *testing.T) {
+	fake := new(fakeAPI)
+	if err := removeUsers(context.Background(), fake, "1", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.deleted) != 2 || fake.deleted[0] != "1" || fake.deleted[1] != "2" {
+		t.Errorf("deleted %v, want [1 2]", fake.deleted)
+	}
+}
+
+func TestCallersUseStubs(t *testing.T) {
+	var calls int
+	stub := &StubAPI{
+		DeleteUsersFunc: func(ctx context.Context, userID string, opts ...CallOption) (*http.Response, error) {
+			calls++
+			if userID != "7" {
+				t.Errorf("got user %q, want 7", userID)
+			}
+			return &http.Response{StatusCode: http.StatusNoContent}, nil
+		},
+	}
+
+	if err := removeUsers(context.Background(), stub, "7"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("made %d calls, want 1", calls)
+	}
+}
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLibraryTypes(t *testing.T) {
+	var order Order
+	err := json.Unmarshal([]byte(`{
+		"id": 1,
+		"billing": {"street": "1 Main St", "vatNumber": "GB123"},
+		"shipping": {"street": "2 High St", "country": {"code": "GB"}}
+	}`), &order)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both libraries declare an Address, which keep their own names and
+	// properties.
+	var billing BillingAddress = order.Billing
+	var shipping ShippingAddress = order.Shipping
+	if billing.Street != "1 Main St" || billing.VatNumber == nil || *billing.VatNumber != "GB123" {
+		t.Errorf("got billing address %+v", billing)
+	}
+	if shipping.Street != "2 High St" || shipping.Country.Code != "GB" {
+		t.Errorf("got shipping address %+v", shipping)
+	}
+
+	// Country is resolved within the shipping library.
+	var _ ShippingCountry = shipping.Country
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoginSession(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			if r.Method != "POST" || r.PostFormValue("email") != "ada@example.com" || r.PostFormValue("secret") != "hunter2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		case "/me":
+			if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	if err := c.LoginSession(context.Background(), "ada@example.com", "wrong"); err == nil {
+		t.Error("logging in with a bad password succeeded")
+	}
+	if err := c.LoginSession(context.Background(), "ada@example.com", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetMe(context.Background()); err != nil {
+		t.Errorf("calling with the session cookie failed: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMultipartUpload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		if got := r.FormValue("title"); got != "Holiday" {
+			t.Errorf("got title %q, want Holiday", got)
+		}
+		if _, ok := r.MultipartForm.Value["caption"]; ok {
+			t.Error("sent the unset caption")
+		}
+
+		file, header, err := r.FormFile("photo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(file)
+		if header.Filename != "beach.jpg" || header.Header.Get("Content-Type") != "image/jpeg" || string(content) != "JPEG" {
+			t.Errorf("got file %q of type %q holding %q", header.Filename, header.Header.Get("Content-Type"), content)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL).CreatePhotos(context.Background(), CreatePhotosPayload{
+		Title: "Holiday",
+		Photo: &FilePart{Name: "beach.jpg", ContentType: "image/jpeg", Content: strings.NewReader("JPEG")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCollidingNames(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		if r.Method == "GET" && r.URL.Path != "/user-groups" && r.URL.Path != "/usergroups" {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `[]`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	ctx := context.Background()
+	check := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, _, err := c.ListOrgsByOrgIDRepos(ctx, "go")
+	check(err)
+	_, _, err = c.ListOrgsRepos(ctx)
+	check(err)
+	_, err = c.GetUsergroups(ctx)
+	check(err)
+	_, err = c.GetUsergroups2(ctx)
+	check(err)
+	// The runtime's SetUserAgent keeps its name.
+	_, err = c.SetUserAgent2(ctx)
+	check(err)
+	c.SetUserAgent("test")
+
+	want := []string{
+		"GET /orgs/go/repos",
+		"GET /orgs/repos",
+		"GET /user-groups",
+		"GET /usergroups",
+		"PUT /agent",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("requested %q, want %q", paths, want)
+	}
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiatedResponses(t *testing.T) {
+	var contentType, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Accept"), "application/json, application/xml;q=0.9"; got != want {
+			t.Errorf("got Accept %q, want %q", got, want)
+		}
+		w.Header().Set("Content-Type", contentType)
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	ctx := context.Background()
+
+	contentType, body = "application/json; charset=utf-8", `{"title":"Q3"}`
+	_, result, err := c.GetReport(ctx, "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.JSON == nil || result.JSON.Title != "Q3" || result.XML != nil {
+		t.Errorf("got %+v, want the JSON report", result)
+	}
+
+	contentType, body = "application/xml", `<Summary><total>12</total></Summary>`
+	_, result, err = c.GetReport(ctx, "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.XML == nil || result.XML.Total != 12 || result.JSON != nil {
+		t.Errorf("got %+v, want the XML summary", result)
+	}
+
+	contentType, body = "text/csv", "title\nQ3\n"
+	if _, _, err := c.GetReport(ctx, "3"); err == nil {
+		t.Error("decoded a text/csv response")
+	}
+}
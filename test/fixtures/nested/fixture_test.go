@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNestedPathParamsAreEscaped(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"number":42,"title":"Broken"}`)
+	}))
+	defer srv.Close()
+
+	_, issue, err := NewClient(srv.URL).GetOrgsReposIssue(context.Background(), "acme/inc", "my repo", 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/orgs/acme%2Finc/repos/my%20repo/issues/42"; path != want {
+		t.Errorf("requested %s, want %s", path, want)
+	}
+	if issue.Number != 42 {
+		t.Errorf("got issue %+v, want number 42", issue)
+	}
+}
+
+func TestPathParamsNamedLikeLocals(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL).DeleteSearch(context.Background(), "cats"); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/search/cats" {
+		t.Errorf("requested %s, want /search/cats", path)
+	}
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// beyondFloat is 2^53 + 1, the smallest integer a float64 can't hold.
+const beyondFloat = 9007199254740993
+
+func TestIntegersRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"id":9007199254740993`) {
+			t.Errorf("sent %s, want the exact ID", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	record := Record{ID: beyondFloat, Score: 1.5, Attributes: map[string]interface{}{"parent": json.Number("9007199254740995")}}
+	for _, c := range []*Client{NewClient(srv.URL), NewClient(srv.URL, WithUseNumber())} {
+		_, got, err := c.CreateRecords(context.Background(), record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != beyondFloat {
+			t.Errorf("got ID %d, want %d", got.ID, int64(beyondFloat))
+		}
+	}
+
+	// Dynamically typed values keep their precision only WithUseNumber.
+	_, got, err := NewClient(srv.URL, WithUseNumber()).CreateRecords(context.Background(), record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent := got.Attributes.(map[string]interface{})["parent"]; parent != json.Number("9007199254740995") {
+		t.Errorf("got parent %#v, want the exact json.Number", parent)
+	}
+}
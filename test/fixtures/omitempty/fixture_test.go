@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestOmitEmpty(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("got body %q: %v", data, err)
+		}
+		keys = keys[:0]
+		for key := range body {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	empty, zero := "", 0.0
+	for _, tc := range []struct {
+		profile Profile
+		want    []string
+	}{
+		{Profile{}, []string{"age", "name"}},
+		{Profile{Nickname: &empty, Score: &zero}, []string{"age", "name", "nickname", "score"}},
+		{Profile{Address: &Address{}}, []string{"address", "age", "name"}},
+		{Profile{Tags: []string{"new"}}, []string{"age", "name", "tags"}},
+	} {
+		if _, err := c.CreateProfiles(context.Background(), tc.profile); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(keys, tc.want) {
+			t.Errorf("%+v: sent %v, want %v", tc.profile, keys, tc.want)
+		}
+	}
+}
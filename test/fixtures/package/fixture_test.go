@@ -0,0 +1,26 @@
+package store
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPackage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `["1"]`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	_, orders, err := c.ListOrders(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 1 || orders[0] != "1" {
+		t.Errorf("got orders %v, want [1]", orders)
+	}
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestOffsetPages(t *testing.T) {
+	all := []string{"a", "b", "c", "d", "e", "f", "g"}
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if limit != 3 {
+			t.Errorf("got limit %d, want the page size", limit)
+		}
+		page := []string{}
+		for i := offset; i < len(all) && i < offset+limit; i++ {
+			page = append(page, all[i])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	it := NewClient(srv.URL).ListItemsPages(ListItemsParams{}, 3)
+	var pages [][]string
+	for it.HasMore() {
+		page, err := it.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page) > 0 {
+			pages = append(pages, page)
+		}
+	}
+
+	want := [][]string{{"a", "b", "c"}, {"d", "e", "f"}, {"g"}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("got pages %v, want %v", pages, want)
+	}
+	if calls != 4 {
+		t.Errorf("made %d calls, want 4 ending with an empty page", calls)
+	}
+}
+
+func TestCursorPages(t *testing.T) {
+	pages := map[string]string{
+		"":   `{"events": ["a", "b"], "next": "p2"}`,
+		"p2": `{"events": ["c", "d"], "next": "p3"}`,
+		"p3": `{"events": ["e"]}`,
+	}
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(pages[r.URL.Query().Get("cursor")]))
+	}))
+	defer srv.Close()
+
+	events, err := NewClient(srv.URL).GetEventsPages(GetEventsParams{}, 0).All(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "c", "d", "e"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("got events %v, want %v", events, want)
+	}
+	if calls != 3 {
+		t.Errorf("made %d calls, want 3 ending without a next cursor", calls)
+	}
+}
+
+func TestAllStopsAtMax(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]string{strconv.Itoa(offset), strconv.Itoa(offset + 1)})
+	}))
+	defer srv.Close()
+
+	items, err := NewClient(srv.URL).ListItemsPages(ListItemsParams{}, 2).All(context.Background(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"0", "1", "2"}; !reflect.DeepEqual(items, want) {
+		t.Errorf("got items %v, want %v", items, want)
+	}
+	if calls != 2 {
+		t.Errorf("made %d calls, want 2 for the first 3 items", calls)
+	}
+}
+
+func TestAllReturnsPartialResults(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == "p2" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"events": ["a", "b"], "next": "p2"}`))
+	}))
+	defer srv.Close()
+
+	events, err := NewClient(srv.URL).GetEventsPages(GetEventsParams{}, 0).All(context.Background(), 0)
+	if !IsStatusError(err, http.StatusInternalServerError) {
+		t.Errorf("got error %v, want the failed page's", err)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("got events %v, want the %v fetched before the error", events, want)
+	}
+}
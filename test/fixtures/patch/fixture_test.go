@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// patchServer returns a server recording the JSON bodies sent to it.
+func patchServer(t *testing.T, bodies *[]map[string]interface{}) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("sent body %s: %v", data, err)
+		}
+		*bodies = append(*bodies, body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPatchBodies(t *testing.T) {
+	var bodies []map[string]interface{}
+	c := NewClient(patchServer(t, &bodies).URL)
+
+	name, age := "Ada", int64(36)
+	patches := []UpdateUserPayload{
+		// Unset fields are left out.
+		{Name: &name},
+
+		// Fields named in NullFields are sent as null.
+		{Age: &age, NullFields: []string{"Nickname", "Address"}},
+
+		{Address: &UpdateUserPayloadAddress{City: "London"}},
+	}
+	for _, patch := range patches {
+		if _, err := c.UpdateUser(context.Background(), "1", patch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []map[string]interface{}{
+		{"name": "Ada"},
+		{"age": float64(36), "nickname": nil, "address": nil},
+		{"address": map[string]interface{}{"city": "London"}},
+	}
+	if !reflect.DeepEqual(bodies, want) {
+		t.Errorf("sent bodies %v, want %v", bodies, want)
+	}
+}
+
+func TestPatchUnknownNullField(t *testing.T) {
+	var bodies []map[string]interface{}
+	c := NewClient(patchServer(t, &bodies).URL)
+
+	_, err := c.UpdateUser(context.Background(), "1", UpdateUserPayload{NullFields: []string{"nickname"}})
+	if err == nil {
+		t.Error("nulled a field the payload doesn't have")
+	}
+	if len(bodies) != 0 {
+		t.Errorf("sent %d bodies, want none", len(bodies))
+	}
+}
+
+func TestMergePatchBodies(t *testing.T) {
+	var bodies []map[string]interface{}
+	c := NewClient(patchServer(t, &bodies).URL)
+
+	if _, err := c.MergeSettings(context.Background(), MergeSettingsPayload{NullFields: []string{"Theme"}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []map[string]interface{}{{"theme": nil}}; !reflect.DeepEqual(bodies, want) {
+		t.Errorf("sent bodies %v, want %v", bodies, want)
+	}
+
+	// MarshalJSON sends the nulls too.
+	data, err := json.Marshal(MergeSettingsPayload{NullFields: []string{"Theme"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"theme":null}` {
+		t.Errorf("marshalled %s, want the theme nulled", data)
+	}
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func handleProtocols(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/status" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, `[]`)
+}
+
+func TestProtocolsRefuseHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleProtocols))
+	defer srv.Close()
+
+	c := NewClient(BaseURL)
+	if err := c.SetBaseURL(srv.URL); err == nil {
+		t.Error("set an http:// base URL")
+	}
+
+	c = NewClient(srv.URL)
+	if _, _, err := c.ListOrders(context.Background()); err == nil {
+		t.Error("listed the orders over HTTP")
+	}
+	// The status may be checked over either.
+	if _, err := c.GetStatus(context.Background()); err != nil {
+		t.Errorf("couldn't get the status over HTTP: %v", err)
+	}
+}
+
+func TestProtocolsAllowHTTPS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(handleProtocols))
+	defer srv.Close()
+
+	c := NewClient(BaseURL, WithHTTPClient(srv.Client()))
+	if err := c.SetBaseURL(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.ListOrders(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetStatus(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
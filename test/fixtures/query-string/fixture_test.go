@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestQueryString(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	page := int64(2)
+	for _, tc := range []struct {
+		search Search
+		want   string
+	}{
+		{Search{Q: "emma"}, "q=emma"},
+		{
+			Search{Q: "emma", Page: &page, Tags: []string{"a", "b"}, Filter: &SearchFilter{Author: "Austen"}},
+			"filter%5Bauthor%5D=Austen&page=2&q=emma&tags=a&tags=b",
+		},
+	} {
+		if _, _, err := c.ListBooks(context.Background(), tc.search); err != nil {
+			t.Fatal(err)
+		}
+		if query != tc.want {
+			t.Errorf("got query %q, want %q", query, tc.want)
+		}
+	}
+}
+
+func TestQueryStringValidation(t *testing.T) {
+	c := NewClient("http://127.0.0.1:1")
+	zero := int64(0)
+	for _, tc := range []struct {
+		search Search
+		param  string
+	}{
+		{Search{}, "q"},
+		{Search{Q: "emma", Page: &zero}, "page"},
+		{Search{Q: "emma", Filter: &SearchFilter{}}, "filter[author]"},
+	} {
+		_, _, err := c.ListBooks(context.Background(), tc.search)
+		if verr, ok := err.(*ValidationError); !ok || verr.Param != tc.param {
+			t.Errorf("%+v: got error %v, want %s to be invalid", tc.search, err, tc.param)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOptionalQueryParams(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	page, sort, exact := int64(0), "name & date", false
+	for _, tc := range []struct {
+		params ListSearchParams
+		want   string
+	}{
+		{ListSearchParams{}, "term=go+lang"},
+		{ListSearchParams{Sort: &sort}, "sort=name+%26+date&term=go+lang"},
+		// Zero values are sent, as they're set.
+		{ListSearchParams{Page: &page}, "page=0&term=go+lang"},
+		{ListSearchParams{Exact: &exact}, "exact=false&term=go+lang"},
+	} {
+		if _, _, err := c.ListSearch(context.Background(), "go lang", tc.params); err != nil {
+			t.Fatal(err)
+		}
+		if query != tc.want {
+			t.Errorf("got query %q, want %q", query, tc.want)
+		}
+	}
+}
+
+func TestQueryParamsNamedLikeLocals(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	if _, _, err := NewClient(srv.URL).ListSuggestions(context.Background(), "go"); err != nil {
+		t.Fatal(err)
+	}
+	if query != "q=go" {
+		t.Errorf("got query %q, want q=go", query)
+	}
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCallsReturnTheResponse(t *testing.T) {
+	const body = `{"id": 7, "name": "Grace"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("X-RateLimit-Remaining", "99")
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+
+	res, user, err := NewClient(srv.URL).GetUser(context.Background(), "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 7 || user.Name != "Grace" {
+		t.Errorf("got user %+v, want 7 Grace", user)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("got status %d, want 200", res.StatusCode)
+	}
+	if res.Header.Get("ETag") != `"v1"` || res.Header.Get("X-RateLimit-Remaining") != "99" {
+		t.Errorf("got headers %v, want the server's", res.Header)
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != body {
+		t.Errorf("read body %q after decoding it, want %q", data, body)
+	}
+}
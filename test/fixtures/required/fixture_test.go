@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequiredParams(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	ctx := context.Background()
+	for _, tc := range []struct {
+		userID, postID, reason, token string
+		missing                       string
+	}{
+		{"", "2", "spam", "t0ken", "userId"},
+		{"1", "", "spam", "t0ken", "postId"},
+		{"1", "2", "", "t0ken", "reason"},
+		{"1", "2", "spam", "", "X-Token"},
+	} {
+		_, err := c.DeleteUsersPosts(ctx, tc.userID, tc.postID, tc.reason, tc.token)
+		if verr, ok := err.(*ValidationError); !ok || verr.Param != tc.missing {
+			t.Errorf("got error %v, want %s to be missing", err, tc.missing)
+		}
+	}
+	if calls != 0 {
+		t.Fatalf("made %d calls with missing parameters", calls)
+	}
+
+	_, err := c.DeleteUsersPosts(ctx, "", "2", "spam", "t0ken")
+	if want := `client: missing required parameter "userId"`; err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+
+	if _, err := c.DeleteUsersPosts(ctx, "1", "2", "spam", "t0ken"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("made %d calls, want 1", calls)
+	}
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResourceTypeCalls(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /books":
+			io.WriteString(w, `[{"id": 1, "title": "Dune"}]`)
+		case "POST /books":
+			var book map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&book)
+			book["id"] = 2
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(book)
+		case "GET /books/1":
+			io.WriteString(w, `{"id": 1, "title": "Dune"}`)
+		case "DELETE /books/1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected call %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := NewClient(srv.URL)
+
+	_, books, err := c.ListBooks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(books) != 1 || books[0].Title != "Dune" {
+		t.Errorf("listed %+v, want Dune", books)
+	}
+
+	_, created, err := c.CreateBooks(ctx, Book{Title: "Emma"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != 2 || created.Title != "Emma" {
+		t.Errorf("created %+v, want Emma with ID 2", created)
+	}
+
+	_, book, err := c.GetBook(ctx, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.ID != 1 || book.Title != "Dune" {
+		t.Errorf("got %+v, want Dune", book)
+	}
+
+	if _, err := c.DeleteBooks(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseHeaders(t *testing.T) {
+	var next, count string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Total-Count", count)
+		if next != "" {
+			w.Header().Set("X-Next-Page", next)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `["a"]`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	count, next = "250", "2"
+	_, items, headers, err := c.ListItems(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int64 = headers.XTotalCount
+	if len(items) != 1 || total != 250 || headers.XNextPage == nil || *headers.XNextPage != "2" {
+		t.Errorf("got items %q and headers %+v, want a total of 250 and page 2 next", items, headers)
+	}
+
+	count, next = "250", ""
+	if _, _, headers, err = c.ListItems(context.Background()); err != nil || headers.XNextPage != nil {
+		t.Errorf("got headers %+v and error %v, want no next page", headers, err)
+	}
+
+	count = "many"
+	if _, _, _, err := c.ListItems(context.Background()); err == nil || !strings.Contains(err.Error(), "X-Total-Count") {
+		t.Errorf("got error %v, want the count to be unparseable", err)
+	}
+}
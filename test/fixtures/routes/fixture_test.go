@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// routeSink records the requests reported to it.
+type routeSink struct{ method, route string }
+
+func (s *routeSink) RecordRequest(method, route string, status int, d time.Duration) {
+	s.method, s.route = method, route
+}
+
+func TestMetricsRecordRouteTemplates(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sink := new(routeSink)
+	c := NewClient(srv.URL)
+	c.UseMetrics(sink)
+	if _, err := c.DeleteOrgsRepos(context.Background(), "acme", "anvil"); err != nil {
+		t.Fatal(err)
+	}
+	if sink.method != "DELETE" || sink.route != "/orgs/{orgId}/repos/{repoId}" {
+		t.Errorf("recorded %s %s, want DELETE /orgs/{orgId}/repos/{repoId}", sink.method, sink.route)
+	}
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSchemaStructs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"id": 7,
+			"address": {"city": "Paris", "geo": {"lat": 48.85, "lng": 2.35}},
+			"phones": [{"number": "555-0100", "mobile": true}]
+		}`)
+	}))
+	defer srv.Close()
+
+	_, user, err := NewClient(srv.URL).GetUsers(context.Background(), "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var u User = user
+	if u.ID != 7 {
+		t.Errorf("got ID %d, want 7", u.ID)
+	}
+	if u.Nickname != nil {
+		t.Errorf("got nickname %q, want it unset", *u.Nickname)
+	}
+
+	// Nested objects are named after the fields holding them, and
+	// referenced definitions after the definitions.
+	var address UserAddress = u.Address
+	if address.City != "Paris" || address.Geo == nil || *address.Geo.Lat != 48.85 || *address.Geo.Lng != 2.35 {
+		t.Errorf("got address %+v", address)
+	}
+	var phones []Phone = u.Phones
+	if len(phones) != 1 || phones[0].Number != "555-0100" || phones[0].Mobile == nil || !*phones[0].Mobile {
+		t.Errorf("got phones %+v", phones)
+	}
+}
+
+func TestValidateResponses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id": 7}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	if _, _, err := c.GetUsers(context.Background(), "7"); err != nil {
+		t.Errorf("got error %v, want none while validation is off", err)
+	}
+
+	c.ValidateResponses(true)
+	_, _, err := c.GetUsers(context.Background(), "7")
+	serr, ok := err.(*SchemaError)
+	if !ok {
+		t.Fatalf("got error %v, want a SchemaError", err)
+	}
+	if serr.Path != "$" || serr.Reason != `missing required property "address"` {
+		t.Errorf("got %+v, want address to be missing", serr)
+	}
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUnsecuredMethodsSkipAuth(t *testing.T) {
+	sent := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent[r.URL.Path] = r.Header.Get("Authorization") + r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	c.UseOAuth("t0ken")
+	c.UseAPIKey("key", "s3cret", APIKeyInQuery)
+
+	// A custom filter sees whether the call needs credentials.
+	var required []bool
+	c.AddFilter(beforeFunc(func(req *http.Request) error {
+		required = append(required, RequiresAuth(req))
+		return nil
+	}))
+
+	ctx := context.Background()
+	if _, err := c.GetMe(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetStatus(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sent["/me"]; got != "Bearer t0kenkey=s3cret" {
+		t.Errorf("sent %q for the secured call, want its credentials", got)
+	}
+	if got := sent["/status"]; got != "" {
+		t.Errorf("sent %q for the public call, want no credentials", got)
+	}
+	if len(required) != 2 || !required[0] || required[1] {
+		t.Errorf("filters saw RequiresAuth %v, want [true false]", required)
+	}
+}
+
+// beforeFunc is a filter calling the function before each request.
+type beforeFunc func(req *http.Request) error
+
+func (f beforeFunc) Before(req *http.Request) error          { return f(req) }
+func (f beforeFunc) After(res *http.Response)                {}
+func (f beforeFunc) AfterError(req *http.Request, err error) {}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// authorization returns the Authorization header of a call made with the
+// client set up by the function.
+func authorization(t *testing.T, setup func(c *Client)) string {
+	t.Helper()
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	setup(c)
+	if _, err := c.GetMe(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return got
+}
+
+func TestOAuth2Scheme(t *testing.T) {
+	if got := authorization(t, func(c *Client) { c.UseOAuth20Auth("t0ken") }); got != "Bearer t0ken" {
+		t.Errorf("sent Authorization %q, want Bearer t0ken", got)
+	}
+	if OAuth20AuthorizationURI != "https://example.com/oauth/authorize" {
+		t.Errorf("got authorization URI %q", OAuth20AuthorizationURI)
+	}
+	if OAuth20AccessTokenURI != "https://example.com/oauth/token" {
+		t.Errorf("got access token URI %q", OAuth20AccessTokenURI)
+	}
+}
+
+func TestOAuth1Scheme(t *testing.T) {
+	got := authorization(t, func(c *Client) { c.UseOAuth10Auth("key", "secret", "token", "tsecret") })
+	if !strings.HasPrefix(got, "OAuth ") || !strings.Contains(got, `oauth_consumer_key="key"`) {
+		t.Errorf("sent Authorization %q, want an OAuth 1.0 signature", got)
+	}
+	if OAuth10RequestTokenURI != "https://example.com/oauth/request_token" {
+		t.Errorf("got request token URI %q", OAuth10RequestTokenURI)
+	}
+	if OAuth10TokenCredentialsURI != "https://example.com/oauth/access_token" {
+		t.Errorf("got token credentials URI %q", OAuth10TokenCredentialsURI)
+	}
+}
+
+func TestBasicSchemes(t *testing.T) {
+	const want = "Basic dXNlcjpwYXNz"
+	if got := authorization(t, func(c *Client) { c.UsePartnerAuth("user", "pass") }); got != want {
+		t.Errorf("sent Authorization %q, want %q", got, want)
+	}
+
+	// The "basic" scheme's helper would be UseBasicAuth, which the client
+	// already has.
+	if got := authorization(t, func(c *Client) { c.UseBasicAuth("user", "pass") }); got != want {
+		t.Errorf("sent Authorization %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSharedError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/widgets" {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"code": 12, "message": "bad widget"}`)
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+		io.WriteString(w, `{"code": 34, "message": "gadget exists"}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	ctx := context.Background()
+	for _, tc := range []struct {
+		call    func() error
+		status  int
+		code    int64
+		message string
+	}{
+		{func() error { _, err := c.CreateWidgets(ctx, CreateWidgetsPayload{Name: "x"}); return err }, http.StatusBadRequest, 12, "bad widget"},
+		{func() error { _, err := c.CreateGadgets(ctx, CreateGadgetsPayload{Name: "x"}); return err }, http.StatusConflict, 34, "gadget exists"},
+	} {
+		err := tc.call()
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Fatalf("got error %v, want an Error", err)
+		}
+		if e.Status != tc.status || e.Code != tc.code || e.Message != tc.message {
+			t.Errorf("got %+v, want %d %d %q", e, tc.status, tc.code, tc.message)
+		}
+		if e.Error() != tc.message {
+			t.Errorf("got message %q, want %q", e.Error(), tc.message)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamedResponsesAreLeftUnread(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[{"line":"a"},{"line":"b"}]`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	var after int
+	c.AddFilter(afterFilter(func(res *http.Response) { after++ }))
+
+	res, entries, err := c.ListLogs(context.Background(), WithStreaming())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if entries != nil {
+		t.Errorf("got entries %v, want the body left to the caller", entries)
+	}
+	if after != 1 {
+		t.Errorf("After filters ran %d times, want 1", after)
+	}
+
+	dec := json.NewDecoder(res.Body)
+	if _, err := dec.Token(); err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for dec.More() {
+		var e Entry
+		if err := dec.Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, e.Line)
+	}
+	if len(lines) != 2 || lines[0] != "a" || lines[1] != "b" {
+		t.Errorf("streamed lines %q, want a and b", lines)
+	}
+}
+
+type afterFilter func(res *http.Response)
+
+func (afterFilter) Before(req *http.Request) error          { return nil }
+func (f afterFilter) After(res *http.Response)              { f(res) }
+func (afterFilter) AfterError(req *http.Request, err error) {}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSubClients(t *testing.T) {
+	var sent []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Method+" "+r.URL.Path+" "+r.Header.Get("User-Agent"))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "POST" || r.URL.Path == "/users/42":
+			io.WriteString(w, `{"name":"Ann"}`)
+		default:
+			io.WriteString(w, `[]`)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	users := c.Users()
+	// Sub-clients share the Client's settings, even those changed later.
+	c.SetUserAgent("test")
+
+	ctx := context.Background()
+	if _, _, err := users.List(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, user, err := users.Create(ctx, User{Name: "Ann"}); err != nil || user.Name != "Ann" {
+		t.Fatalf("got (%+v, %v), want Ann", user, err)
+	}
+	if _, user, err := users.Get(ctx, "42"); err != nil || user.Name != "Ann" {
+		t.Fatalf("got (%+v, %v), want Ann", user, err)
+	}
+	if _, err := users.Delete(ctx, "42"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := users.Orders().List(ctx, "42"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Orders().List(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"GET /users test",
+		"POST /users test",
+		"GET /users/42 test",
+		"DELETE /users/42 test",
+		"GET /users/42/orders test",
+		"GET /orders test",
+	}
+	if !reflect.DeepEqual(sent, want) {
+		t.Errorf("sent %q, want %q", sent, want)
+	}
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSwaggerClient(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.RequestURI())
+		if key := r.Header.Get("X-API-Key"); key != "s3cret" {
+			t.Errorf("%s %s: sent the X-API-Key header %q, want s3cret", r.Method, r.URL.Path, key)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			if r.URL.Path == "/v1/pets" {
+				json.NewEncoder(w).Encode([]Pet{{ID: 1, Name: "Rex"}})
+				return
+			}
+			json.NewEncoder(w).Encode(Pet{ID: 1, Name: "Rex"})
+		case "POST":
+			var pet Pet
+			json.NewDecoder(r.Body).Decode(&pet)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(pet)
+		}
+	}))
+	defer srv.Close()
+
+	if BaseURL != "https://api.example.com/v1" {
+		t.Errorf("got BaseURL %q, want https://api.example.com/v1", BaseURL)
+	}
+
+	ctx := context.Background()
+	c := NewClient(srv.URL + "/v1")
+	c.UseAPIKeyAuth("s3cret")
+
+	limit := int32(10)
+	_, pets, err := c.ListPets(ctx, ListPetsParams{Limit: &limit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pets) != 1 || pets[0].Name != "Rex" {
+		t.Errorf("listed pets %+v, want Rex", pets)
+	}
+
+	tag := "dog"
+	_, pet, err := c.CreatePet(ctx, Pet{ID: 2, Name: "Fido", Tag: &tag})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pet.ID != 2 || pet.Tag == nil || *pet.Tag != "dog" {
+		t.Errorf("created pet %+v, want Fido the dog", pet)
+	}
+
+	if _, pet, err = c.ShowPetByID(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if pet.Name != "Rex" {
+		t.Errorf("got pet %+v, want Rex", pet)
+	}
+
+	want := []string{"GET /v1/pets?limit=10", "POST /v1/pets", "GET /v1/pets/1"}
+	if len(got) != len(want) {
+		t.Fatalf("server got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("server got %q, want %q", got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// deadlineFilter records the deadline of the last request it saw.
+type deadlineFilter struct {
+	deadline time.Time
+	ok       bool
+}
+
+func (f *deadlineFilter) Before(req *http.Request) error {
+	f.deadline, f.ok = req.Context().Deadline()
+	return nil
+}
+
+func (f *deadlineFilter) After(res *http.Response) {}
+
+func (f *deadlineFilter) AfterError(req *http.Request, err error) {}
+
+func newTimeoutClient(t *testing.T) (*Client, *deadlineFilter) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `["a"]`)
+	}))
+	t.Cleanup(srv.Close)
+	f := &deadlineFilter{}
+	return NewClient(srv.URL, WithFilters(f)), f
+}
+
+// checkDeadline fails unless the filter saw a deadline d after start.
+func checkDeadline(t *testing.T, f *deadlineFilter, start time.Time, d time.Duration) {
+	t.Helper()
+	if !f.ok {
+		t.Fatalf("the request had no deadline, want one after %v", d)
+	}
+	if got := f.deadline.Sub(start); got < d || got > d+time.Second {
+		t.Errorf("the request's deadline was %v away, want %v", got, d)
+	}
+}
+
+func TestAnnotatedTimeout(t *testing.T) {
+	c, f := newTimeoutClient(t)
+
+	start := time.Now()
+	if _, _, err := c.Search(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	checkDeadline(t, f, start, 2*time.Second)
+
+	start = time.Now()
+	if _, _, err := c.ListReports(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	checkDeadline(t, f, start, 90*time.Second)
+
+	if _, _, err := c.Status(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if f.ok {
+		t.Errorf("the unannotated call had a deadline of %v", f.deadline)
+	}
+}
+
+func TestAnnotatedTimeoutOverride(t *testing.T) {
+	c, f := newTimeoutClient(t)
+
+	start := time.Now()
+	if _, _, err := c.Search(context.Background(), WithTimeout(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	checkDeadline(t, f, start, time.Minute)
+
+	// A caller's shorter deadline is kept.
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	if _, _, err := c.Search(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !f.deadline.Equal(want) {
+		t.Errorf("got deadline %v, want the context's %v", f.deadline, want)
+	}
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTraitParams(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Query().Encode(), "limit=10&offset=20&q=go"; got != want {
+			t.Errorf("got query %q, want %q", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `["client"]`)
+	}))
+	defer srv.Close()
+
+	offset, limit, q := int64(20), int64(10), "go"
+	_, repos, err := NewClient(srv.URL).ListRepos(context.Background(),
+		ListReposParams{Offset: &offset, Limit: &limit, Q: &q})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 1 || repos[0] != "client" {
+		t.Errorf("got repos %v, want [client]", repos)
+	}
+}
+
+func TestMethodParamsWinOverTraits(t *testing.T) {
+	c := NewClient("http://api.example.invalid", WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		t.Errorf("sent %s %s, which is invalid", req.Method, req.URL)
+		return nil, io.EOF
+	})))
+
+	// The trait allows a limit of up to 1000, but the method only 100.
+	limit := int64(500)
+	_, _, err := c.ListRepos(context.Background(), ListReposParams{Limit: &limit})
+	verr, ok := err.(*ValidationError)
+	if !ok || verr.Param != "limit" || verr.Reason != "must be at most 100" {
+		t.Errorf("got error %v, want limit to be at most 100", err)
+	}
+}
+
+// roundTripFunc is an http.RoundTripper calling the function.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// roundTrip decodes data into v and encodes it again, failing unless the
+// result is the same JSON.
+func roundTrip(t *testing.T, data string, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want, got interface{}
+	json.Unmarshal([]byte(data), &want)
+	json.Unmarshal(out, &got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round tripped %s into %s", data, out)
+	}
+}
+
+func TestInheritedProperties(t *testing.T) {
+	var kennel Kennel
+	roundTrip(t, `{"dogs": [{"name": "Rex", "tags": ["good"], "breed": "collie"}]}`, &kennel)
+
+	if len(kennel.Dogs) != 1 {
+		t.Fatalf("got dogs %+v, want one", kennel.Dogs)
+	}
+	dog := kennel.Dogs[0]
+	if dog.Name != "Rex" || dog.Breed != "collie" || len(dog.Tags) != 1 || dog.Tags[0] != "good" {
+		t.Errorf("got dog %+v, want Rex the collie", dog)
+	}
+	if kennel.Capacity != nil {
+		t.Errorf("got capacity %v, want it unset", *kennel.Capacity)
+	}
+
+	// Dog embeds Animal, so it can be used as one.
+	var animal Animal = dog.Animal
+	if animal.Name != "Rex" {
+		t.Errorf("got animal %+v, want Rex", animal)
+	}
+}
+
+func TestOptionalProperties(t *testing.T) {
+	var kennel Kennel
+	roundTrip(t, `{"dogs": [], "capacity": 4}`, &kennel)
+	if kennel.Capacity == nil || *kennel.Capacity != 4 {
+		t.Errorf("got capacity %v, want 4", kennel.Capacity)
+	}
+}
+
+func TestUnions(t *testing.T) {
+	var dog Pet
+	roundTrip(t, `{"name": "Rex", "tags": [], "breed": "collie"}`, &dog)
+	if dog.Dog == nil || dog.Cat != nil || dog.Dog.Breed != "collie" {
+		t.Errorf("got pet %+v, want the dog", dog)
+	}
+
+	var cat Pet
+	roundTrip(t, `{"name": "Tom", "tags": [], "indoor": true}`, &cat)
+	if cat.Cat == nil || cat.Dog != nil || !cat.Cat.Indoor {
+		t.Errorf("got pet %+v, want the cat", cat)
+	}
+
+	if err := json.Unmarshal([]byte(`{"wings": 2}`), new(Pet)); err == nil {
+		t.Error("decoded a pet which is neither member")
+	}
+}
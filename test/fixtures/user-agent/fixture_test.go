@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDefaultUserAgent(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.UserAgent()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	c.SetUserAgent(DefaultUserAgent)
+	if _, err := c.GetStatus(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got != "Example-API/v2" {
+		t.Errorf("sent User-Agent %q, want Example-API/v2", got)
+	}
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestXMLBodies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/xml" {
+			t.Errorf("got Content-Type %q, want application/xml", got)
+		}
+		if got := r.Header.Get("Accept"); got != "application/xml" {
+			t.Errorf("got Accept %q, want application/xml", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if want := xml.Header + `<Note id="7"><title>Hi</title></Note>`; string(body) != want {
+			t.Errorf("got body %q, want %q", body, want)
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		io.WriteString(w, xml.Header+`<Note id="7"><title>Hi</title><body>Saved</body></Note>`)
+	}))
+	defer srv.Close()
+
+	_, note, err := NewClient(srv.URL).UpdateNote(context.Background(), "7", Note{ID: 7, Title: "Hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if note.ID != 7 || note.Title != "Hi" || note.Body == nil || *note.Body != "Saved" {
+		t.Errorf("got note %+v", note)
+	}
+}
+
+func TestXMLArrayBodies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		want := xml.Header + `<Notes><Note id="1"><title>A</title></Note><Note id="2"><title>B</title></Note></Notes>`
+		if string(body) != want {
+			t.Errorf("got body %q, want %q", body, want)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL).CreateNotes(context.Background(), []Note{{ID: 1, Title: "A"}, {ID: 2, Title: "B"}}); err != nil {
+		t.Fatal(err)
+	}
+}
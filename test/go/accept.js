@@ -0,0 +1,13 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: accept', 'accept', client => {
+  it('accepts the media types of the successful response', () => {
+    expect(client.read('notes.go')).to.contain('req.Header.Set("Accept", "application/json")')
+    expect(client.read('reports.go'))
+      .to.contain('req.Header.Set("Accept", "application/json, application/vnd.report+json;q=0.9")')
+  })
+})
@@ -0,0 +1,25 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: annotations', 'annotations', client => {
+  it('names methods by (go.methodName)', () => {
+    const users = client.read('users.go')
+    expect(users).to.contain('func (c *Client) ListAllUsers(ctx context.Context, opts ...CallOption)')
+    expect(users).not.to.contain('func (c *Client) ListUsers(')
+  })
+
+  it('leaves out methods and resources marked (go.skip)', () => {
+    expect(client.read('users.go')).not.to.contain('DeleteUsers')
+    expect(client.files()).not.to.include('internal.go')
+    client.files().forEach(file => {
+      expect(client.read(file), file).not.to.match(/\/internal|Internal/)
+    })
+  })
+
+  it('makes fields marked (go.pointer) pointers', () => {
+    expect(client.read('models.go')).to.match(/\tName \*string +`json:"name"`/)
+  })
+})
@@ -0,0 +1,15 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: API key schemes', 'api-key', client => {
+  it('generates a helper for keys in the query', () => {
+    expect(client.read('api.go')).to.contain('c.UseAPIKey("api_key", key, APIKeyInQuery)')
+  })
+
+  it('generates a helper for keys in a header', () => {
+    expect(client.read('api.go')).to.contain('c.UseAPIKey("X-API-Key", key, APIKeyInHeader)')
+  })
+})
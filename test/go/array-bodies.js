@@ -0,0 +1,14 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: array bodies', 'array-bodies', client => {
+  it('sends arrays as slices of their items', () => {
+    const users = client.read('users.go')
+    expect(users).to.contain('func (c *Client) CreateUsers(ctx context.Context, payload []User, opts ...CallOption) (*http.Response, error) {')
+    expect(users).not.to.contain('CreateUsersPayload')
+    expect(client.read('tags.go')).to.contain('func (c *Client) UpdateTags(ctx context.Context, payload []string, opts ...CallOption) (*http.Response, error) {')
+  })
+})
@@ -0,0 +1,14 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: arrays', 'arrays', client => {
+  it('takes array parameters as slices', () => {
+    const posts = client.read('posts.go')
+    expect(posts).to.contain('ids []int64')
+    expect(posts).to.match(/\tTag \[\]string\n/)
+    expect(posts).to.contain('c.addArrayParam(v, "tag", formatParams(query.Tag))')
+  })
+})
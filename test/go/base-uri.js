@@ -0,0 +1,19 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: base URI parameters', 'base-uri', client => {
+  it('takes the parameters when creating clients', () => {
+    const api = client.read('api.go')
+    expect(api).to.match(/type BaseURIParameters struct \{\n\tRegion +string\n\tVersion +string\n\}/)
+    expect(api).to.contain('func NewClientWithParameters(params BaseURIParameters, opts ...Option) (*Client, error) {')
+  })
+
+  it('defaults the version to the API\'s', () => {
+    const api = client.read('api.go')
+    expect(api).to.contain('params.Version = "v3"')
+    expect(api).to.contain('return nil, missingParam("region")')
+  })
+})
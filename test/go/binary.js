@@ -0,0 +1,17 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: binary bodies', 'binary', client => {
+  it('returns downloads unread', () => {
+    const files = client.read('files.go')
+    expect(files).to.match(/func \(c \*Client\) GetFiles\(ctx context\.Context, name string, opts \.\.\.CallOption\) \(\*http\.Response, io\.ReadCloser, error\)/)
+  })
+
+  it('takes uploads as readers', () => {
+    const files = client.read('files.go')
+    expect(files).to.match(/func \(c \*Client\) UpdateFiles\(ctx context\.Context, name string, payload io\.Reader, opts \.\.\.CallOption\) \(\*http\.Response, error\)/)
+  })
+})
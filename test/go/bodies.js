@@ -0,0 +1,20 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: bodies', 'bodies', client => {
+  it('only sends bodies the methods declare', () => {
+    const notes = client.read('notes.go')
+    expect(notes).to.contain('func (c *Client) ListNotes(ctx context.Context, opts ...CallOption) (*http.Response, []Note, error) {')
+    expect(notes).to.contain('func (c *Client) CreateNotes(ctx context.Context, payload Note, opts ...CallOption) (*http.Response, error) {')
+    expect(notes).to.contain('func (c *Client) DeleteNotes(ctx context.Context, noteID string, opts ...CallOption) (*http.Response, error) {')
+  })
+
+  it('leaves out bodies declared on GET methods', () => {
+    const search = client.read('search.go')
+    expect(search).to.contain('func (c *Client) GetSearch(ctx context.Context, opts ...CallOption) (*http.Response, error) {')
+    expect(search).not.to.contain('Content-Type')
+  })
+})
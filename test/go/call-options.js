@@ -0,0 +1,13 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: call-options', 'call-options', client => {
+  it('passes the options of each call to the Client', () => {
+    const items = client.read('items.go')
+    expect(items).to.contain('func (c *Client) ListItems(ctx context.Context, query ListItemsParams, opts ...CallOption) (*http.Response, []string, error) {')
+    expect(items).to.contain('c.do(ctx, req, opts...)')
+  })
+})
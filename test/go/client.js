@@ -0,0 +1,17 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: client', 'client', client => {
+  it('copies the runtime into the package', () => {
+    expect(client.files()).to.include.members(['api.go', 'bootstrap.go', 'endpoints.go', 'models.go', 'users.go'])
+    expect(client.read('bootstrap.go')).to.match(/^package client$/m)
+  })
+
+  it('takes a context as the first argument of calls', () => {
+    expect(client.read('users.go'))
+      .to.contain('func (c *Client) GetUser(ctx context.Context, userID string, opts ...CallOption)')
+  })
+})
@@ -0,0 +1,13 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: codec', 'codec', client => {
+  it('marshals payloads with the Client\'s codec', () => {
+    const notes = client.read('notes.go')
+    expect(notes).to.contain('body, err := c.jsonCodec().Marshal(payload)')
+    expect(notes).not.to.contain('json.Marshal(')
+  })
+})
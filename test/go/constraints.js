@@ -0,0 +1,19 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: parameter constraints', 'constraints', client => {
+  it('checks bounds before sending', () => {
+    const pages = client.read('pages.go')
+    expect(pages).to.contain('if page < 1 {')
+    expect(pages).to.contain('&ValidationError{Param: "page", Reason: "must be at least 1"}')
+  })
+
+  it('compiles patterns once', () => {
+    const pages = client.read('pages.go')
+    expect(pages).to.contain('var getPagesSlugPattern = regexp.MustCompile(`^[a-z-]+$`)')
+    expect(pages).to.contain('if !getPagesSlugPattern.MatchString(slug) {')
+  })
+})
@@ -0,0 +1,23 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: dates', 'dates', client => {
+  it('maps each date and time type to its own Go type', () => {
+    const models = client.read('models.go')
+    expect(models).to.match(/\tAt +time\.Time +`json:"at"`\n/)
+    expect(models).to.match(/\tDay +DateOnly +`json:"day"`\n/)
+    expect(models).to.match(/\tStarts +TimeOnly +`json:"starts"`\n/)
+    expect(models).to.match(/\tLocal +DateTimeOnly +`json:"local"`\n/)
+    expect(models).to.match(/\tSeen +HTTPTime +`json:"seen"`\n/)
+  })
+
+  it('takes date parameters in their declared formats', () => {
+    const events = client.read('events.go')
+    expect(events).to.contain('since DateOnly')
+    expect(events).to.match(/\tBefore \*time\.Time\n/)
+    expect(events).to.match(/\tIfModifiedSince \*HTTPTime\n/)
+  })
+})
@@ -0,0 +1,13 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: defaults', 'defaults', client => {
+  it('falls back to the defaults of unset parameters', () => {
+    const items = client.read('items.go')
+    expect(items).to.match(/if order == "" \{\n\s+order = "asc"\n/)
+    expect(items).to.match(/\} else \{\n\s+v\.Set\("limit", "20"\)\n/)
+  })
+})
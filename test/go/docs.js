@@ -0,0 +1,30 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: docs', 'docs', client => {
+  it('documents methods with their descriptions', () => {
+    const books = client.read('books.go')
+    expect(books).to.contain([
+      '// ListBooks returns all the books in the catalogue, sorted by title.',
+      '//',
+      '//   - Paged by offset',
+      '//   - Filtered by author',
+      'func (c *Client) ListBooks('
+    ].join('\n'))
+    expect(books).to.contain('// CreateBooks adds a book.\nfunc (c *Client) CreateBooks(')
+    expect(books).to.contain('\t// Author sets the "author" query parameter. Only books by this author.\n')
+  })
+
+  it('documents types and fields, wrapping long descriptions', () => {
+    const models = client.read('models.go')
+    expect(models).to.contain([
+      '// Book is a book in the catalogue, which lists every book the library holds,',
+      '// whether it\'s on the shelves or lent out.',
+      'type Book struct {'
+    ].join('\n'))
+    expect(models).to.contain('\t// Title is the title, as printed on the cover (https://example.com/covers).\n')
+  })
+})
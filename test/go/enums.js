@@ -0,0 +1,27 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: enums', 'enums', client => {
+  it('writes a type with a constant for each value', () => {
+    const tasks = client.read('tasks.go')
+    expect(tasks).to.contain('type Status string')
+    expect(tasks).to.match(/\tStatusOnHold +Status = "on-hold"\n/)
+    expect(tasks).to.contain('func (v Status) IsValid() bool {')
+  })
+
+  it('numbers constants whose names would collide', () => {
+    const tasks = client.read('tasks.go')
+    expect(tasks).to.match(/\tSortCreated +Sort = "created"\n/)
+    expect(tasks).to.match(/\tSortCreated2 +Sort = "-created"\n/)
+    expect(tasks).to.contain('case SortCreated, SortCreated2, SortName, SortName2:')
+  })
+
+  it('names numeric constants after their values', () => {
+    const models = client.read('models.go')
+    expect(models).to.contain('type Priority int64')
+    expect(models).to.match(/\tPriorityMinus1 Priority = -1\n/)
+  })
+})
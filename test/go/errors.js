@@ -0,0 +1,13 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: errors per status', 'errors', client => {
+  it('decodes the bodies declared for unsuccessful statuses', () => {
+    const users = client.read('users.go')
+    expect(users).to.match(/case 404:\n\t+return res, result, newAPIError\(res, new\(NotFound\)\)/)
+    expect(users).to.contain('return res, result, newAPIError(res, nil)')
+  })
+})
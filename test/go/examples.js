@@ -0,0 +1,24 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: examples', 'examples', client => {
+  it('writes a test of each call with examples', () => {
+    const tests = client.read('books_test.go')
+    expect(tests).to.contain('func TestListBooksExamples(t *testing.T) {')
+    expect(tests).to.match(/\{"empty", `\[\s*\]`\},\n/)
+    expect(tests).to.match(/\{"shelf", `\[.*"Persuasion".*\]`\},\n/)
+    expect(tests).to.contain('func TestCreateBooksExamples(t *testing.T) {')
+    expect(tests).to.match(/const requestExample = `\{.*"Emma".*\}`\n/)
+  })
+
+  it('runs the tests against servers responding with the examples', () => {
+    const output = helpers.goTest(client.dir, ['-v', '-run', 'Examples'])
+    expect(output).to.contain('--- PASS: TestListBooksExamples/empty')
+    expect(output).to.contain('--- PASS: TestListBooksExamples/shelf')
+    expect(output).to.contain('--- PASS: TestCreateBooksExamples/example')
+    expect(output).not.to.contain('SKIP')
+  })
+})
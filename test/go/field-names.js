@@ -0,0 +1,15 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: field-names', 'field-names', client => {
+  it('names fields idiomatically, keeping the wire names in their tags', () => {
+    const models = client.read('models.go')
+    expect(models).to.match(/\tUserID +string +`json:"user-id"`/)
+    expect(models).to.match(/\tCreatedAt +time\.Time +`json:"created_at"`/)
+    expect(models).to.match(/\tAPIURL +string +`json:"api_url"`/)
+    expect(models).to.match(/\tN3dSecure +bool +`json:"3d_secure"`/)
+  })
+})
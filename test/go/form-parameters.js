@@ -0,0 +1,16 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: form-parameters', 'form-parameters', client => {
+  it('takes RAML 0.8 formParameters as payload fields', () => {
+    const subscriptions = client.read('subscriptions.go')
+    expect(subscriptions).to.contain('func (c *Client) CreateSubscriptions(ctx context.Context, payload CreateSubscriptionsPayload, opts ...CallOption) (*http.Response, error) {')
+    expect(subscriptions).to.match(/\tEmail +string\n/)
+    expect(subscriptions).to.match(/\tTag +\[\]string\n/)
+    expect(subscriptions).not.to.contain('v.Set(')
+    expect(client.read('uploads.go')).to.match(/\tFile +\*FilePart\n/)
+  })
+})
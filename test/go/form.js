@@ -0,0 +1,20 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: url-encoded bodies', 'form', client => {
+  it('takes the fields in a payload', () => {
+    const oauth = client.read('oauth.go')
+    expect(oauth).to.match(/func \(c \*Client\) ExchangeCode\(ctx context\.Context, payload ExchangeCodePayload, opts \.\.\.CallOption\)/)
+    expect(oauth).to.match(/\tRedirectURI +string\n/)
+    expect(oauth).to.match(/\tScope +\*string\n/)
+  })
+
+  it('encodes them as a form', () => {
+    const oauth = client.read('oauth.go')
+    expect(oauth).to.contain('form.Add("grant_type", formatParam(payload.GrantType))')
+    expect(oauth).to.contain('strings.NewReader(form.Encode())')
+  })
+})
@@ -0,0 +1,46 @@
+/* eslint-env mocha */
+'use strict'
+
+const childProcess = require('child_process')
+const expect = require('chai').expect
+const fs = require('fs')
+const os = require('os')
+const path = require('path')
+const helpers = require('../helpers')
+const Module = require('../../lib/targets/go/lang').Module
+
+describe('go: format', function () {
+  this.timeout(120000)
+
+  helpers.describeClient('generated files', 'client', client => {
+    it('are left as gofmt would format them', () => {
+      client.files().forEach(file => {
+        const formatted = childProcess.execFileSync('gofmt', [path.join(client.dir, file)], { encoding: 'utf8' })
+        expect(client.read(file), file).to.equal(formatted)
+      })
+    })
+
+    it('quote a lone import without parentheses', () => {
+      client.files()
+        .map(file => client.read(file))
+        .forEach(source => expect(source).not.to.match(/^import \(\n\t"[^"]+"\n\)$/m))
+    })
+  })
+
+  describe('source which doesn\'t parse', () => {
+    let dir
+
+    before(() => { dir = fs.mkdtempSync(path.join(os.tmpdir(), 'format-')) })
+    after(() => helpers.remove(dir))
+
+    it('is reported rather than written', () => {
+      const module = new Module(dir, 'client')
+      module.file('bad.go').write('func {\n')
+
+      return module.save().then(() => { throw new Error('saved bad.go') }, err => {
+        expect(err.message).to.contain(`Error formatting ${path.join(dir, 'bad.go')}`)
+        expect(fs.existsSync(path.join(dir, 'bad.go'))).to.equal(false)
+      })
+    })
+  })
+})
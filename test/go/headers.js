@@ -0,0 +1,25 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: header parameters', 'headers', client => {
+  it('takes required headers as arguments and optional ones in a struct', () => {
+    const docs = client.read('docs.go')
+    expect(docs).to.match(/func \(c \*Client\) DeleteDocs\(ctx context\.Context, docID string, ifMatch string, headers DeleteDocsHeaders, opts \.\.\.CallOption\)/)
+    expect(docs).to.match(/\tXRequestID \*string\n/)
+    expect(docs).to.match(/\tXPriority +\*XPriority\n/)
+  })
+
+  it('sends defaults for unset headers', () => {
+    const docs = client.read('docs.go')
+    expect(docs).to.contain('req.Header.Set("X-Priority", "low")')
+  })
+
+  it('renames arguments which would clash with the call\'s locals', () => {
+    const docs = client.read('docs.go')
+    expect(docs).to.contain('func (c *Client) CreateDocs(ctx context.Context, vValue string, opts ...CallOption) (*http.Response, error) {')
+    expect(docs).to.contain('req.Header.Set("V", formatParam(vValue))')
+  })
+})
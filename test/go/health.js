@@ -0,0 +1,18 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: health', 'health', client => {
+  it('generates Ping for the /health resource', () => {
+    const endpoints = client.read('endpoints.go')
+    expect(endpoints).to.contain('func (c *Client) Ping(ctx context.Context, opts ...CallOption) error')
+    expect(endpoints).to.contain('req, err := http.NewRequest("GET", "/health", nil)')
+    expect(endpoints).to.match(/\tPing\(ctx context\.Context, opts \.\.\.CallOption\) error\n/)
+  })
+
+  it('adds Ping to StubAPI', () => {
+    expect(client.read('stub.go')).to.contain('PingFunc')
+  })
+})
@@ -0,0 +1,20 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: included schemas and examples', 'includes', client => {
+  it('generates structs from nested includes', () => {
+    const profile = client.read('profile.go')
+    expect(profile).to.match(/Address +Address +`json:"address"`/)
+    expect(profile).to.match(/Country +Country +`json:"country"`/)
+    expect(profile).to.contain('type Country struct {')
+  })
+
+  it('tests the included examples', () => {
+    const test = client.read('profile_test.go')
+    expect(test).to.contain('func TestGetProfileExamples(t *testing.T) {')
+    expect(test).to.contain('1 Main St')
+  })
+})
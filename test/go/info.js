@@ -0,0 +1,14 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: info', 'info', client => {
+  it('writes constants describing the API', () => {
+    const api = client.read('api.go')
+    expect(api).to.contain('const APIVersion = "v2"\n')
+    expect(api).to.contain('const DefaultMediaType = "application/vnd.store+json"\n')
+    expect(api).to.contain('const BaseURL = "https://api.example.com/v2"\n')
+  })
+})
@@ -0,0 +1,23 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: the API interface', 'interface', client => {
+  it('lists the calls, implemented by the Client', () => {
+    const endpoints = client.read('endpoints.go')
+    expect(endpoints).to.contain('type API interface {')
+    expect(endpoints).to.match(/\tDeleteUsers\(ctx context\.Context, userID string, opts \.\.\.CallOption\) \(\*http\.Response, error\)\n/)
+    expect(endpoints).to.match(/\tGetUser\(ctx context\.Context, userID string, opts \.\.\.CallOption\) /)
+    expect(endpoints).to.contain('var _ API = (*Client)(nil)')
+  })
+
+  it('writes a stub implementing it', () => {
+    const stub = client.read('stub.go')
+    expect(stub).to.contain('type StubAPI struct {')
+    expect(stub).to.contain('DeleteUsersFunc func(ctx context.Context, userID string, opts ...CallOption) (*http.Response, error)')
+    expect(stub).to.contain('return s.DeleteUsersFunc(ctx, userID, opts...)')
+    expect(stub).to.contain('var _ API = (*StubAPI)(nil)')
+  })
+})
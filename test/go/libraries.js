@@ -0,0 +1,22 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: libraries', 'libraries', client => {
+  it('prefixes the types of libraries with their names', () => {
+    const models = client.read('models.go')
+    expect(models).to.contain('type BillingAddress struct {')
+    expect(models).to.contain('type ShippingAddress struct {')
+    expect(models).to.contain('type ShippingCountry struct {')
+    expect(models).not.to.match(/type Address\b/)
+  })
+
+  it('resolves references within and across libraries', () => {
+    const models = client.read('models.go')
+    expect(models).to.match(/Billing +BillingAddress +`json:"billing"`/)
+    expect(models).to.match(/Shipping +ShippingAddress +`json:"shipping"`/)
+    expect(models).to.match(/Country +ShippingCountry +`json:"country"`/)
+  })
+})
@@ -0,0 +1,15 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: password login', 'login', client => {
+  it('posts the fields named by the scheme settings', () => {
+    const api = client.read('api.go')
+    expect(api).to.contain('func (c *Client) LoginSession(ctx context.Context, username string, password string) error')
+    expect(api).to.contain('c.loginWithPassword(ctx, "/login", true, map[string]string{')
+    expect(api).to.match(/"email":\s+username,/)
+    expect(api).to.match(/"secret":\s+password,/)
+  })
+})
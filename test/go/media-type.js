@@ -0,0 +1,14 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: media-type', 'media-type', client => {
+  it('sends bodies without a media type as the default one', () => {
+    const notes = client.read('notes.go')
+    expect(notes).to.contain('req.Header.Set("Content-Type", "application/json")')
+    expect(notes).to.contain('req.Header.Set("Accept", "application/json")')
+    expect(notes).to.contain('payload Note')
+  })
+})
@@ -0,0 +1,21 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: multipart bodies', 'multipart', client => {
+  it('takes fields and files in a payload', () => {
+    const photos = client.read('photos.go')
+    expect(photos).to.match(/func \(c \*Client\) CreatePhotos\(ctx context\.Context, payload CreatePhotosPayload, opts \.\.\.CallOption\)/)
+    expect(photos).to.match(/\tTitle +string\n/)
+    expect(photos).to.match(/\tCaption +\*string\n/)
+    expect(photos).to.match(/\tPhoto +\*FilePart\n/)
+  })
+
+  it('streams the body', () => {
+    const photos = client.read('photos.go')
+    expect(photos).to.contain('body, contentType := multipartBody(parts)')
+    expect(photos).to.contain('req.Header.Set("Content-Type", contentType)')
+  })
+})
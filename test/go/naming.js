@@ -0,0 +1,31 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: naming', 'naming', client => {
+  it('tells colliding methods apart by their paths, then by number', () => {
+    const names = client.files()
+      .filter(file => !/_test\.go$/.test(file))
+      .map(file => client.read(file).match(/^func \(c \*Client\) \w+/gm) || [])
+      .reduce((all, some) => all.concat(some), [])
+      .map(decl => decl.split(' ').pop())
+
+    expect(names).to.include.members(['ListOrgsByOrgIDRepos', 'ListOrgsRepos', 'GetUsergroups', 'GetUsergroups2', 'SetUserAgent2'])
+    expect(names.filter(name => name === 'SetUserAgent')).to.have.lengthOf(1)
+  })
+
+  it('names the methods the same way each time', () => {
+    let again
+    return helpers.generate('naming')
+      .then(out => {
+        again = out
+        expect(helpers.files(again)).to.deep.equal(client.files())
+        client.files().forEach(file => {
+          expect(helpers.read(again, file), file).to.equal(client.read(file))
+        })
+      })
+      .finally(() => helpers.remove(again))
+  })
+})
@@ -0,0 +1,15 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: negotiation', 'negotiation', client => {
+  it('returns a field for the type of each media type', () => {
+    const reports = client.read('reports.go')
+    expect(reports).to.contain('type GetReportResult struct {')
+    expect(reports).to.match(/\tJSON \*Report\n/)
+    expect(reports).to.match(/\tXML +\*Summary\n/)
+    expect(reports).to.contain('(*http.Response, GetReportResult, error)')
+  })
+})
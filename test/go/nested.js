@@ -0,0 +1,21 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: nested', 'nested', client => {
+  it('takes the URI parameters in the order of the path', () => {
+    expect(client.read('orgs.go')).to.contain('func (c *Client) GetOrgsReposIssue(ctx context.Context, orgID string, repo string, number int64, opts ...CallOption) (*http.Response, Issue, error) {')
+  })
+
+  it('escapes each of them', () => {
+    expect(client.read('orgs.go')).to.contain('fmt.Sprintf("/orgs/%s/repos/%s/issues/%s", pathParam(orgID), pathParam(repo), pathParam(number))')
+  })
+
+  it('renames those which would clash with the call\'s locals', () => {
+    const search = client.read('search.go')
+    expect(search).to.contain('func (c *Client) DeleteSearch(ctx context.Context, qValue string, opts ...CallOption) (*http.Response, error) {')
+    expect(search).to.contain('fmt.Sprintf("/search/%s", pathParam(qValue))')
+  })
+})
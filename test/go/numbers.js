@@ -0,0 +1,14 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: numbers', 'numbers', client => {
+  it('gives integers and numbers their Go types', () => {
+    const models = client.read('models.go')
+    expect(models).to.match(/\tID +int64 +`json:"id"`/)
+    expect(models).to.match(/\tScore +float64 +`json:"score"`/)
+    expect(models).to.match(/\tAttributes +interface\{\} +`json:"attributes,omitempty"`/)
+  })
+})
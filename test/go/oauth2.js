@@ -0,0 +1,21 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: OAuth 2.0', 'oauth2', client => {
+  it('defaults to Bearer tokens for APIs declaring an OAuth 2.0 scheme', () => {
+    expect(client.read('api.go')).to.contain('const defaultOAuthScheme = "Bearer"')
+  })
+
+  it('keeps the legacy OAuth scheme for other APIs', () => {
+    let dir
+    return helpers.generate('client')
+      .then(out => {
+        dir = out
+        expect(helpers.read(dir, 'api.go')).to.contain('const defaultOAuthScheme = "OAuth"')
+      })
+      .finally(() => helpers.remove(dir))
+  })
+})
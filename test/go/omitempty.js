@@ -0,0 +1,16 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: omitempty', 'omitempty', client => {
+  it('tags only optional fields with omitempty', () => {
+    const models = client.read('models.go')
+    expect(models).to.match(/\tName +string +`json:"name"`\n/)
+    expect(models).to.match(/\tAge +int64 +`json:"age"`\n/)
+    expect(models).to.match(/\tNickname +\*string +`json:"nickname,omitempty"`\n/)
+    expect(models).to.match(/\tAddress +\*Address +`json:"address,omitempty"`\n/)
+    expect(models).to.match(/\tTags +\[\]string +`json:"tags,omitempty"`\n/)
+  })
+})
@@ -0,0 +1,58 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const fs = require('fs')
+const path = require('path')
+const helpers = require('../helpers')
+
+// contents returns the generated files of dir, by name.
+const contents = dir => helpers.files(dir).reduce((files, name) => {
+  files[name] = helpers.read(dir, name)
+  return files
+}, {})
+
+helpers.describeClient('go: output', 'sub-clients', client => {
+  it('puts calls in the file of their top-level resource, and types in models.go', () => {
+    const users = client.read('users.go')
+    expect(users).to.contain('func (c *Client) GetUser(')
+    expect(users).to.contain('func (c *Client) ListUsersOrders(')
+    expect(users).not.to.contain('func (c *Client) ListOrders(')
+    expect(client.read('orders.go')).to.contain('func (c *Client) ListOrders(')
+
+    const models = client.read('models.go')
+    expect(models).to.contain('type User struct {')
+    expect(models).to.contain('type Order struct {')
+    client.files().filter(name => name !== 'models.go').forEach(name => {
+      expect(client.read(name), name).not.to.match(/^type (User|Order) struct/m)
+    })
+  })
+
+  it('sorts calls and types by name', () => {
+    const calls = client.read('users.go').match(/^func \(c \*Client\) \w+/gm)
+    expect(calls.length).to.be.above(1)
+    expect(calls).to.deep.equal(calls.slice().sort())
+
+    const models = client.read('models.go')
+    expect(models.indexOf('type Order struct {')).to.be.below(models.indexOf('type User struct {'))
+  })
+
+  it('writes the same files when generating again', () => {
+    const first = contents(client.dir)
+    return helpers.generate('sub-clients', {}, client.dir).then(() => {
+      expect(contents(client.dir)).to.deep.equal(first)
+    })
+  })
+
+  it('removes files it generated before, and leaves others alone', () => {
+    const stale = path.join(client.dir, 'carts.go')
+    const own = path.join(client.dir, 'extra.go')
+    fs.writeFileSync(stale, client.read('orders.go'))
+    fs.writeFileSync(own, 'package client\n')
+    return helpers.generate('sub-clients', {}, client.dir).then(() => {
+      expect(fs.existsSync(stale)).to.equal(false)
+      expect(fs.existsSync(own)).to.equal(true)
+      fs.unlinkSync(own)
+    })
+  })
+})
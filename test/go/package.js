@@ -0,0 +1,40 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const fs = require('fs')
+const os = require('os')
+const path = require('path')
+const helpers = require('../helpers')
+
+describe('go: package', function () {
+  this.timeout(120000)
+
+  function expectPackage (client, name) {
+    client.files().forEach(file => {
+      expect(client.read(file).match(/^package (\w+)$/m)[1], file).to.equal(name)
+    })
+  }
+
+  helpers.describeClient('from the annotation', 'package', client => {
+    it('names the package of every file', () => expectPackage(client, 'store'))
+  })
+
+  helpers.describeClient('from the flag', 'package', { packageName: 'orders' }, client => {
+    it('takes precedence over the annotation', () => expectPackage(client, 'orders'))
+  })
+
+  describe('named by the flag invalidly', () => {
+    let dir
+
+    before(() => { dir = fs.mkdtempSync(path.join(os.tmpdir(), 'package-')) })
+    after(() => helpers.remove(dir))
+
+    it('rejects names which aren\'t Go identifiers', () => {
+      return Promise.all(['my-client', 'func', '1client'].map(name =>
+        helpers.generate('package', { packageName: name }, dir)
+          .then(() => { throw new Error(`generated package ${name}`) },
+            err => expect(err.message).to.equal(`Invalid Go package name "${name}"`))))
+    })
+  })
+})
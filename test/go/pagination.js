@@ -0,0 +1,19 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: pagination', 'pagination', client => {
+  it('pages by offset', () => {
+    const items = client.read('items.go')
+    expect(items).to.contain('type ListItemsIterator struct {')
+    expect(items).to.contain('func (c *Client) ListItemsPages(query ListItemsParams, pageSize int, opts ...CallOption) *ListItemsIterator {')
+  })
+
+  it('pages by cursor', () => {
+    const events = client.read('events.go')
+    expect(events).to.contain('func (c *Client) GetEventsPages(query GetEventsParams, pageSize int, opts ...CallOption) *GetEventsIterator {')
+    expect(events).to.contain('Iteration stops after the first page without a next cursor.')
+  })
+})
@@ -0,0 +1,20 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: patch', 'patch', client => {
+  it('makes every field of PATCH bodies an omitted pointer', () => {
+    const users = client.read('users.go')
+    expect(users).to.match(/\tName +\*string +`json:"name,omitempty"`/)
+    expect(users).to.match(/\tAge +\*int64 +`json:"age,omitempty"`/)
+    expect(users).to.match(/\tAddress +\*UpdateUserPayloadAddress +`json:"address,omitempty"`/)
+    expect(users).to.match(/\tNullFields +\[\]string +`json:"-"`/)
+  })
+
+  it('marshals patches with the null fields', () => {
+    expect(client.read('users.go')).to.contain('body, err := payload.marshalPatch(c.jsonCodec())')
+    expect(client.read('settings.go')).to.contain('func (p MergeSettingsPayload) MarshalJSON() ([]byte, error)')
+  })
+})
@@ -0,0 +1,16 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: protocols', 'protocols', client => {
+  it('restricts the API to its protocols', () => {
+    expect(client.read('api.go')).to.contain('var allowedProtocols = []string{"https"}')
+  })
+
+  it('lets methods declare protocols of their own', () => {
+    expect(client.read('status.go')).to.contain('ctx = withProtocols(ctx, "http", "https")')
+    expect(client.read('orders.go')).not.to.contain('withProtocols')
+  })
+})
@@ -0,0 +1,14 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: query-string', 'query-string', client => {
+  it('takes the query as a value of its type', () => {
+    const books = client.read('books.go')
+    expect(books).to.contain('func (c *Client) ListBooks(ctx context.Context, query Search, opts ...CallOption)')
+    expect(books).to.contain('v.Set("filter[author]", formatParam(query.Filter.Author))')
+    expect(books).to.contain('c.addArrayParam(v, "tags", formatParams(query.Tags))')
+  })
+})
@@ -0,0 +1,21 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: query parameters', 'query', client => {
+  it('takes required parameters as arguments and optional ones in a struct', () => {
+    const search = client.read('search.go')
+    expect(search).to.match(/func \(c \*Client\) ListSearch\(ctx context\.Context, term string, query ListSearchParams, opts \.\.\.CallOption\)/)
+    expect(search).to.match(/\tPage +\*int64\n/)
+    expect(search).to.match(/\tSort +\*string\n/)
+    expect(search).to.match(/\tExact +\*bool\n/)
+  })
+
+  it('renames arguments which would clash with the call\'s locals', () => {
+    const suggestions = client.read('suggestions.go')
+    expect(suggestions).to.contain('func (c *Client) ListSuggestions(ctx context.Context, qValue string, opts ...CallOption) (*http.Response, []string, error) {')
+    expect(suggestions).to.contain('v.Set("q", formatParam(qValue))')
+  })
+})
@@ -0,0 +1,12 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: raw responses', 'raw-response', client => {
+  it('returns the response ahead of the decoded body', () => {
+    expect(client.read('users.go')).to.match(
+      /func \(c \*Client\) GetUser\(ctx context\.Context, userID string, opts \.\.\.CallOption\) \(\*http\.Response, \*?User, error\)/)
+  })
+})
@@ -0,0 +1,14 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: required', 'required', client => {
+  it('checks required parameters before making the request', () => {
+    const users = client.read('users.go')
+    expect(users).to.contain('func (c *Client) DeleteUsersPosts(ctx context.Context, userID string, postID string, reason string, xToken string, opts ...CallOption)')
+    expect(users).to.match(/if userID == "" \{\n\s+return nil, missingParam\("userId"\)\n/)
+    expect(users.indexOf('missingParam("X-Token")')).to.be.below(users.indexOf('http.NewRequest('))
+  })
+})
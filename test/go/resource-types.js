@@ -0,0 +1,25 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: resource types', 'resource-types', client => {
+  it('adds the collection\'s methods to the collection', () => {
+    const books = client.read('books.go')
+    expect(books).to.match(/func \(c \*Client\) ListBooks\(ctx context\.Context, opts \.\.\.CallOption\) \(\*http\.Response, \[\]Book, error\)/)
+    expect(books).to.match(/func \(c \*Client\) CreateBooks\(ctx context\.Context, payload Book, opts \.\.\.CallOption\)/)
+  })
+
+  it('adds the member\'s methods to the member', () => {
+    const books = client.read('books.go')
+    expect(books).to.match(/func \(c \*Client\) GetBook\(ctx context\.Context, bookID string, opts \.\.\.CallOption\)/)
+    expect(books).to.match(/func \(c \*Client\) DeleteBooks\(ctx context\.Context, bookID string, opts \.\.\.CallOption\) \(\*http\.Response, error\)/)
+  })
+
+  it('prefers the resource\'s declarations', () => {
+    const books = client.read('books.go')
+    expect(books).to.contain('// GetBook fetches one book, by its ID.')
+    expect(books).not.to.contain('Returns the book.')
+  })
+})
@@ -0,0 +1,15 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: response-headers', 'response-headers', client => {
+  it('returns the declared headers in a struct after the result', () => {
+    const items = client.read('items.go')
+    expect(items).to.contain('func (c *Client) ListItems(ctx context.Context, opts ...CallOption) (*http.Response, []string, ListItemsResponseHeaders, error) {')
+    expect(items).to.match(/\tXTotalCount int64\n/)
+    expect(items).to.match(/\tXNextPage +\*string\n/)
+    expect(items).to.contain('if err := parseHeader(res, "X-Total-Count", &resHeaders.XTotalCount); err != nil {')
+  })
+})
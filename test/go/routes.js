@@ -0,0 +1,11 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: route templates', 'routes', client => {
+  it('stamps the route template on the context of calls', () => {
+    expect(client.read('orgs.go')).to.contain('ctx = withRoute(ctx, "/orgs/{orgId}/repos/{repoId}")')
+  })
+})
@@ -0,0 +1,27 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: JSON schema bodies', 'schemas', client => {
+  it('names the call after the resource, not the schema', () => {
+    const users = client.read('users.go')
+    expect(users).to.match(/func \(c \*Client\) GetUsers\(ctx context\.Context, userID string, opts \.\.\.CallOption\) \(\*http\.Response, User, error\)/)
+  })
+
+  it('writes structs for nested objects', () => {
+    const users = client.read('users.go')
+    expect(users).to.match(/ID +int64 +`json:"id"`/)
+    expect(users).to.match(/Nickname +\*string +`json:"nickname,omitempty"`/)
+    expect(users).to.match(/Address +UserAddress +`json:"address"`/)
+    expect(users).to.match(/Geo +\*UserAddressGeo +`json:"geo,omitempty"`/)
+    expect(users).to.match(/Phones +\[\]Phone +`json:"phones,omitempty"`/)
+  })
+
+  it('validates responses against the schema', () => {
+    const users = client.read('users.go')
+    expect(users).to.contain('var getUsersResultSchema = newSchema(')
+    expect(users).to.contain('if err := c.validateResponse(res, getUsersResultSchema); err != nil {')
+  })
+})
@@ -0,0 +1,12 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: securedBy', 'secured-by', client => {
+  it('marks unsecured calls', () => {
+    expect(client.read('status.go')).to.contain('ctx = withoutAuth(ctx)')
+    expect(client.read('me.go')).not.to.contain('withoutAuth')
+  })
+})
@@ -0,0 +1,26 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: security schemes', 'security', client => {
+  it('writes a setup helper for each scheme', () => {
+    const api = client.read('api.go')
+    expect(api).to.contain('func (c *Client) UseOAuth20Auth(token string) {\n\tc.UseBearerToken(token)\n}')
+    expect(api).to.contain('c.UseOAuth1(consumerKey, consumerSecret, token, tokenSecret)')
+    expect(api).to.contain('func (c *Client) UsePartnerAuth(username string, password string) {\n\tc.UseBasicAuth(username, password)\n}')
+  })
+
+  it('writes constants for the settings\' URIs', () => {
+    const api = client.read('api.go')
+    expect(api).to.contain('const OAuth20AccessTokenURI = "https://example.com/oauth/token"')
+    expect(api).to.contain('const OAuth10TokenCredentialsURI = "https://example.com/oauth/access_token"')
+  })
+
+  it('leaves out helpers taken by the client', () => {
+    const api = client.read('api.go')
+    expect(api).not.to.contain('func (c *Client) UseBasicAuth(')
+    expect(api).not.to.contain('func (c *Client) UseDigestAuth(')
+  })
+})
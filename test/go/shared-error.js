@@ -0,0 +1,20 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: shared error type', 'shared-error', client => {
+  it('writes one Error type for equal error schemas', () => {
+    const code = client.files().map(name => client.read(name)).join('\n')
+    expect(code.match(/^type Error struct/gm)).to.have.length(1)
+    expect(code).not.to.contain('Error400')
+    expect(code).not.to.contain('Error409')
+    expect(client.read('endpoints.go')).to.contain('func (e *Error) Error() string {')
+  })
+
+  it('decodes both errors into it', () => {
+    expect(client.read('widgets.go')).to.contain('newAPIError(res, new(Error))')
+    expect(client.read('gadgets.go')).to.contain('newAPIError(res, new(Error))')
+  })
+})
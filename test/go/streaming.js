@@ -0,0 +1,11 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: streaming', 'streaming', client => {
+  it('returns before decoding streamed responses', () => {
+    expect(client.read('logs.go')).to.match(/if streamed\(opts\) \{\n\s+return res, result, nil\n/)
+  })
+})
@@ -0,0 +1,21 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: sub-clients', 'sub-clients', client => {
+  it('writes a sub-client for each resource, nested like the resources', () => {
+    const users = client.read('users.go')
+    expect(users).to.contain('func (c *Client) Users() *UsersClient {')
+    expect(users).to.contain('func (s *UsersClient) Orders() *UsersOrdersClient {')
+    expect(users).to.contain('// The people using the store.')
+    expect(client.read('orders.go')).to.contain('func (c *Client) Orders() *OrdersClient {')
+  })
+
+  it('names the calls of sub-clients with their verbs', () => {
+    const users = client.read('users.go')
+    expect(users).to.contain('func (s *UsersClient) Get(ctx context.Context, userID string, opts ...CallOption) (*http.Response, User, error) {')
+    expect(users).to.contain('return s.c.GetUser(ctx, userID, opts...)')
+  })
+})
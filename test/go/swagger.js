@@ -0,0 +1,18 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: swagger', 'swagger', client => {
+  it('generates a method for each operation', () => {
+    const pets = client.read('pets.go')
+    expect(pets).to.contain('func (c *Client) ListPets(ctx context.Context, query ListPetsParams, opts ...CallOption) (*http.Response, []Pet, error)')
+    expect(pets).to.contain('func (c *Client) CreatePet(ctx context.Context, payload Pet, opts ...CallOption) (*http.Response, Pet, error)')
+    expect(pets).to.contain('func (c *Client) ShowPetByID(ctx context.Context, petID string, opts ...CallOption) (*http.Response, Pet, error)')
+  })
+
+  it('declares the definitions', () => {
+    expect(client.read('models.go')).to.contain('type Pet struct {')
+  })
+})
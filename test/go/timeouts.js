@@ -0,0 +1,17 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: timeouts', 'timeouts', client => {
+  it('applies (go.timeout) ahead of the caller\'s options', () => {
+    expect(client.read('search.go')).to.contain('opts = append([]CallOption{WithTimeout(2 * time.Second)}, opts...)')
+    expect(client.read('reports.go')).to.contain('opts = append([]CallOption{WithTimeout(90 * time.Second)}, opts...)')
+    expect(client.read('status.go')).not.to.contain('WithTimeout')
+  })
+
+  it('documents the timeout', () => {
+    expect(client.read('search.go')).to.contain('// Search times out after 2s, unless the call is made\n// WithTimeout.')
+  })
+})
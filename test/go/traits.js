@@ -0,0 +1,26 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: traits', 'traits', client => {
+  it('adds the parameters of the traits a method has', () => {
+    const repos = client.read('repos.go')
+    expect(repos).to.match(/func \(c \*Client\) ListRepos\(ctx context\.Context, query ListReposParams, opts \.\.\.CallOption\)/)
+    expect(repos).to.match(/\tOffset \*int64\n/)
+    expect(repos).to.match(/\tLimit +\*int64\n/)
+    expect(repos).to.match(/\tQ +\*string\n/)
+  })
+
+  it('substitutes the traits\' parameters', () => {
+    const repos = client.read('repos.go')
+    expect(repos).to.contain('// Offset sets the first of the repos to return.')
+  })
+
+  it('prefers the method\'s declarations', () => {
+    const repos = client.read('repos.go')
+    expect(repos).to.contain('"must be at most 100"')
+    expect(repos).not.to.contain('"must be at most 1000"')
+  })
+})
@@ -0,0 +1,27 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: RAML 1.0 types', 'types', client => {
+  it('embeds the types objects inherit from', () => {
+    const models = client.read('models.go')
+    expect(models).to.match(/type Dog struct \{\n\tAnimal\n/)
+    expect(models).to.match(/type Cat struct \{\n\tAnimal\n/)
+  })
+
+  it('writes slices for arrays', () => {
+    const models = client.read('models.go')
+    expect(models).to.match(/Tags \[\]string +`json:"tags"`/)
+    expect(models).to.match(/Dogs +\[\]Dog +`json:"dogs"`/)
+    expect(models).to.match(/Capacity \*int64 +`json:"capacity,omitempty"`/)
+  })
+
+  it('wraps unions', () => {
+    const models = client.read('models.go')
+    expect(models).to.match(/type Pet struct \{\n\tDog \*Dog\n\tCat \*Cat\n\}/)
+    expect(models).to.contain('func (u Pet) MarshalJSON() ([]byte, error) {')
+    expect(models).to.contain('func (u *Pet) UnmarshalJSON(data []byte) error {')
+  })
+})
@@ -0,0 +1,11 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: user agent', 'user-agent', client => {
+  it('names the API and its version in DefaultUserAgent', () => {
+    expect(client.read('api.go')).to.contain('const DefaultUserAgent = "Example-API/v2"')
+  })
+})
@@ -0,0 +1,27 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const helpers = require('../helpers')
+
+helpers.describeClient('go: XML bodies', 'xml', client => {
+  it('tags structs for XML', () => {
+    const models = client.read('models.go')
+    expect(models).to.match(/ID +int64 +`json:"id" xml:"id,attr"`/)
+    expect(models).to.match(/Body +\*string +`json:"body,omitempty" xml:"body,omitempty"`/)
+  })
+
+  it('encodes requests with an XML declaration', () => {
+    const notes = client.read('notes.go')
+    expect(notes).to.contain('body, err := encodeXML(payload, "Note")')
+    expect(notes).to.contain('req.Header.Set("Accept", "application/xml")')
+  })
+
+  it('wraps arrays in a root element', () => {
+    const notes = client.read('notes.go')
+    expect(notes).to.contain('func (c *Client) CreateNotes(ctx context.Context, payload []Note, opts ...CallOption) (*http.Response, error) {')
+    expect(notes).to.contain('Items []Note `xml:"Note"`')
+    expect(notes).to.contain('}{payload}, "Notes")')
+    expect(notes).not.to.contain('CreateNotesPayload')
+  })
+})
@@ -0,0 +1,146 @@
+/* eslint-env mocha */
+'use strict'
+
+const childProcess = require('child_process')
+const fs = require('fs')
+const os = require('os')
+const path = require('path')
+
+const IncludeResolver = require('../lib/include').IncludeResolver
+const Todo = require('../lib/todo').Todo
+const model = require('../lib/model')
+const target = require('../lib/targets/go').default
+
+const fixtures = path.join(__dirname, 'fixtures')
+
+// The generator reports its progress on the terminal, which would garble
+// mocha's output, and needs a TTY to do it.
+Todo.prototype.writeMessage = () => {}
+
+/**
+ * Returns the path of the definition in the fixture's directory, which is
+ * api.raml, or api.json or api.yaml for Swagger fixtures.
+ */
+function definition (fixture) {
+  const file = ['api.raml', 'api.json', 'api.yaml']
+    .map(name => path.join(fixtures, fixture, name))
+    .find(file => fs.existsSync(file))
+  if (!file) {
+    throw new Error(`Fixture ${fixture} has no definition`)
+  }
+
+  return file
+}
+
+/**
+ * Generates a Go client from the fixture in test/fixtures into a new
+ * temporary directory, or into dir if it's given, resolving to the
+ * directory. The fixture's Go tests are copied next to the generated code,
+ * along with a go.mod so that it builds on its own.
+ */
+exports.generate = (fixture, options, dir) => {
+  const file = definition(fixture)
+  dir = dir || fs.mkdtempSync(path.join(os.tmpdir(), `${fixture}-`))
+
+  return model.load(file)
+    .then(api => target.generate(api, dir, new IncludeResolver(path.resolve(file)), options || {}))
+    .then(() => {
+      // The fixture's tests join the package the client was generated in,
+      // which may have been named by the flag or an annotation.
+      const pkg = exports.read(dir, 'api.go').match(/^package (\w+)$/m)[1]
+      fs.readdirSync(path.join(fixtures, fixture))
+        .filter(name => /_test\.go$/.test(name))
+        .forEach(name => {
+          const source = fs.readFileSync(path.join(fixtures, fixture, name), 'utf8')
+          fs.writeFileSync(path.join(dir, name), source.replace(/^package \w+$/m, `package ${pkg}`))
+        })
+      fs.writeFileSync(path.join(dir, 'go.mod'), 'module example.com/client\n\ngo 1.21\n')
+
+      return dir
+    })
+}
+
+/**
+ * Returns the contents of a generated file.
+ */
+exports.read = (dir, name) => fs.readFileSync(path.join(dir, name), 'utf8')
+
+/**
+ * Returns the names of the generated Go files, sorted.
+ */
+exports.files = dir => fs.readdirSync(dir).filter(name => /\.go$/.test(name)).sort()
+
+/**
+ * Runs `go vet` and `go test` on the generated client, passing any extra
+ * arguments to `go test`, and returns the output of the tests. Throws an
+ * error holding their output if either fails.
+ */
+exports.goTest = (dir, args) => {
+  const commands = [['vet', '.'], ['test'].concat(args || [], '.')]
+  let output = ''
+  commands.forEach(command => {
+    const result = childProcess.spawnSync('go', command, {
+      cwd: dir,
+      encoding: 'utf8',
+      env: Object.assign({}, process.env, { GO111MODULE: 'on', GOFLAGS: '-mod=mod', GOTOOLCHAIN: 'local' })
+    })
+    if (result.error) {
+      throw result.error
+    }
+    if (result.status !== 0) {
+      throw new Error(`go ${command[0]} failed in ${dir}:\n${result.stdout}${result.stderr}`)
+    }
+    output = result.stdout
+  })
+
+  return output
+}
+
+/**
+ * Removes a directory the client was generated into.
+ */
+exports.remove = dir => {
+  if (!dir || !fs.existsSync(dir)) {
+    return
+  }
+
+  fs.readdirSync(dir).forEach(name => {
+    const file = path.join(dir, name)
+    if (fs.statSync(file).isDirectory()) {
+      exports.remove(file)
+    } else {
+      fs.unlinkSync(file)
+    }
+  })
+  fs.rmdirSync(dir)
+}
+
+/**
+ * Describes the client generated from the fixture, with the options if
+ * they're given. The client is generated before the tests fn declares and
+ * removed after them; fn is passed the client, which reads its files once
+ * the tests run. A last test builds the client and runs the fixture's Go
+ * tests against it.
+ */
+exports.describeClient = (title, fixture, options, fn) => {
+  if (typeof options === 'function') {
+    fn = options
+    options = undefined
+  }
+
+  describe(title, function () {
+    this.timeout(120000)
+    const client = {
+      dir: null,
+      read: name => exports.read(client.dir, name),
+      files: () => exports.files(client.dir)
+    }
+
+    before(() => exports.generate(fixture, options).then(dir => { client.dir = dir }))
+    after(() => exports.remove(client.dir))
+
+    fn(client)
+
+    it('builds and passes its tests', () => exports.goTest(client.dir))
+  })
+}
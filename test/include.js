@@ -0,0 +1,34 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const path = require('path')
+const include = require('../lib/include')
+
+const fixture = path.join(__dirname, 'fixtures', 'includes')
+
+describe('IncludeResolver', () => {
+  const resolver = new include.IncludeResolver(path.join(fixture, 'api.raml'))
+
+  it('loads JSON and plain text', () => {
+    expect(resolver.load('examples/profile.json').name).to.equal('Grace')
+    expect(resolver.load('api.raml')).to.match(/^#%RAML 1\.0/)
+  })
+
+  it('resolves references relative to the document making them', () => {
+    const schema = resolver.resolveJSON(resolver.load('profile.json'))
+    const address = schema.properties.address
+    expect(address.title).to.equal('Address')
+    expect(address.properties.country.title).to.equal('Country')
+  })
+
+  it('resolves fragments', () => {
+    const schema = resolver.resolveJSON({ $ref: 'schemas/address.json#/properties/street' })
+    expect(schema).to.deep.equal({ type: 'string' })
+  })
+
+  it('detects cycles', () => {
+    expect(() => resolver.resolveJSON(resolver.load('cycle/a.json'), path.join(fixture, 'cycle', 'a.json')))
+      .to.throw(include.IncludeCycleError, /a\.json -> .*b\.json -> .*a\.json/)
+  })
+})
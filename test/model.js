@@ -0,0 +1,68 @@
+/* eslint-env mocha */
+'use strict'
+
+const expect = require('chai').expect
+const path = require('path')
+const model = require('../lib/model')
+
+const fixtures = path.join(__dirname, 'fixtures')
+
+describe('parse', function () {
+  this.timeout(120000)
+
+  const parse = fixture => model.parse(path.join(fixtures, fixture))
+  const method = (api, verb, uri) => api.methods.find(m => m.method === verb && m.path === uri)
+
+  it('flattens the methods of resource types', () => parse('resource-types/api.raml').then(api => {
+    expect(api.methods.map(m => `${m.method} ${m.path}`).sort()).to.deep.equal([
+      'delete /books/{bookId}',
+      'get /books',
+      'get /books/{bookId}',
+      'post /books'
+    ])
+    expect(method(api, 'get', '/books').responses[0].body[0].type).to.deep.equal(['Book[]'])
+    expect(method(api, 'post', '/books').body[0]).to.include({ mediaType: 'application/json' })
+    expect(method(api, 'post', '/books').body[0].type).to.deep.equal(['Book'])
+
+    // Methods' own declarations take precedence over inherited ones.
+    expect(method(api, 'get', '/books/{bookId}').description).to.equal('Fetches one book, by its ID.')
+  }))
+
+  it('applies traits', () => parse('traits/api.raml').then(api => {
+    const repos = method(api, 'get', '/repos')
+    expect(repos.queryParameters.map(p => p.name).sort()).to.deep.equal(['limit', 'offset', 'q'])
+    const offset = repos.queryParameters.find(p => p.name === 'offset')
+    expect(offset).to.include({ required: false, description: 'Sets the first of the repos to return.' })
+    expect(offset.type).to.deep.equal(['integer'])
+  }))
+
+  it('nests resources, and lists their methods', () => parse('sub-clients/api.raml').then(api => {
+    const users = api.resources.find(r => r.path === '/users')
+    expect(users.methods.map(m => m.method).sort()).to.deep.equal(['get', 'post'])
+    expect(users.resources.map(r => r.path)).to.deep.equal(['/users/{userId}'])
+    expect(users.resources[0].uriParameters.map(p => p.name)).to.deep.equal(['userId'])
+  }))
+
+  it('names the types of libraries with their namespace', () => parse('libraries/api.raml').then(api => {
+    const names = api.types.map(t => t.name)
+    expect(names).to.include.members(['Order', 'billing.Address', 'shipping.Address'])
+    const order = api.types.find(t => t.name === 'Order')
+    expect(order.properties.map(p => p.name)).to.deep.equal(['id', 'billing', 'shipping'])
+  }))
+
+  it('describes the API and its security', () => parse('secured-by/api.raml').then(api => {
+    expect(api.title).to.be.a('string')
+    expect(api.securitySchemes.length).to.be.above(0)
+    api.methods.forEach(m => expect(m.securedBy.length, `${m.method} ${m.path}`).to.be.above(0))
+  }))
+
+  it('converts Swagger definitions', () => parse('swagger/api.json').then(api => {
+    expect(api).to.include({ title: 'Pet Store', version: '1.0', baseUri: 'https://api.example.com/v1' })
+    expect(api.methods.map(m => `${m.method} ${m.path}`)).to.deep.equal([
+      'get /pets',
+      'post /pets',
+      'get /pets/{petId}'
+    ])
+    expect(api.securitySchemes.map(s => s.name)).to.deep.equal(['api_key'])
+  }))
+})